@@ -0,0 +1,93 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+)
+
+var (
+	errorType           = reflect.TypeOf((*error)(nil)).Elem()
+	dumpableType        = reflect.TypeOf((*Dumpable)(nil)).Elem()
+	binaryMarshalerType = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+)
+
+// UnaddressableError is the error of dumps made WithStrict, reporting the
+// first value a feature could not be applied to because it is not
+// addressable, like values stored in maps or interfaces.
+type UnaddressableError struct {
+	Path    string
+	Feature string
+}
+
+func (e *UnaddressableError) Error() string {
+	return fmt.Sprintf("godump: %s: %s needs an addressable value", tracePath(e.Path), e.Feature)
+}
+
+// WithStrict makes dumps fail with an *UnaddressableError when a feature
+// cannot be applied to a value because it is not addressable. Such values
+// are annotated in any case, e.g.
+//
+//	(unaddressable: (*main.T).Error not applied)
+//
+// for a value whose error method has a pointer receiver. The dump is still
+// written in full; only the functions returning an error report it.
+func WithStrict(strict bool) Option {
+	return func(d *Dumper) {
+		d.strict = strict
+	}
+}
+
+// unaddressable annotates n for the feature that could not be applied to its
+// value and, in strict mode, fails the dump.
+func (v *variable) unaddressable(n *Node, feature string) {
+	n.annotate("(unaddressable: " + feature + " not applied)")
+	if v.d.strict && v.err == nil {
+		v.err = &UnaddressableError{n.Path, feature}
+	}
+}
+
+// receiver returns the value to call the method of the single-method
+// interface t on: val itself, or its address if only the pointer type
+// implements t. Values that are not addressable are annotated then. Values
+// reached by deref, through the pointer to them, are left alone since the
+// pointer was rendered by the method already.
+func (v *variable) receiver(val reflect.Value, t reflect.Type, n *Node, deref bool) reflect.Value {
+	if deref {
+		return val
+	}
+	r, ok := addrReceiver(val, t)
+	if !ok {
+		v.unaddressable(n, "(*"+typeName(val.Type())+")."+t.Method(0).Name)
+	}
+	return r
+}
+
+// addressable returns a copy of the top-level value val that can be
+// addressed, so the methods with pointer receivers of the values in it can
+// be called like those of values reached through pointers.
+func addressable(val reflect.Value) reflect.Value {
+	if !val.IsValid() || val.CanAddr() || !val.CanInterface() {
+		return val
+	}
+	c := reflect.New(val.Type()).Elem()
+	c.Set(val)
+	return c
+}
+
+// addrReceiver returns val, or its address if only the pointer type
+// implements t. It returns false if val is not addressable then.
+func addrReceiver(val reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	if !val.CanInterface() || val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface ||
+		val.Type().Implements(t) || !reflect.PointerTo(val.Type()).Implements(t) {
+		return val, true
+	}
+	if val.CanAddr() {
+		return val.Addr(), true
+	}
+	return val, false
+}
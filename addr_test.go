@@ -0,0 +1,45 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"io"
+	"testing"
+)
+
+type ptrError struct {
+	Code int
+}
+
+func (e *ptrError) Error() string { return "failed" }
+
+func TestUnaddressable(t *testing.T) {
+	v := &struct {
+		E ptrError
+		M map[string]ptrError
+	}{ptrError{1}, map[string]ptrError{"k": {2}}}
+	want := "(*struct { E godump.ptrError; M map[string]godump.ptrError })\n" +
+		"  (struct { E godump.ptrError; M map[string]godump.ptrError })\n" +
+		"    E(godump.ptrError) \"failed\"\n" +
+		"    M(map[string]godump.ptrError)\n" +
+		"      k(godump.ptrError) (unaddressable: (*godump.ptrError).Error not applied)\n" +
+		"        Code(int) 2\n"
+	if got := Sdump(v); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	// Top-level values passed by value are copied to be addressable.
+	if got, want := Sdump(ptrError{3}), "(godump.ptrError) \"failed\"\n"; got != want {
+		t.Errorf("top-level value: got %q, want %q", got, want)
+	}
+	if got, want := Sdump(struct{ E ptrError }{}), "(struct { E godump.ptrError })\n  E(godump.ptrError) \"failed\"\n"; got != want {
+		t.Errorf("field of top-level value: got %q, want %q", got, want)
+	}
+
+	err := NewDumper(WithStrict(true)).fdump(io.Discard, "", v)
+	if e, ok := err.(*UnaddressableError); !ok || e.Path != "M[k]" {
+		t.Errorf("strict: got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"bufio"
+	"reflect"
+	"sync"
+)
+
+// appendBuffer collects output into a byte slice, through a buffered
+// writer kept with it for reuse.
+type appendBuffer struct {
+	b  []byte
+	bw *bufio.Writer
+}
+
+func (a *appendBuffer) Write(p []byte) (int, error) {
+	a.b = append(a.b, p...)
+	return len(p), nil
+}
+
+var appendBuffers = sync.Pool{
+	New: func() interface{} {
+		a := new(appendBuffer)
+		a.bw = bufio.NewWriter(a)
+		return a
+	},
+}
+
+// AppendDump appends the dump of v to dst and returns the extended buffer,
+// like the Append functions of strconv. It is meant for callers managing
+// their own buffers: the output is written to dst directly, which is only
+// reallocated when it runs out of capacity, and the buffers used while
+// dumping are reused across calls, so a dump into a buffer that is large
+// enough allocates much less than Sdump. The values dumped and the nodes
+// describing them still allocate, in proportion to the size of v. Errors of
+// custom renderers cut the output short.
+func (d *Dumper) AppendDump(dst []byte, v interface{}) []byte {
+	a := appendBuffers.Get().(*appendBuffer)
+	a.b = dst
+	dump := d.bufferedVariable(a.bw, "")
+	dump.begin()
+	dump.root(reflect.ValueOf(v))
+	dump.end()
+	a.bw.Flush()
+	dst, a.b = a.b, nil
+	appendBuffers.Put(a)
+	return dst
+}
+
+// AppendDump is the package-level version of Dumper.AppendDump. Options
+// allocate a Dumper per call; performance-sensitive callers should create
+// one with NewDumper once instead.
+func AppendDump(dst []byte, v interface{}, opts ...Option) []byte {
+	return std().With(opts...).AppendDump(dst, v)
+}
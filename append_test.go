@@ -0,0 +1,43 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import "testing"
+
+func TestAppendDump(t *testing.T) {
+	v := map[string][]int{"a": {1, 2}}
+	dst := AppendDump([]byte("prefix\n"), v, WithHeader(true))
+	if want := "prefix\n" + Sdump(v, WithHeader(true)); string(dst) != want {
+		t.Errorf("got\n%s\nwant\n%s", dst, want)
+	}
+
+	d := NewDumper()
+	s := S{1, 2}
+	buf := make([]byte, 0, 1024)
+	appendAllocs := testing.AllocsPerRun(100, func() { buf = d.AppendDump(buf[:0], s) })
+	sdumpAllocs := testing.AllocsPerRun(100, func() { d.Sdump(s) })
+	if appendAllocs >= sdumpAllocs {
+		t.Errorf("AppendDump allocates %v times, Sdump %v times", appendAllocs, sdumpAllocs)
+	}
+}
+
+func BenchmarkAppendDump(b *testing.B) {
+	d := NewDumper()
+	v := []S{{1, 2}, {3, 4}, {5, 6}}
+	buf := make([]byte, 0, 4096)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = d.AppendDump(buf[:0], v)
+	}
+}
+
+func BenchmarkSdump(b *testing.B) {
+	d := NewDumper()
+	v := []S{{1, 2}, {3, 4}, {5, 6}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		d.Sdump(v)
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"io"
+	"reflect"
+	"strings"
+)
+
+// DumpWithBaseline prints the dump of v to standard out, marking the lines
+// that differ from the dump of baseline in a column in front of them:
+//
+//	  (main.Config)
+//	!   Port(int) 8081
+//	    Host(string) "localhost"
+//	+   Debug(bool) true
+//	-   Verbose(bool) false
+//
+// Changed values are marked with !, values missing from baseline with +
+// and values missing from v, which are shown as they are in baseline, with
+// -. Values are matched by their path, so elements of arrays and slices
+// are compared by index. Options are applied as by Dump. Marks are only
+// shown in line-based output, not e.g. in JSON.
+func DumpWithBaseline(v, baseline interface{}, opts ...Option) {
+	std().With(opts...).DumpWithBaseline(v, baseline)
+}
+
+// SdumpWithBaseline returns the dump of v marked as by DumpWithBaseline.
+func SdumpWithBaseline(v, baseline interface{}, opts ...Option) string {
+	return std().With(opts...).SdumpWithBaseline(v, baseline)
+}
+
+// DumpWithBaseline is the Dumper version of the package-level
+// DumpWithBaseline.
+func (d *Dumper) DumpWithBaseline(v, baseline interface{}) {
+	io.WriteString(d.output(), d.SdumpWithBaseline(v, baseline))
+}
+
+// SdumpWithBaseline is the Dumper version of the package-level
+// SdumpWithBaseline.
+func (d *Dumper) SdumpWithBaseline(v, baseline interface{}) string {
+	var b strings.Builder
+	dump := d.newVariable(&b, "")
+	if d.treeRender == nil {
+		dump.baseline = d.baselineOf(reflect.ValueOf(baseline))
+	}
+	dump.begin()
+	dump.root(reflect.ValueOf(v))
+	if dump.baseline != nil {
+		dump.baseline.removed(dump, dump.baseline.roots)
+	}
+	dump.end()
+	dump.w.Flush()
+	return b.String()
+}
+
+// baseline holds the nodes of the dump of a baseline while a value is
+// dumped against it.
+type baseline struct {
+	roots []*tree
+	nodes map[baselineKey]*tree
+
+	// Nodes of the baseline matched by the dumped value
+	seen map[baselineKey]bool
+}
+
+type baselineKey struct {
+	path, name string
+	depth      int
+}
+
+func keyOfNode(n *Node) baselineKey {
+	return baselineKey{n.Path, n.Name, n.Depth}
+}
+
+// baselineOf collects the nodes of the dump of val.
+func (d *Dumper) baselineOf(val reflect.Value) *baseline {
+	b := &baseline{
+		nodes: make(map[baselineKey]*tree),
+		seen:  make(map[baselineKey]bool),
+	}
+	c := d.With(func(c *Dumper) {
+		c.treeRender = func(_ *Dumper, _ io.Writer, root *tree) error {
+			b.roots = append(b.roots, root)
+			return nil
+		}
+	})
+	c.newVariable(io.Discard, "").root(val)
+
+	var index func(ts []*tree)
+	index = func(ts []*tree) {
+		for _, t := range ts {
+			if _, ok := b.nodes[keyOfNode(&t.Node)]; !ok {
+				b.nodes[keyOfNode(&t.Node)] = t
+			}
+			index(t.children)
+		}
+	}
+	index(b.roots)
+	return b
+}
+
+// mark sets the mark of n in the column in front of its indentation.
+func (b *baseline) mark(n *Node) {
+	k := keyOfNode(n)
+	b.seen[k] = true
+	t, ok := b.nodes[k]
+	switch {
+	case !ok:
+		n.Indent = marked(n.Indent, '+')
+	case t.Type != n.Type || t.Leaf != n.Leaf || n.Leaf && (t.Value != n.Value || t.Note != n.Note):
+		n.Indent = marked(n.Indent, '!')
+	}
+}
+
+// marked returns indent with its first column replaced by mark.
+func marked(indent string, mark byte) string {
+	if indent == "" {
+		return string(mark) + " "
+	}
+	return string(mark) + indent[1:]
+}
+
+// children returns the nodes of the baseline below the one matching n.
+func (b *baseline) children(n *Node) []*tree {
+	if t, ok := b.nodes[keyOfNode(n)]; ok {
+		return t.children
+	}
+	return nil
+}
+
+// removed renders the trees that were not matched by the dumped value,
+// marked with -.
+func (b *baseline) removed(v *variable, ts []*tree) {
+	for _, t := range ts {
+		if b.seen[keyOfNode(&t.Node)] {
+			continue
+		}
+		v.removedTree(t)
+	}
+}
+
+// removedTree renders t, a tree of the baseline missing from the dump.
+func (v *variable) removedTree(t *tree) {
+	n := t.Node
+	n.Indent = marked(v.indentation(n.Depth), '-')
+	if err := v.renderer.Open(&v.line, &n); err != nil && v.err == nil {
+		v.err = err
+	}
+	v.flushLine()
+	for _, c := range t.children {
+		v.removedTree(c)
+	}
+	if err := v.renderer.Close(&v.line, &n); err != nil && v.err == nil {
+		v.err = err
+	}
+	v.flushLine()
+}
@@ -0,0 +1,51 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import "testing"
+
+type settings struct {
+	Port  int
+	Host  string
+	Tags  []string
+	Extra map[string]int
+}
+
+func TestDumpWithBaseline(t *testing.T) {
+	old := settings{8080, "localhost", []string{"a", "b"}, map[string]int{"x": 1, "y": 2}}
+	cur := settings{8081, "localhost", []string{"a"}, map[string]int{"x": 1, "z": 3}}
+	want := "  (godump.settings)\n" +
+		"!   Port(int) 8081\n" +
+		"    Host(string) \"localhost\"\n" +
+		"    Tags([]string)\n" +
+		"      0 \"a\"\n" +
+		"-     1 \"b\"\n" +
+		"    Extra(map[string]int)\n" +
+		"      x(int) 1\n" +
+		"+     z(int) 3\n" +
+		"-     y(int) 2\n"
+	if got := SdumpWithBaseline(cur, old, WithElementTypes(false)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	if got, want := SdumpWithBaseline(old, old), Sdump(old); got != indentLines(want) {
+		t.Errorf("unchanged: got\n%s\nwant\n%s", got, want)
+	}
+	if got, want := SdumpWithBaseline(cur, old, WithJSON(true)), Sdump(cur, WithJSON(true)); got != want {
+		t.Errorf("JSON: got\n%s\nwant\n%s", got, want)
+	}
+}
+
+// indentLines indents the lines of s by the column of the marks.
+func indentLines(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		if i == 0 || s[i-1] == '\n' {
+			b = append(b, "  "...)
+		}
+		b = append(b, s[i])
+	}
+	return string(b)
+}
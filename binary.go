@@ -0,0 +1,50 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+)
+
+// Number of marshaled bytes shown for encoding.BinaryMarshaler values.
+const maxBinaryBytes = 64
+
+// WithBinaryMarshaler renders values implementing encoding.BinaryMarshaler,
+// but not fmt.Stringer, as the hex encoding of their marshaled bytes rather
+// than their fields. This suits opaque types like hash states and keys.
+func WithBinaryMarshaler(enable bool) Option {
+	return func(d *Dumper) {
+		d.binaryMarshaler = enable
+	}
+}
+
+// marshalBinary returns the leaf value and note rendering val through its
+// MarshalBinary method, if it has one.
+func marshalBinary(val reflect.Value) (value, note string, ok bool) {
+	if !val.CanInterface() || val.Kind() == reflect.Ptr && val.IsNil() || val.Kind() == reflect.Interface {
+		return "", "", false
+	}
+	i := val.Interface()
+	if _, ok := i.(fmt.Stringer); ok {
+		return "", "", false
+	}
+	m, ok := i.(encoding.BinaryMarshaler)
+	if !ok {
+		return "", "", false
+	}
+	b, err := m.MarshalBinary()
+	if err != nil {
+		return "", "", false
+	}
+
+	note = fmt.Sprintf("(%d bytes)", len(b))
+	if len(b) > maxBinaryBytes {
+		return "0x" + hex.EncodeToString(b[:maxBinaryBytes]) + "...", note, true
+	}
+	return "0x" + hex.EncodeToString(b), note, true
+}
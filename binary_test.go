@@ -0,0 +1,40 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"crypto/sha256"
+	"strings"
+	"testing"
+)
+
+type key struct {
+	b []byte
+}
+
+func (k key) MarshalBinary() ([]byte, error) {
+	return k.b, nil
+}
+
+func TestBinaryMarshaler(t *testing.T) {
+	d := NewDumper(WithBinaryMarshaler(true))
+	v := struct{ K key }{key{[]byte{0xde, 0xad}}}
+	want := "(struct { K godump.key })\n  K(godump.key) 0xdead (2 bytes)\n"
+	if got := d.Sdump(v); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Hash states marshal their internal state.
+	h := sha256.New()
+	h.Write([]byte("abc"))
+	got := d.Sdump(h)
+	if !strings.HasPrefix(got, "(*sha256.Digest) 0x") || !strings.HasSuffix(got, " bytes)\n") {
+		t.Errorf("hash state: %q", got)
+	}
+
+	if got := Sdump(v); got == want {
+		t.Error("binary rendering enabled by default")
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import "sync"
+
+// Budget bounds the output of all dumps sharing it, e.g. the dumps made
+// while serving one request, so debugging output stays bounded under load
+// however large or deeply linked the values are:
+//
+//	b := godump.NewBudget(1000, 8, 64<<10)
+//	d := godump.NewDumper(godump.WithBudget(b))
+//	d.Dump(req)
+//	d.Dump(state)
+//
+// Once the budget is spent, dumps stop where they are with a line noting
+// ...(budget exhausted), and later dumps only consist of that line.
+// Budgets are safe for concurrent use.
+type Budget struct {
+	// Depth limit of every dump
+	depth int
+
+	mu           sync.Mutex
+	nodes, bytes int // left; unlimited if not limited
+	limitNodes   bool
+	limitBytes   bool
+}
+
+// NewBudget returns a budget of nodes values and bytes bytes of output in
+// total, limiting every dump to values nested at most depth levels deep, as
+// WithMaxDepth does. Zero means unlimited. The lines of values closing
+// composite ones, e.g. closing braces, are written even when the budget is
+// spent, so the output can overrun the bytes by a few lines.
+func NewBudget(nodes, depth, bytes int) *Budget {
+	return &Budget{
+		depth:      depth,
+		nodes:      nodes,
+		bytes:      bytes,
+		limitNodes: nodes > 0,
+		limitBytes: bytes > 0,
+	}
+}
+
+// WithBudget makes dumps take their output from b. Cached output is not
+// used with a budget.
+func WithBudget(b *Budget) Option {
+	return func(d *Dumper) {
+		d.budget = b
+	}
+}
+
+// Exhausted reports whether b is spent.
+func (b *Budget) Exhausted() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.spent()
+}
+
+// Remaining returns the numbers of values and bytes left in b, or -1 if
+// they are unlimited.
+func (b *Budget) Remaining() (nodes, bytes int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	nodes, bytes = -1, -1
+	if b.limitNodes {
+		nodes = max(b.nodes, 0)
+	}
+	if b.limitBytes {
+		bytes = max(b.bytes, 0)
+	}
+	return nodes, bytes
+}
+
+func (b *Budget) spent() bool {
+	return b.limitNodes && b.nodes <= 0 || b.limitBytes && b.bytes <= 0
+}
+
+// spendNode takes a value from b, reporting whether there was any left.
+func (b *Budget) spendNode() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.spent() {
+		return false
+	}
+	b.nodes--
+	return true
+}
+
+// spendBytes takes n bytes of output from b.
+func (b *Budget) spendBytes(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bytes -= n
+}
+
+// depthLimit returns the depth limit maxDepth tightened to that of b.
+func (b *Budget) depthLimit(maxDepth int) int {
+	if b == nil || b.depth <= 0 || maxDepth > 0 && maxDepth <= b.depth {
+		return maxDepth
+	}
+	return b.depth
+}
+
+// overBudget stops the dump at the value found at path, since the budget is
+// spent, noting it in its place.
+func (v *variable) overBudget(path string) {
+	v.exhausted = true
+	v.truncated(path, TruncatedBudget, 0)
+	d := int(v.indent) + 1
+	n := &Node{
+		Depth:  d,
+		Indent: v.indentation(d),
+		Note:   "...(budget exhausted)",
+		Leaf:   true,
+	}
+	v.open(n)
+	v.close(n)
+}
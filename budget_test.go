@@ -0,0 +1,57 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBudget(t *testing.T) {
+	type node struct {
+		Val  int
+		Next *node
+	}
+	list := &node{Val: 1}
+	list.Next = &node{Val: 2, Next: &node{Val: 3}}
+
+	b := NewBudget(5, 0, 0)
+	var truncations []Truncation
+	d := NewDumper(WithBudget(b), WithTruncationReport(func(ts []Truncation) { truncations = ts }))
+	want := `(*godump.node)
+  (godump.node)
+    Val(int) 1
+    Next(*godump.node)
+      Next(godump.node)
+        ...(budget exhausted)
+`
+	if got := d.Sdump(list); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if want := []Truncation{{"Next.Val", TruncatedBudget, 0}}; !reflect.DeepEqual(truncations, want) {
+		t.Errorf("truncations: got %+v, want %+v", truncations, want)
+	}
+	if got := d.Sdump(42); got != "...(budget exhausted)\n" {
+		t.Errorf("dump after the budget is spent: got %q", got)
+	}
+	if !b.Exhausted() {
+		t.Error("budget not exhausted")
+	}
+	if nodes, bytes := b.Remaining(); nodes != 0 || bytes != -1 {
+		t.Errorf("Remaining() = %d, %d, want 0, -1", nodes, bytes)
+	}
+
+	b = NewBudget(0, 1, 60)
+	d = NewDumper(WithBudget(b))
+	want = `(*godump.node)
+  (godump.node) ...(max depth reached)
+`
+	if got := d.Sdump(list); got != want {
+		t.Errorf("depth: got\n%s\nwant\n%s", got, want)
+	}
+	if got := d.Sdump([]int{1, 2, 3}); got != "([]int)\n  ...(budget exhausted)\n" {
+		t.Errorf("bytes: got %q", got)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// wireFormat is the wire representation of an integer field, selected by
+// its dump tag: be16, be32, be64, le16, le32 or le64, e.g.
+//
+//	Length uint16 `dump:"be16"`
+//
+// is dumped as
+//
+//	Length(uint16) 513 (be16: 02 01)
+type wireFormat struct {
+	name  string
+	order binary.ByteOrder
+	bytes int
+}
+
+var wireFormats = []wireFormat{
+	{"be16", binary.BigEndian, 2},
+	{"be32", binary.BigEndian, 4},
+	{"be64", binary.BigEndian, 8},
+	{"le16", binary.LittleEndian, 2},
+	{"le32", binary.LittleEndian, 4},
+	{"le64", binary.LittleEndian, 8},
+}
+
+// wireFormatOf returns the wire format of the field f, if its tag selects
+// one.
+func wireFormatOf(f reflect.StructField) (*wireFormat, bool) {
+	for i := range wireFormats {
+		if _, ok := tagOption(f, wireFormats[i].name); ok {
+			return &wireFormats[i], true
+		}
+	}
+	return nil, false
+}
+
+// note returns the note of the integer val in the wire format w, or false if
+// val is not an integer.
+func (w *wireFormat) note(val reflect.Value) (string, bool) {
+	var u uint64
+	bits := uint(w.bytes * 8)
+	fits := true
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := val.Int()
+		u = uint64(i)
+		fits = bits == 64 || i>>(bits-1) == 0 || i>>(bits-1) == -1
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u = val.Uint()
+		fits = bits == 64 || u>>bits == 0
+	default:
+		return "", false
+	}
+	if !fits {
+		return "(" + w.name + ": overflow)", true
+	}
+	b := make([]byte, 8)
+	w.order.PutUint64(b, u)
+	if w.order == binary.BigEndian {
+		b = b[8-w.bytes:]
+	} else {
+		b = b[:w.bytes]
+	}
+	return fmt.Sprintf("(%s: % x)", w.name, b), true
+}
+
+// annotateWire notes the representation of val, the value of n, in the wire
+// format w if it is an integer. Unsigned integers, otherwise shown in hex,
+// are shown in decimal next to it.
+func (v *variable) annotateWire(val reflect.Value, n *Node, w *wireFormat) {
+	note, ok := w.note(val)
+	if !ok {
+		return
+	}
+	if k := val.Kind(); k >= reflect.Uint && k <= reflect.Uintptr && v.d.locale == nil {
+		n.Value = strconv.FormatUint(val.Uint(), 10)
+	}
+	n.annotate(note)
+}
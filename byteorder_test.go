@@ -0,0 +1,30 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import "testing"
+
+func TestWireFormats(t *testing.T) {
+	type frame struct {
+		Length uint16 `dump:"be16"`
+		Seq    uint32 `dump:"le32"`
+		Offset int16  `dump:"name=off,be16"`
+		Flags  int    `dump:"be16"`
+		ID     uint64 `dump:"le64"`
+		Kind   string `dump:"be16"`
+	}
+	v := frame{Length: 513, Seq: 1, Offset: -2, Flags: 70000, ID: 0x0102, Kind: "data"}
+	want := `(godump.frame)
+  Length(uint16) 513 (be16: 02 01)
+  Seq(uint32) 1 (le32: 01 00 00 00)
+  off(int16) -2 (be16: ff fe)
+  Flags(int) 70000 (be16: overflow)
+  ID(uint64) 258 (le64: 02 01 00 00 00 00 00 00)
+  Kind(string) "data"
+`
+	if got := Sdump(v, WithInline(200)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
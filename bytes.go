@@ -0,0 +1,166 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Default number of bytes of a byte slice or array shown before it is
+// truncated.
+const maxBytes = 256
+
+// WithByteDetection renders byte slices and arrays holding UTF-8 text as a
+// string, and other ones as a hex dump like that of hexdump -C. The choice
+// is noted next to the type.
+func WithByteDetection(enable bool) Option {
+	return func(d *Dumper) {
+		d.byteDetection = enable
+	}
+}
+
+// WithHexdump controls whether byte slices and arrays are rendered as a hex
+// dump like that of hexdump -C rather than element by element:
+//
+//	Data([]uint8) (20 bytes)
+//	  00000000 00 01 02 03 04 05 06 07  08 09 0a 0b 0c 0d 0e 0f  |................|
+//	  00000010 41 42 43 44                                       |ABCD|
+//
+// It is enabled by default.
+func WithHexdump(enable bool) Option {
+	return func(d *Dumper) {
+		d.hexdump = enable
+	}
+}
+
+// WithMaxBytes truncates the text and hex dumps of byte slices and arrays
+// after n bytes. Zero means unlimited; the default is 256.
+func WithMaxBytes(n int) Option {
+	return func(d *Dumper) {
+		d.maxBytes = n
+	}
+}
+
+// isBytes reports whether val is a byte slice or array.
+func isBytes(val reflect.Value) bool {
+	k := val.Kind()
+	return (k == reflect.Slice || k == reflect.Array) && val.Type().Elem().Kind() == reflect.Uint8
+}
+
+// bytesOf returns up to max bytes of the byte slice or array val.
+func bytesOf(val reflect.Value, max int) []byte {
+	l := val.Len()
+	if l > max {
+		l = max
+	}
+	b := make([]byte, l)
+	for i := range b {
+		b[i] = byte(val.Index(i).Uint())
+	}
+	return b
+}
+
+// isText reports whether b looks like text: valid UTF-8 without control
+// characters other than white space.
+func isText(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+// byteLimit returns the number of bytes shown of byte slices and arrays
+// of length l.
+func (v *variable) byteLimit(l int) int {
+	if v.d.maxBytes <= 0 || l < v.d.maxBytes {
+		return l
+	}
+	return v.d.maxBytes
+}
+
+// bytesNode renders the byte slice or array val as a hex dump, or as text
+// if detect is set and it looks like text.
+func (v *variable) bytesNode(val reflect.Value, n *Node, detect bool) {
+	l := val.Len()
+	b := bytesOf(val, v.byteLimit(l))
+	truncated := ""
+	if l > len(b) {
+		truncated = ", truncated"
+		v.truncated(n.Path, TruncatedBytes, l-len(b))
+	}
+
+	if !detect {
+		n.annotate(fmt.Sprintf("(%d bytes%s)", l, truncated))
+		v.open(n)
+		v.hexdump(b, n)
+		return
+	}
+	if isText(b) {
+		// Do not cut a rune in half.
+		for len(b) > 0 && !utf8.Valid(b) {
+			b = b[:len(b)-1]
+		}
+		n.Leaf = true
+		n.Value = strconv.Quote(string(b))
+		n.annotate(fmt.Sprintf("(text, %d bytes%s)", l, truncated))
+		v.open(n)
+		return
+	}
+
+	n.annotate(fmt.Sprintf("(binary, %d bytes%s)", l, truncated))
+	v.open(n)
+	v.hexdump(b, n)
+}
+
+// hexdump writes b as lines of 16 bytes in the format of hexdump -C below n.
+func (v *variable) hexdump(b []byte, n *Node) {
+	d := n.Depth + 1
+	for off := 0; off < len(b); off += 16 {
+		row := b[off:]
+		if len(row) > 16 {
+			row = row[:16]
+		}
+		var line strings.Builder
+		for i := 0; i < 16; i++ {
+			if i == 8 {
+				line.WriteByte(' ')
+			}
+			if i < len(row) {
+				fmt.Fprintf(&line, "%02x ", row[i])
+			} else {
+				line.WriteString("   ")
+			}
+		}
+		line.WriteString(" |")
+		for _, c := range row {
+			if c < 0x20 || c > 0x7e {
+				c = '.'
+			}
+			line.WriteByte(c)
+		}
+		line.WriteByte('|')
+
+		c := &Node{
+			Depth:  d,
+			Indent: v.indentation(d),
+			Path:   n.Path,
+			Name:   fmt.Sprintf("%08x", off),
+			Leaf:   true,
+			Note:   line.String(),
+		}
+		v.open(c)
+		v.close(c)
+	}
+}
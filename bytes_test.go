@@ -0,0 +1,57 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestByteDetection(t *testing.T) {
+	d := NewDumper(WithByteDetection(true))
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{[]byte("héllo\n"), "([]uint8) \"héllo\\n\" (text, 7 bytes)\n"},
+		{[4]byte{'a', 'b', 'c', 'd'}, "([4]uint8) \"abcd\" (text, 4 bytes)\n"},
+		{[]byte("\x00\x01binary\xffdata, more than a row"), "([]uint8) (binary, 30 bytes)\n" +
+			"  00000000 00 01 62 69 6e 61 72 79  ff 64 61 74 61 2c 20 6d  |..binary.data, m|\n" +
+			"  00000010 6f 72 65 20 74 68 61 6e  20 61 20 72 6f 77        |ore than a row|\n"},
+	}
+	for _, tt := range tests {
+		if got := d.Sdump(tt.v); got != tt.want {
+			t.Errorf("got\n%q\nwant\n%q", got, tt.want)
+		}
+	}
+
+	long := d.Sdump([]byte(strings.Repeat("x", 300)))
+	if want := "([]uint8) \"" + strings.Repeat("x", 256) + "\" (text, 300 bytes, truncated)\n"; long != want {
+		t.Errorf("truncated text: %q", long)
+	}
+}
+
+func TestHexdump(t *testing.T) {
+	v := struct{ Data []byte }{[]byte("\x00\x01\x02\x03\x04\x05\x06\x07\x08\x09\x0a\x0b\x0c\x0d\x0e\x0fABCD")}
+	want := "(struct { Data []uint8 })\n" +
+		"  Data([]uint8) (20 bytes)\n" +
+		"    00000000 00 01 02 03 04 05 06 07  08 09 0a 0b 0c 0d 0e 0f  |................|\n" +
+		"    00000010 41 42 43 44                                       |ABCD|\n"
+	if got := Sdump(v); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	want = "(struct { Data []uint8 })\n" +
+		"  Data([]uint8) (20 bytes, truncated)\n" +
+		"    00000000 00 01 02 03                                       |....|\n"
+	if got := Sdump(v, WithMaxBytes(4)); got != want {
+		t.Errorf("truncated: got\n%s\nwant\n%s", got, want)
+	}
+
+	want = "([2]uint8)\n  0(uint8) 0x61\n  1(uint8) 0x62\n"
+	if got := Sdump([2]byte{'a', 'b'}, WithHexdump(false)); got != want {
+		t.Errorf("disabled: got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Cache holds the rendered output of values reached through pointers, so
+// dumps repeatedly reaching the same immutable values, e.g. in monitoring
+// loops, render them once. It is safe for concurrent use, but must only be
+// shared by Dumpers with the same configuration.
+type Cache struct {
+	mu      sync.Mutex
+	gen     uint64
+	entries map[cacheKey][]cachedLine
+}
+
+// cacheKey identifies the rendering of the value pointed to in a context.
+type cacheKey struct {
+	ptr   uintptr
+	typ   reflect.Type
+	name  string
+	depth int
+	limit int // depth limit relative to the value; -1 for none
+	hops  int // pointers left to follow; -1 for all
+	color bool
+}
+
+type cachedLine struct {
+	indent int64
+	text   string
+}
+
+// NewCache returns an empty Cache.
+func NewCache() *Cache {
+	return &Cache{entries: make(map[cacheKey][]cachedLine)}
+}
+
+// WithCache makes the Dumper reuse the output cached in c for values reached
+// through pointers, as long as it was cached with the same generation, and
+// cache the output of the others. Bumping gen invalidates everything cached,
+// so it must be changed whenever any value dumped may have been modified.
+// Dumping the same pointer at different depths or under different names is
+// cached separately. Caching is disabled while redaction rules, handlers,
+// formatters, custom renderers or chunked, split or baseline dumps are in
+// use, since their output depends on more than the pointer.
+func WithCache(c *Cache, gen uint64) Option {
+	return func(d *Dumper) {
+		d.cache = c
+		d.cacheGen = gen
+	}
+}
+
+// cacheKey returns the key of the pointer val, dumped as n, if its output
+// can be cached.
+func (v *variable) cacheKey(val reflect.Value, n *Node) (cacheKey, bool) {
+	d := v.d
+	if d.cache == nil || val.IsNil() || len(d.redactRules) > 0 || d.ignored != nil || len(d.ignoredPaths) > 0 || d.budget != nil || d.truncationReport != nil || d.redactionReport != nil || d.redactionSummary || d.provenance != nil || d.classify || d.sharedPointers || d.hasHandlers() || d.hasFormatters() ||
+		d.treeRender != nil || v.chunk != nil || v.shallow != nil || v.baseline != nil || v.lines != nil {
+		return cacheKey{}, false
+	}
+	r, ok := v.renderer.(textRenderer)
+	if !ok {
+		return cacheKey{}, false
+	}
+	// Colors depend on the destination of the dump, if automatic.
+	k := cacheKey{ptr: val.Pointer(), typ: val.Type(), name: n.Name, depth: n.Depth, limit: -1, hops: -1, color: r.color}
+	if v.depthLimit > 0 {
+		k.limit = v.depthLimit - n.Depth
+	}
+	if d.followPointers >= 0 {
+		k.hops = d.followPointers - v.pointers
+	}
+	return k, true
+}
+
+// lookup returns the lines cached for k in the generation gen.
+func (c *Cache) lookup(k cacheKey, gen uint64) ([]cachedLine, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gen != gen {
+		return nil, false
+	}
+	lines, ok := c.entries[k]
+	return lines, ok
+}
+
+// store caches lines for k in the generation gen, dropping the entries of
+// other generations.
+func (c *Cache) store(k cacheKey, gen uint64, lines []cachedLine) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gen != gen {
+		c.gen = gen
+		c.entries = make(map[cacheKey][]cachedLine)
+	}
+	c.entries[k] = lines
+}
+
+// cachedElem dumps the value pointed to by val, the pointer of n, from the
+// cache if possible, and caches it otherwise.
+func (v *variable) cachedElem(val reflect.Value, n *Node) {
+	k, ok := v.cacheKey(val, n)
+	if !ok {
+		v.elem, v.deref = n.Elem, true
+		v.dump(val.Elem(), n.Name, n.Path)
+		return
+	}
+	if lines, ok := v.d.cache.lookup(k, v.d.cacheGen); ok {
+		saved := v.indent
+		for _, l := range lines {
+			v.indent = l.indent
+			v.line.WriteString(l.text)
+			v.flushLine()
+		}
+		v.indent = saved
+		return
+	}
+
+	var lines []cachedLine
+	cycles := v.cycles
+	v.captures = append(v.captures, &lines)
+	v.elem, v.deref = n.Elem, true
+	v.dump(val.Elem(), n.Name, n.Path)
+	v.captures = v.captures[:len(v.captures)-1]
+	// Cycles are cut short relative to the values containing this one, so
+	// their output is only valid here.
+	if v.err == nil && v.cycles == cycles {
+		v.d.cache.store(k, v.d.cacheGen, lines)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import "testing"
+
+func TestCache(t *testing.T) {
+	shared := &S{1, 2}
+	v := []*S{shared, shared}
+	c := NewCache()
+	want := Sdump(v)
+	for i := 0; i < 2; i++ {
+		if got := Sdump(v, WithCache(c, 1)); got != want {
+			t.Errorf("dump %d: got\n%s\nwant\n%s", i, got, want)
+		}
+	}
+	if len(c.entries) != 2 {
+		t.Errorf("got %d entries, want 2", len(c.entries))
+	}
+
+	// Stale output is served until the generation changes.
+	shared.A = 3
+	if got := Sdump(v, WithCache(c, 1)); got != want {
+		t.Errorf("same generation: got\n%s\nwant\n%s", got, want)
+	}
+	want = Sdump(v)
+	if got := Sdump(v, WithCache(c, 2)); got != want {
+		t.Errorf("new generation: got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCacheIgnoredPaths(t *testing.T) {
+	type node struct{ Name, Secret string }
+	type branch struct{ Node *node }
+	n := &node{"n", "s"}
+	v := struct{ Left, Right branch }{branch{n}, branch{n}}
+	got := Sdump(v, WithCache(NewCache(), 1), WithIgnorePaths("Right.Node.Secret"))
+	want := Sdump(v, WithIgnorePaths("Right.Node.Secret"))
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCacheReports(t *testing.T) {
+	type secret struct {
+		Key  string `dump:"redact"`
+		Data []int
+	}
+	s := &secret{"k", []int{1, 2, 3}}
+	v := []*secret{s, s}
+	var truncations []Truncation
+	var redactions []Redaction
+	d := NewDumper(
+		WithCache(NewCache(), 1),
+		WithMaxElements(2),
+		WithTruncationReport(func(t []Truncation) { truncations = t }),
+		WithRedactionReport(func(r []Redaction) { redactions = r }),
+	)
+	for i := 0; i < 2; i++ {
+		d.Sdump(v)
+		if len(truncations) != 2 || len(redactions) != 2 {
+			t.Errorf("dump %d: got truncations %v and redactions %v, want 2 each", i, truncations, redactions)
+		}
+	}
+}
+
+func TestCacheColor(t *testing.T) {
+	shared := &S{1, 2}
+	v := []*S{shared, shared}
+	c := NewCache()
+	Sdump(v, WithCache(c, 1), WithColor(true))
+	if got, want := Sdump(v, WithCache(c, 1)), Sdump(v); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// WithCanonical selects a canonical output suitable for hashing or signing
+// dumps: map entries are sorted by key, numbers are printed in a normalized
+// decimal form, no memory addresses are printed, interface values are
+// replaced by their dynamic values, time.Time values are printed in RFC 3339
+// format in UTC, and the versioned header line is enabled. Combined with
+// other options affecting the layout, it is only stable as far as they are.
+func WithCanonical(canonical bool) Option {
+	return func(d *Dumper) {
+		d.canonical = canonical
+		if canonical {
+			d.header = true
+		}
+	}
+}
+
+// canonicalLeaf returns the canonical representation of the leaf val.
+func canonicalLeaf(val reflect.Value) string {
+	switch val.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(val.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(val.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(val.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return canonicalFloat(val.Float(), val.Type().Bits())
+	case reflect.Complex64, reflect.Complex128:
+		c := val.Complex()
+		bits := val.Type().Bits() / 2
+		return "(" + canonicalFloat(real(c), bits) + "," + canonicalFloat(imag(c), bits) + "i)"
+	case reflect.String:
+		return strconv.Quote(val.String())
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer, reflect.Map, reflect.Ptr, reflect.Slice, reflect.Interface:
+		if val.IsNil() {
+			return "nil"
+		}
+		return "<set>"
+	}
+	return leafValue(val)
+}
+
+func canonicalFloat(f float64, bits int) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case f == 0:
+		// Treat -0 like 0.
+		return "0"
+	}
+	return strconv.FormatFloat(f, 'g', -1, bits)
+}
+
+// canonicalTime returns the canonical representation of the time.Time val.
+func canonicalTime(val reflect.Value) (string, bool) {
+	if val.Type() != timeType || !val.CanInterface() {
+		return "", false
+	}
+	t := val.Interface().(time.Time)
+	return strconv.Quote(t.UTC().Format(time.RFC3339Nano)), true
+}
+
+// mapEntries returns the keys of the map val, named by name, and their
+// values, sorted by compareKeys. Unlike MapIndex, it finds the
+// values of keys not equal to themselves, like NaNs.
+func mapEntries(val reflect.Value, name func(reflect.Value) string) (keys, values []reflect.Value, names []string) {
+	iter := val.MapRange()
+	for iter.Next() {
+		keys = append(keys, iter.Key())
+		values = append(values, iter.Value())
+		names = append(names, name(iter.Key()))
+	}
+	sort.Sort(keySorter{keys, names, values})
+	return keys, values, names
+}
+
+type keySorter struct {
+	keys   []reflect.Value
+	names  []string
+	values []reflect.Value
+}
+
+func (s keySorter) Len() int { return len(s.keys) }
+func (s keySorter) Less(i, j int) bool {
+	return compareKeys(s.keys[i], s.keys[j], s.names[i], s.names[j]) < 0
+}
+func (s keySorter) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.names[i], s.names[j] = s.names[j], s.names[i]
+	s.values[i], s.values[j] = s.values[j], s.values[i]
+}
+
+// compareKeys orders the map keys a and b, named an and bn: numbers
+// numerically, strings lexically, false before true and other keys by their
+// names. Interface keys are ordered by their dynamic values, those of
+// different kinds by kind.
+func compareKeys(a, b reflect.Value, an, bn string) int {
+	for a.Kind() == reflect.Interface && !a.IsNil() {
+		a = a.Elem()
+	}
+	for b.Kind() == reflect.Interface && !b.IsNil() {
+		b = b.Elem()
+	}
+	ka, kb := keyClass(a.Kind()), keyClass(b.Kind())
+	if ka != kb {
+		return ka - kb
+	}
+	c := 0
+	switch {
+	case a.CanInt() && b.CanInt():
+		c = cmpOrdered(a.Int() < b.Int(), a.Int() > b.Int())
+	case a.CanUint() && b.CanUint():
+		c = cmpOrdered(a.Uint() < b.Uint(), a.Uint() > b.Uint())
+	case ka == numberKey:
+		x, y := numeric(a), numeric(b)
+		c = cmpOrdered(x < y, x > y)
+	case ka == stringKey:
+		c = cmpOrdered(a.String() < b.String(), a.String() > b.String())
+	case ka == boolKey:
+		c = cmpOrdered(!a.Bool() && b.Bool(), a.Bool() && !b.Bool())
+	}
+	if c == 0 {
+		c = cmpOrdered(an < bn, an > bn)
+	}
+	return c
+}
+
+// Classes of map keys, in their order.
+const (
+	boolKey = iota
+	numberKey
+	stringKey
+	otherKey
+)
+
+func keyClass(k reflect.Kind) int {
+	switch {
+	case k == reflect.Bool:
+		return boolKey
+	case isNumeric(k):
+		return numberKey
+	case k == reflect.String:
+		return stringKey
+	}
+	return otherKey
+}
+
+func cmpOrdered(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
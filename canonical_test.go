@@ -0,0 +1,73 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type event struct {
+	At     time.Time
+	Counts map[string]uint8
+	Ratio  float64
+	Extra  interface{}
+	Notify func()
+}
+
+func TestCanonical(t *testing.T) {
+	loc := time.FixedZone("CEST", 2*60*60)
+	v := event{
+		At:     time.Date(2014, 5, 1, 14, 0, 0, 0, loc),
+		Counts: map[string]uint8{"b": 2, "a": 1, "c": 3},
+		Ratio:  math.Copysign(0, -1),
+		Extra:  []interface{}{math.Inf(1)},
+		Notify: func() {},
+	}
+	want := "godump/" + strconv.Itoa(FormatVersion) + " opts=canonical,header\n" +
+		"(godump.event)\n" +
+		"  At(time.Time) \"2014-05-01T12:00:00Z\"\n" +
+		"  Counts(map[string]uint8)\n" +
+		"    a(uint8) 1\n" +
+		"    b(uint8) 2\n" +
+		"    c(uint8) 3\n" +
+		"  Ratio(float64) 0\n" +
+		"  Extra([]interface {})\n" +
+		"    0(float64) +Inf\n" +
+		"  Notify(func()) <set>\n"
+	d := NewDumper(WithCanonical(true))
+	for i := 0; i < 5; i++ {
+		if got := d.Sdump(v); got != want {
+			t.Fatalf("got\n%s\nwant\n%s", got, want)
+		}
+	}
+}
+
+func TestKeyOrder(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{map[int]int{10: 0, 2: 0, -1: 0}, "{-1:0, 2:0, 10:0}"},
+		{map[uint8]int{200: 0, 3: 0}, "{0x3:0, 0xc8:0}"},
+		{map[float64]int{1.5: 0, -3: 0, 10: 0}, "{-3:0, 1.5:0, 10:0}"},
+		{map[string]int{"b": 0, "B": 0, "a": 0}, `{"B":0, "a":0, "b":0}`},
+		{map[bool]int{true: 0, false: 0}, "{false:0, true:0}"},
+		{map[interface{}]int{"x": 0, 10: 0, 2: 0, true: 0}, `{true:0, 2:0, 10:0, "x":0}`},
+		{map[[2]int]int{{2, 1}: 0, {1, 2}: 0}, "{[2]int{1, 2}:0, [2]int{2, 1}:0}"},
+	}
+	for _, tt := range tests {
+		want := "(" + typeString(reflect.ValueOf(tt.v)) + ") " + tt.want + "\n"
+		for i := 0; i < 5; i++ {
+			if got := Sdump(tt.v, WithInline(200)); got != want {
+				t.Errorf("got %q, want %q", got, want)
+				break
+			}
+		}
+	}
+}
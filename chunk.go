@@ -0,0 +1,112 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"bytes"
+	"errors"
+	"hash"
+	"hash/fnv"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrBadToken is returned by DumpChunk for tokens it did not produce, or
+// produced for a dump whose lines up to the token have changed since.
+var ErrBadToken = errors.New("godump: bad resumption token")
+
+// chunk tracks the lines of a dump that belong to the requested chunk.
+type chunk struct {
+	skip  int         // lines to leave out before the chunk starts
+	left  int         // lines left in the chunk
+	more  bool        // whether lines were cut off after the chunk
+	want  uint64      // checksum the lines left out must have
+	sum   hash.Hash64 // checksum of the lines up to the end of the chunk
+	stale bool        // whether the lines left out differ from the token's
+}
+
+// DumpChunk writes at most lines lines of the dump of v to w, starting where
+// the chunk that returned token left off, or at the top for an empty token.
+// It returns the token resuming the dump after this chunk, or an empty
+// token if the dump is complete. Map entries are sorted so the chunks fit
+// together as long as v does not change in between; tokens hold a checksum
+// of the lines before them, and ErrBadToken is returned if these changed.
+// Tokens are opaque and the header, checksum and marker lines are not
+// written.
+//
+// Every chunk dumps v from the top again and leaves out the lines before
+// it, so paging through a dump of n lines costs O(n*n/lines) in total.
+// Lines must be at least 1.
+func (d *Dumper) DumpChunk(w io.Writer, v interface{}, token string, lines int) (next string, err error) {
+	if lines < 1 {
+		return "", errors.New("godump: chunk of " + strconv.Itoa(lines) + " lines")
+	}
+	c := &chunk{left: lines, sum: fnv.New64a()}
+	if token != "" {
+		n, sum, _ := strings.Cut(token, "-")
+		c.skip, err = strconv.Atoi(n)
+		if err != nil || c.skip <= 0 {
+			return "", ErrBadToken
+		}
+		if c.want, err = strconv.ParseUint(sum, 16, 64); err != nil {
+			return "", ErrBadToken
+		}
+	}
+	skip := c.skip
+	dump := d.newVariable(w, "")
+	dump.chunk = c
+	dump.root(reflect.ValueOf(v))
+	if err := dump.w.Flush(); err != nil {
+		return "", err
+	}
+	if c.stale || c.skip > 0 {
+		return "", ErrBadToken
+	}
+	if dump.err != nil {
+		return "", dump.err
+	}
+	if c.more {
+		next = strconv.Itoa(skip+lines) + "-" + strconv.FormatUint(c.sum.Sum64(), 16)
+	}
+	return next, nil
+}
+
+// DumpChunk is like the Dumper version, using the default configuration.
+func DumpChunk(w io.Writer, v interface{}, token string, lines int, opts ...Option) (string, error) {
+	return std().With(opts...).DumpChunk(w, v, token, lines)
+}
+
+// keep returns the lines of the output b that belong to the chunk. Nodes
+// rendered by templates may write several lines at once.
+func (c *chunk) keep(b []byte) []byte {
+	var kept []byte
+	for len(b) > 0 {
+		line := b
+		if i := bytes.IndexByte(b, '\n'); i >= 0 {
+			line = b[:i+1]
+		}
+		b = b[len(line):]
+		switch {
+		case c.skip > 0:
+			c.sum.Write(line)
+			c.skip--
+			if c.skip == 0 && c.sum.Sum64() != c.want {
+				// Stop the dump without writing anything.
+				c.stale, c.more = true, true
+				return nil
+			}
+		case c.left == 0:
+			c.more = true
+			return kept
+		default:
+			c.sum.Write(line)
+			c.left--
+			kept = append(kept, line...)
+		}
+	}
+	return kept
+}
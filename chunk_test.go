@@ -0,0 +1,98 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestDumpChunk(t *testing.T) {
+	v := map[string][]int{"b": {3, 4}, "a": {1, 2}}
+	want := "(map[string][]int)\n" +
+		"  a([]int)\n" +
+		"    0(int) 1\n" +
+		"    1(int) 2\n" +
+		"  b([]int)\n" +
+		"    0(int) 3\n" +
+		"    1(int) 4\n"
+	var all strings.Builder
+	token, chunks := "", 0
+	for {
+		var b strings.Builder
+		next, err := DumpChunk(&b, v, token, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := strings.Count(b.String(), "\n"); n > 3 {
+			t.Errorf("chunk %d has %d lines", chunks, n)
+		}
+		all.WriteString(b.String())
+		chunks++
+		if next == "" {
+			break
+		}
+		token = next
+	}
+	if got := all.String(); got != want || chunks != 3 {
+		t.Errorf("got %d chunks\n%s\nwant 3\n%s", chunks, got, want)
+	}
+
+	if _, err := DumpChunk(&all, v, "x", 3); err != ErrBadToken {
+		t.Errorf("bad token: got %v", err)
+	}
+	if _, err := DumpChunk(&all, v, "", 0); err == nil {
+		t.Error("no error for chunks of 0 lines")
+	}
+}
+
+func TestDumpChunkMultiline(t *testing.T) {
+	// The template writes two lines for every node at once.
+	tmpl := template.Must(template.New("").Parse("{{.Path}}\n{{.Value}}\n"))
+	v := []int{1, 2, 3}
+	var all strings.Builder
+	token := ""
+	for i := 0; i < 10; i++ {
+		var b strings.Builder
+		next, err := DumpChunk(&b, v, token, 3, WithTemplate(tmpl))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := strings.Count(b.String(), "\n"); n > 3 {
+			t.Errorf("chunk %d has %d lines", i, n)
+		}
+		all.WriteString(b.String())
+		if token = next; token == "" {
+			break
+		}
+	}
+	if got, want := all.String(), Sdump(v, WithTemplate(tmpl)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDumpChunkStale(t *testing.T) {
+	v := []int{1, 2, 3, 4}
+	var b strings.Builder
+	token, err := DumpChunk(&b, v, "", 2)
+	if err != nil || token == "" {
+		t.Fatalf("got %q, %v", token, err)
+	}
+	if _, err := DumpChunk(&b, v, token, 2); err != nil {
+		t.Errorf("same value: %v", err)
+	}
+	b.Reset()
+	// The lines before the token changed.
+	if _, err := DumpChunk(&b, []int{0, 2, 3, 4}, token, 2); err != ErrBadToken || b.Len() != 0 {
+		t.Errorf("changed value: got %v, wrote %q", err, b.String())
+	}
+	if _, err := DumpChunk(&b, []int{}, token, 2); err != ErrBadToken {
+		t.Errorf("shorter value: got %v", err)
+	}
+	if _, err := DumpChunk(&b, v, "2", 2); err != ErrBadToken {
+		t.Errorf("token without checksum: got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Classes of values for policies on where their dumps may be stored.
+const (
+	ClassPII         = "pii"
+	ClassSecret      = "secret"
+	ClassLargeBinary = "large-binary"
+)
+
+// Classifier returns the classes of the value at path, e.g. ClassPII for
+// e-mail addresses, or none.
+type Classifier func(path string, val reflect.Value) []string
+
+// Classification records the classes of a value.
+type Classification struct {
+	Path    string
+	Classes []string
+}
+
+// WithClassifier turns on classification, tagging the nodes of values
+// with the classes reported by classifiers, if any. Values are also tagged
+// with the class of the redaction rule masking them, as set by
+// WithRuleClass, and struct fields with the class of their
+// dump:"class=..." tag. The classes are carried by the Classes of nodes,
+// the JSON tree and the text output, as class=pii,secret after the note,
+// so dumps can be checked against policies before they are stored.
+// Classified values are not inlined.
+func WithClassifier(classifiers ...Classifier) Option {
+	return func(d *Dumper) {
+		d.classify = true
+		d.classifiers = append(d.classifiers, classifiers...)
+	}
+}
+
+// LargeBinary returns a classifier tagging byte slices and arrays longer
+// than n bytes as ClassLargeBinary.
+func LargeBinary(n int) Classifier {
+	return func(_ string, val reflect.Value) []string {
+		switch val.Kind() {
+		case reflect.Slice, reflect.Array:
+			if val.Type().Elem().Kind() == reflect.Uint8 && val.Len() > n {
+				return []string{ClassLargeBinary}
+			}
+		}
+		return nil
+	}
+}
+
+// WithClassificationReport calls f after every dump with the values
+// classified in it, in the order they were found, if classification is
+// turned on by WithClassifier.
+func WithClassificationReport(f func([]Classification)) Option {
+	return func(d *Dumper) {
+		d.classificationReport = f
+	}
+}
+
+// classTag returns the class the field f is tagged with, by dump:"class=...".
+func classTag(f reflect.StructField) string {
+	class, _ := tagOption(f, "class")
+	return class
+}
+
+// classifyNode tags n, the node of val, with its classes: that of the rule
+// masking it, if masked, of its field tag and those of the classifiers.
+func (v *variable) classifyNode(n *Node, val reflect.Value, rule string, masked bool, tag string) {
+	var classes []string
+	add := func(class string) {
+		for _, c := range classes {
+			if c == class {
+				return
+			}
+		}
+		classes = append(classes, class)
+	}
+	if masked {
+		if class := v.d.ruleClass(rule); class != "" {
+			add(class)
+		}
+	}
+	if tag != "" {
+		add(tag)
+	}
+	if val.CanInterface() {
+		for _, c := range v.d.classifiers {
+			for _, class := range c(n.Path, val) {
+				add(class)
+			}
+		}
+	}
+	if len(classes) == 0 {
+		return
+	}
+	n.Classes = classes
+	v.classifications = append(v.classifications, Classification{Path: n.Path, Classes: classes})
+}
+
+// WithRuleClass tags the values masked by the redaction rule named rule
+// with class. The values masked by RedactNames and dump:"redact" tags are
+// tagged with ClassSecret unless set otherwise.
+func WithRuleClass(rule, class string) Option {
+	return func(d *Dumper) {
+		if d.ruleClasses == nil {
+			d.ruleClasses = make(map[string]string)
+		}
+		d.ruleClasses[rule] = class
+	}
+}
+
+// ruleClass returns the class of the values masked by the rule named name.
+func (d *Dumper) ruleClass(name string) string {
+	if class, ok := d.ruleClasses[name]; ok {
+		return class
+	}
+	switch name {
+	case "names", tagRule:
+		return ClassSecret
+	}
+	return ""
+}
+
+// classified reports whether a classifier tags val, found at path.
+func (v *variable) classified(path string, val reflect.Value) bool {
+	if !v.d.classify || !val.CanInterface() {
+		return false
+	}
+	for _, c := range v.d.classifiers {
+		if len(c(path, val)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// classNote returns the classes of n as written after its note.
+func classNote(n *Node) string {
+	return "class=" + strings.Join(n.Classes, ",")
+}
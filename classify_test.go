@@ -0,0 +1,63 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type member struct {
+	Email    string `dump:"class=pii"`
+	Password string
+	Avatar   []byte
+	Plan     string
+}
+
+func TestClassify(t *testing.T) {
+	a := member{"bob@example.com", "hunter2", []byte("GIF8"), "pro"}
+	var report []Classification
+	d := NewDumper(
+		WithInline(80),
+		WithRedact(RedactNames()),
+		WithClassifier(LargeBinary(2)),
+		WithClassificationReport(func(c []Classification) { report = c }),
+	)
+	got := d.Sdump(a)
+	want := "(godump.member)\n" +
+		"  Email(string) \"bob@example.com\" class=pii\n" +
+		"  Password(string) ***REDACTED*** class=secret\n" +
+		"  Avatar([]uint8) (4 bytes) class=large-binary\n" +
+		"    00000000 47 49 46 38                                       |GIF8|\n" +
+		"  Plan(string) \"pro\"\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	wantReport := []Classification{
+		{"Email", []string{ClassPII}},
+		{"Password", []string{ClassSecret}},
+		{"Avatar", []string{ClassLargeBinary}},
+	}
+	if !reflect.DeepEqual(report, wantReport) {
+		t.Errorf("report = %v, want %v", report, wantReport)
+	}
+
+	// Classes are left out unless classification is turned on.
+	if got := NewDumper(WithInline(80)).Sdump(member{Email: "x"}); strings.Contains(got, "class=") {
+		t.Errorf("unclassified dump got %q", got)
+	}
+}
+
+func TestClassifyJSON(t *testing.T) {
+	d := NewDumper(WithJSON(true), WithRedact(RedactNames()), WithRuleClass("names", ClassPII), WithClassifier())
+	got := d.Sdump(struct{ Token, Name string }{"t", "n"})
+	want := `{"type":"struct { Token string; Name string }","kind":"struct","children":[` +
+		`{"path":"Token","name":"Token","type":"string","kind":"string","value":"***REDACTED***","classes":["pii"]},` +
+		`{"path":"Name","name":"Name","type":"string","kind":"string","value":"\"n\""}]}` + "\n"
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command godump-view connects to a program streaming its dumps with
+// godump.ListenAndStream and pretty-prints them as they arrive.
+//
+// Usage:
+//
+//	godump-view [-addr host:port] [-label pattern] [-color=false]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/liudng/godump"
+)
+
+const (
+	colorReset = "\x1b[0m"
+	colorLabel = "\x1b[1;36m"
+	colorName  = "\x1b[32m"
+	colorType  = "\x1b[33m"
+)
+
+var (
+	addr  = flag.String("addr", "localhost:7070", "address of the stream server")
+	label = flag.String("label", "", "only show dumps whose label matches this pattern")
+	color = flag.Bool("color", true, "colorize output")
+)
+
+// Matches a dump line: indentation, field name, (type) and the rest.
+var lineRE = regexp.MustCompile(`^(\s*)([^(\s]*)(\(.*?\))( .*|)$`)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("godump-view: ")
+	flag.Parse()
+
+	c, err := godump.DialStream(*addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer c.Close()
+	c.Filter = *label
+
+	for {
+		rec, err := c.Recv()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		show(os.Stdout, rec)
+	}
+}
+
+func show(w io.Writer, rec *godump.Record) {
+	header := fmt.Sprintf("--- #%d %s %s (%s)", rec.Seq, rec.Time.Format("15:04:05.000"), rec.Label, rec.Type)
+	if rec.ID != "" {
+		header += " id=" + rec.ID
+	}
+	if !*color {
+		fmt.Fprintf(w, "%s\n%s", header, rec.Text)
+		return
+	}
+
+	fmt.Fprintf(w, "%s%s%s\n", colorLabel, header, colorReset)
+	for _, line := range strings.SplitAfter(rec.Text, "\n") {
+		if line == "" {
+			continue
+		}
+		m := lineRE.FindStringSubmatch(strings.TrimSuffix(line, "\n"))
+		if m == nil {
+			fmt.Fprint(w, line)
+			continue
+		}
+		fmt.Fprintf(w, "%s%s%s%s%s%s%s%s\n", m[1], colorName, m[2], colorReset, colorType, m[3], colorReset, m[4])
+	}
+}
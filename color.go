@@ -0,0 +1,60 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"io"
+	"os"
+)
+
+// colorMode tells whether the default grammar is rendered in color.
+type colorMode int
+
+const (
+	colorAuto colorMode = iota // when writing to a terminal
+	colorOn
+	colorOff
+)
+
+// ANSI escape sequences of the parts of lines.
+const (
+	colorName  = "\x1b[33m" // yellow
+	colorType  = "\x1b[36m" // cyan
+	colorValue = "\x1b[32m" // green
+	colorNote  = "\x1b[2m"  // faint
+	colorReset = "\x1b[0m"
+)
+
+// WithColor controls whether names, types and values are rendered in
+// different ANSI colors, which makes large dumps easier to scan. By
+// default dumps are colored when they are written straight to a terminal,
+// unless the NO_COLOR environment variable is set or TERM is dumb. Colors
+// only apply to the default grammar.
+func WithColor(enable bool) Option {
+	return func(d *Dumper) {
+		d.color = colorOff
+		if enable {
+			d.color = colorOn
+		}
+	}
+}
+
+// colorize renders the dump of v in color if it uses the default grammar.
+func (v *variable) colorize() {
+	if r, ok := v.renderer.(textRenderer); ok {
+		r.color = true
+		v.renderer = r
+	}
+}
+
+// isTerminal reports whether w is a terminal accepting colors.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,51 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestColor(t *testing.T) {
+	v := struct {
+		A int
+		B *S
+	}{1, nil}
+	want := "(\x1b[36mstruct { A int; B *godump.S }\x1b[0m)\n" +
+		"  \x1b[33mA\x1b[0m(\x1b[36mint\x1b[0m) \x1b[32m1\x1b[0m\n" +
+		"  \x1b[33mB\x1b[0m(\x1b[36m*godump.S\x1b[0m) \x1b[32mnil\x1b[0m\n"
+	if got := Sdump(v, WithColor(true)); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := Sdump(v, WithColor(true), WithColor(false)), Sdump(v); got != want {
+		t.Errorf("disabled: got %q, want %q", got, want)
+	}
+	if got := Sdump(v, WithColor(true), WithJSON(true)); got != Sdump(v, WithJSON(true)) {
+		t.Errorf("JSON: got %q", got)
+	}
+}
+
+func TestColorAuto(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if isTerminal(f) {
+		t.Error("regular file taken for a terminal")
+	}
+	if err := Fdump(f, 1); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "(int) 1\n" {
+		t.Errorf("got %q", b)
+	}
+}
@@ -0,0 +1,342 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// configOptions maps the names of the settings of config files to the
+// functions turning their values into options.
+var configOptions = map[string]func(string) (Option, error){
+	"addresses":    boolOption(WithAddresses),
+	"binary":       boolOption(WithBinaryMarshaler),
+	"bytes":        bytesOption,
+	"canonical":    boolOption(WithCanonical),
+	"checksum":     boolOption(WithChecksum),
+	"color":        boolOption(WithColor),
+	"correlation":  correlationOption,
+	"decode":       boolOption(WithDecode),
+	"describe":     boolOption(WithDescribe),
+	"downsample":   downsampleOption,
+	"elemtypes":    boolOption(WithElementTypes),
+	"errortext":    boolOption(WithErrorText),
+	"expand":       boolOption(WithFullExpansion),
+	"fields":       fieldsOption,
+	"filter":       filterOption,
+	"follow":       intOption(WithFollowPointers),
+	"funcnames":    boolOption(WithFuncNames),
+	"header":       boolOption(WithHeader),
+	"hexdump":      boolOption(WithHexdump),
+	"ignorefields": listOption(WithIgnoreFields),
+	"ignorepaths":  listOption(WithIgnorePaths),
+	"indent":       intOption(WithIndent),
+	"inline":       intOption(WithInline),
+	"inlineptr":    boolOption(WithInlinePointers),
+	"json":         boolOption(WithJSON),
+	"internals":    boolOption(WithRuntimeInternals),
+	"jsonnames":    boolOption(WithJSONNames),
+	"keyfield":     func(s string) (Option, error) { return WithKeyField(s), nil },
+	"keylen":       intOption(WithMaxKeyLen),
+	"locale":       func(s string) (Option, error) { return WithLocale(s), nil },
+	"markers":      boolOption(WithMarkers),
+	"maxbytes":     intOption(WithMaxBytes),
+	"maxdepth":     intOption(WithMaxDepth),
+	"maxelements":  intOption(WithMaxElements),
+	"maxstring":    intOption(WithMaxStringLen),
+	"omitnilfuncs": boolOption(WithOmitNilFuncs),
+	"preview":      previewOption,
+	"redact":       redactOption,
+	"sequence":     boolOption(WithSequence),
+	"sexpr":        boolOption(WithSExpr),
+	"shared":       boolOption(WithSharedPointers),
+	"singleline":   boolOption(WithSingleLine),
+	"snapshot":     boolOption(WithMapSnapshot),
+	"strict":       boolOption(WithStrict),
+	"stringers":    stringersOption,
+	"stringtable":  boolOption(WithStringTable),
+	"style":        styleOption,
+	"timestamp":    boolOption(WithTimestamp),
+	"toml":         boolOption(WithTOML),
+	"unexported":   boolOption(WithUnexported),
+	"verbosity":    intOption(WithVerbosity),
+	"wellknown":    boolOption(WithWellKnownTypes),
+	"zero":         boolOption(WithZeroElision),
+}
+
+func boolOption(f func(bool) Option) func(string) (Option, error) {
+	return func(s string) (Option, error) {
+		if s == "" {
+			return f(true), nil
+		}
+		b, err := strconv.ParseBool(s)
+		return f(b), err
+	}
+}
+
+func intOption(f func(int) Option) func(string) (Option, error) {
+	return func(s string) (Option, error) {
+		n, err := strconv.Atoi(s)
+		return f(n), err
+	}
+}
+
+func listOption(f func(...string) Option) func(string) (Option, error) {
+	return func(s string) (Option, error) {
+		items := strings.Split(s, ",")
+		for i := range items {
+			items[i] = strings.TrimSpace(items[i])
+		}
+		return f(items...), nil
+	}
+}
+
+func redactOption(s string) (Option, error) {
+	if s == "" {
+		return WithRedact(RedactNames()), nil
+	}
+	return listOption(func(names ...string) Option { return WithRedact(RedactNames(names...)) })(s)
+}
+
+func correlationOption(s string) (Option, error) {
+	if s == "" {
+		return WithCorrelationIDs(true), nil
+	}
+	return WithCorrelationID(s), nil
+}
+
+func bytesOption(s string) (Option, error) {
+	switch s {
+	case "detect":
+		return WithByteDetection(true), nil
+	case "raw":
+		return WithByteDetection(false), nil
+	}
+	return nil, fmt.Errorf("want detect or raw, got %q", s)
+}
+
+func fieldsOption(s string) (Option, error) {
+	switch s {
+	case "exported":
+		return WithFields(ExportedOnly), nil
+	case "all":
+		return WithFields(AllFields), nil
+	case "unsafe":
+		return WithFields(AllWithUnsafe), nil
+	}
+	return nil, fmt.Errorf("want exported, all or unsafe, got %q", s)
+}
+
+func stringersOption(s string) (Option, error) {
+	switch s {
+	case "ignore":
+		return WithStringers(IgnoreStringer), nil
+	case "only":
+		return WithStringers(StringerOnly), nil
+	case "fields":
+		return WithStringers(StringerPlusFields), nil
+	}
+	return nil, fmt.Errorf("want ignore, only or fields, got %q", s)
+}
+
+func styleOption(s string) (Option, error) {
+	for _, st := range Styles() {
+		if st.Name() == s {
+			return WithStyle(st), nil
+		}
+	}
+	return nil, fmt.Errorf("want default, spew, tree or compact, got %q", s)
+}
+
+func filterOption(s string) (Option, error) {
+	q, err := ParseQuery(s)
+	return WithFilter(q), err
+}
+
+func downsampleOption(s string) (Option, error) {
+	mode, points, _ := strings.Cut(s, ":")
+	n, err := strconv.Atoi(points)
+	if err != nil {
+		return nil, err
+	}
+	switch mode {
+	case "nth":
+		return WithDownsample(EveryNth, n), nil
+	case "mean":
+		return WithDownsample(BucketMean, n), nil
+	}
+	return nil, fmt.Errorf("want nth:N or mean:N, got %q", s)
+}
+
+// ParseConfig reads the options of a config file from r. Config files have
+// one setting per line, named like the options in the dump header:
+//
+//	# more detail while debugging
+//	verbosity = 2
+//	inline = 80
+//	locale = de-DE
+//	canonical
+//
+// A setting without a value turns it on. Empty lines and lines starting with
+// # are ignored. The supported settings are addresses, binary, bytes (detect
+// or raw), canonical, checksum, color, correlation (an ID, by default one
+// per dump), decode, describe, downsample (nth:N or mean:N), elemtypes,
+// errortext, expand, fields (exported, all or unsafe), filter, follow,
+// funcnames, header, hexdump, ignorefields (comma-separated field names),
+// ignorepaths (comma-separated path patterns), indent, inline, inlineptr,
+// internals, json, jsonnames, keyfield, keylen, locale, markers, maxbytes,
+// maxdepth, maxelements, maxstring, omitnilfuncs, preview (BYTES:LINES),
+// redact (comma-separated parts of sensitive names, by default
+// SensitiveNames), sequence, sexpr, shared, singleline, snapshot, strict,
+// stringers (ignore, only or fields), stringtable, style (default, spew,
+// tree or compact), timestamp, toml, unexported, verbosity, wellknown and
+// zero.
+func ParseConfig(r io.Reader) ([]Option, error) {
+	var opts []Option
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimSpace(s.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, value, _ := strings.Cut(text, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		parse, ok := configOptions[name]
+		if !ok {
+			return nil, fmt.Errorf("godump: config line %d: unknown setting %q", line, name)
+		}
+		opt, err := parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("godump: config line %d: %s: %v", line, name, err)
+		}
+		opts = append(opts, opt)
+	}
+	return opts, s.Err()
+}
+
+// LoadConfig reads the options of the config file at path, as described by
+// ParseConfig.
+func LoadConfig(path string) ([]Option, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseConfig(f)
+}
+
+// ConfigWatcher reloads a config file into the default Dumper.
+type ConfigWatcher struct {
+	path string
+	base *Dumper
+	sig  chan os.Signal
+
+	mu  sync.Mutex
+	mod time.Time
+	err error
+
+	stop chan struct{}
+	once sync.Once
+	done chan struct{}
+}
+
+// WatchConfig makes the default Dumper the one it is at the time of the
+// call, configured by the config file at path, and keeps it that way as the
+// file changes: the file is reloaded whenever its modification time
+// changes, checked every interval unless interval is zero, and whenever
+// the process receives one of the signals, e.g. syscall.SIGHUP. This way
+// the dump verbosity of a live service can be raised without restarting
+// it. The new Dumper is swapped in atomically. WatchConfig fails if the
+// file cannot be loaded at first; later failures keep the configuration
+// in effect and are reported by Err.
+func WatchConfig(path string, interval time.Duration, sig ...os.Signal) (*ConfigWatcher, error) {
+	cw := &ConfigWatcher{
+		path: path,
+		base: std(),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if err := cw.Reload(); err != nil {
+		return nil, err
+	}
+	if len(sig) > 0 {
+		cw.sig = make(chan os.Signal, 1)
+		signal.Notify(cw.sig, sig...)
+	}
+	go cw.loop(interval)
+	return cw, nil
+}
+
+func (cw *ConfigWatcher) loop(interval time.Duration) {
+	defer close(cw.done)
+	var tick <-chan time.Time
+	if interval > 0 {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		tick = t.C
+	}
+	for {
+		select {
+		case <-tick:
+			fi, err := os.Stat(cw.path)
+			cw.mu.Lock()
+			changed := err == nil && !fi.ModTime().Equal(cw.mod)
+			cw.mu.Unlock()
+			if changed {
+				cw.Reload()
+			}
+		case <-cw.sig:
+			cw.Reload()
+		case <-cw.stop:
+			return
+		}
+	}
+}
+
+// Reload reloads the config file right away.
+func (cw *ConfigWatcher) Reload() error {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	fi, err := os.Stat(cw.path)
+	var opts []Option
+	if err == nil {
+		// Broken files are not retried until they change again.
+		cw.mod = fi.ModTime()
+		opts, err = LoadConfig(cw.path)
+	}
+	cw.err = err
+	if err != nil {
+		return err
+	}
+	SetDefault(cw.base.With(opts...))
+	return nil
+}
+
+// Err returns the error of the last reload, or nil if it succeeded.
+func (cw *ConfigWatcher) Err() error {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	return cw.err
+}
+
+// Stop stops watching the config file. The default Dumper is left as
+// configured by the last successful reload.
+func (cw *ConfigWatcher) Stop() {
+	cw.once.Do(func() {
+		if cw.sig != nil {
+			signal.Stop(cw.sig)
+		}
+		close(cw.stop)
+	})
+	<-cw.done
+}
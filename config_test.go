@@ -0,0 +1,77 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseConfig(t *testing.T) {
+	opts, err := ParseConfig(strings.NewReader("# comment\n\nverbosity = 2\ninline=80\nheader\nfields = all\ndownsample = mean:4\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "godump/21 opts=downsample=mean:4,fields=all,header,inline=80,verbosity=2"
+	if got := NewDumper(opts...).Header(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	for _, cfg := range []string{"verbose = 2", "inline = wide", "fields = some", "header = maybe", "filter = A["} {
+		if _, err := ParseConfig(strings.NewReader(cfg)); err == nil {
+			t.Errorf("%q: no error", cfg)
+		}
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	defer SetDefault(Default())
+	path := filepath.Join(t.TempDir(), "godump.conf")
+	if _, err := WatchConfig(path, 0); err == nil {
+		t.Fatal("missing file: no error")
+	}
+	if err := os.WriteFile(path, []byte("inline = 80\n"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	cw, err := WatchConfig(path, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cw.Stop()
+	v := []int{1, 2}
+	if got, want := Sdump(v), "([]int) {1, 2}\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if err := os.WriteFile(path, []byte("inline = 0\nfollow = 1\n"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	mod := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, mod, mod); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); Default().inlineWidth != 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("config not reloaded")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got, want := Sdump(v), "([]int)\n  0(int) 1\n  1(int) 2\n"; got != want {
+		t.Errorf("reloaded: got %q, want %q", got, want)
+	}
+
+	if err := os.WriteFile(path, []byte("bogus\n"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	if err := cw.Reload(); err == nil || cw.Err() != err {
+		t.Errorf("bad config: got %v, Err %v", err, cw.Err())
+	}
+	if Default().followPointers != 1 {
+		t.Error("bad config replaced the configuration")
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import "context"
+
+type labelKey struct{}
+
+// ContextWithLabel returns a copy of ctx carrying label, so the dumps made
+// with DumpCtx in its scope, e.g. while handling a request, are tagged with
+// it. Labels added to contexts that already carry one are appended to it,
+// separated by a slash, like req-123/db.
+func ContextWithLabel(ctx context.Context, label string) context.Context {
+	if outer := LabelFromContext(ctx); outer != "" {
+		label = outer + "/" + label
+	}
+	return context.WithValue(ctx, labelKey{}, label)
+}
+
+// LabelFromContext returns the label carried by ctx, or an empty string.
+func LabelFromContext(ctx context.Context) string {
+	label, _ := ctx.Value(labelKey{}).(string)
+	return label
+}
+
+// DumpCtx is like DumpLabel, with the label carried by ctx.
+func (d *Dumper) DumpCtx(ctx context.Context, v interface{}) {
+	d.DumpLabel(LabelFromContext(ctx), v)
+}
+
+// DumpCtx is like DumpLabel, with the label carried by ctx:
+//
+//	ctx = godump.ContextWithLabel(ctx, "req-123")
+//	godump.DumpCtx(ctx, order)
+func DumpCtx(ctx context.Context, v interface{}, opts ...Option) {
+	std().With(opts...).DumpCtx(ctx, v)
+}
@@ -0,0 +1,34 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDumpCtx(t *testing.T) {
+	ctx := context.Background()
+	if got := LabelFromContext(ctx); got != "" {
+		t.Errorf("unlabeled context: got %q", got)
+	}
+	ctx = ContextWithLabel(ctx, "req-123")
+	sub := ContextWithLabel(ctx, "db")
+	if got := LabelFromContext(sub); got != "req-123/db" {
+		t.Errorf("got %q, want %q", got, "req-123/db")
+	}
+
+	var labels []string
+	sink := SinkFunc(func(label string, sev Severity, chunk []byte) error {
+		labels = append(labels, label)
+		return nil
+	})
+	d := NewDumper(WithSink(Debug, sink))
+	d.DumpCtx(ctx, 1)
+	DumpCtx(sub, 1, WithSink(Debug, sink))
+	if len(labels) != 2 || labels[0] != "req-123" || labels[1] != "req-123/db" {
+		t.Errorf("got labels %q", labels)
+	}
+}
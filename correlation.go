@@ -0,0 +1,82 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// WithCorrelationID makes every dump start with id, e.g. the ID of the
+// request being served, on the line of the sequence number and time:
+//
+//	seq=12 id=req-7f3a
+//
+// so a dump referenced in a log line can be matched with its full version
+// kept in a file, a RingSink or a viewer. The ID is also set on the
+// Records of RingSinks and viewers. Documents hold it in their own syntax:
+// JSON as the id of the top-level object, TOML in a comment line,
+// S-expressions in a block comment and single-line dumps as a prefix. An
+// empty id disables it.
+func WithCorrelationID(id string) Option {
+	return func(d *Dumper) {
+		d.correlationID = id
+	}
+}
+
+// WithCorrelationIDs makes every dump start with an ID of its own, as
+// returned by NewCorrelationID, unless WithCorrelationID gives one. The ID
+// of a dump is found by CorrelationID.
+func WithCorrelationIDs(enable bool) Option {
+	return func(d *Dumper) {
+		d.correlationIDs = enable
+	}
+}
+
+// NewCorrelationID returns a random ID of 16 hexadecimal digits, for
+// logging it before passing it to WithCorrelationID.
+func NewCorrelationID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// CorrelationID returns the ID dump starts with, if it has one.
+func CorrelationID(dump string) (string, bool) {
+	// The stamp line follows the marker and header lines, if any.
+	for i := 0; i < 3 && dump != ""; i++ {
+		var line string
+		line, dump, _ = strings.Cut(dump, "\n")
+		if id, ok := stampID(line); ok {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// stampID returns the correlation ID of line if it is a stamp line.
+func stampID(line string) (string, bool) {
+	id, ok := "", false
+	for _, field := range strings.Split(line, " ") {
+		key, value, _ := strings.Cut(field, "=")
+		switch key {
+		case "seq", "time":
+		case "id":
+			id, ok = value, true
+		default:
+			return "", false
+		}
+	}
+	return id, ok
+}
+
+// newCorrelationID returns the ID of a new dump, if it has one.
+func (d *Dumper) newCorrelationID() string {
+	if d.correlationID == "" && d.correlationIDs {
+		return NewCorrelationID()
+	}
+	return d.correlationID
+}
@@ -0,0 +1,81 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestCorrelationID(t *testing.T) {
+	if got, want := NewDumper(WithCorrelationID("req-1")).Sdump(1), "id=req-1\n(int) 1\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	var seq uint64
+	got := NewDumper(WithSequence(true), WithCorrelationID("req-1")).Sdump(1)
+	if _, err := fmt.Sscanf(got, "seq=%d id=req-1\n(int) 1\n", &seq); err != nil {
+		t.Errorf("%q: %v", got, err)
+	}
+	if id, ok := CorrelationID(got); !ok || id != "req-1" {
+		t.Errorf("CorrelationID = %q, %v, want req-1", id, ok)
+	}
+
+	d := NewDumper(WithCorrelationIDs(true), WithMarkers(true), WithHeader(true))
+	a, b := d.Sdump(1), d.Sdump(1)
+	idA, okA := CorrelationID(a)
+	idB, okB := CorrelationID(b)
+	if !okA || !okB || len(idA) != 16 || idA == idB {
+		t.Errorf("generated IDs %q and %q, want distinct 16-digit IDs", idA, idB)
+	}
+
+	// Values looking like IDs are not taken for them.
+	if id, ok := CorrelationID(NewDumper().Sdump(struct{ S string }{"id=x"})); ok {
+		t.Errorf("CorrelationID of an unstamped dump = %q", id)
+	}
+}
+
+func TestCorrelationIDSink(t *testing.T) {
+	var logged bytes.Buffer
+	ring := NewRingSink(2)
+	d := NewDumper(
+		WithCorrelationID("req-2"),
+		WithSink(Debug, ring),
+		WithSink(Debug, LoggerSink(log.New(&logged, "", 0))),
+	)
+	d.DumpLabel("tick", 1)
+	recs := ring.Records()
+	if len(recs) != 1 || recs[0].ID != "req-2" || !strings.HasPrefix(recs[0].Text, "id=req-2\n") {
+		t.Errorf("ring got %+v", recs)
+	}
+	if want := "debug tick id=req-2:\nid=req-2\n(int) 1\n"; logged.String() != want {
+		t.Errorf("log got %q, want %q", logged.String(), want)
+	}
+}
+
+func TestCorrelationIDDocuments(t *testing.T) {
+	tests := []struct {
+		opt  Option
+		want string
+	}{
+		{WithJSON(true), `{"id":"req-3","type":"int","kind":"int","value":"1"}` + "\n"},
+		{WithSExpr(true), "#|id=req-3|# (int 1)\n"},
+		{WithTOML(true), "# id=req-3\nvalue = 1\n"},
+		{WithSingleLine(true), "id=req-3 1\n"},
+	}
+	for _, tt := range tests {
+		ring := NewRingSink(1)
+		d := NewDumper(WithCorrelationID("req-3"), WithSink(Debug, ring), tt.opt)
+		if got := d.Sdump(1); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+		d.Dump(1)
+		if recs := ring.Records(); len(recs) != 1 || recs[0].ID != "req-3" {
+			t.Errorf("ring got %+v", recs)
+		}
+	}
+}
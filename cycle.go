@@ -0,0 +1,57 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// visit identifies a pointer, map or slice being dumped. The type tells
+// apart a struct and its first field, which share their address, and the
+// length and capacity the slices of the same array.
+type visit struct {
+	ptr      uintptr
+	typ      reflect.Type
+	len, cap int
+}
+
+// visitOf returns the visit of the pointer, map or slice val.
+func visitOf(val reflect.Value) visit {
+	p := visit{ptr: val.Pointer(), typ: val.Type()}
+	if val.Kind() == reflect.Slice {
+		p.len, p.cap = val.Len(), val.Cap()
+	}
+	return p
+}
+
+// cycle reports whether the non-nil pointer, map or slice val is being
+// dumped further up already, so dumping it again would never end.
+func (v *variable) cycle(val reflect.Value) bool {
+	if val.IsNil() || !v.visiting[visitOf(val)] {
+		return false
+	}
+	v.cycles++
+	return true
+}
+
+// enter marks the pointer, map or slice val as being dumped until leave is
+// called.
+func (v *variable) enter(val reflect.Value) {
+	if v.visiting == nil {
+		v.visiting = make(map[visit]bool)
+	}
+	v.visiting[visitOf(val)] = true
+}
+
+func (v *variable) leave(val reflect.Value) {
+	delete(v.visiting, visitOf(val))
+}
+
+// cycleNote returns the text rendering the pointer, map or slice val in
+// place of a cycle.
+func cycleNote(val reflect.Value) string {
+	return fmt.Sprintf("<cycle to %#x>", val.Pointer())
+}
@@ -0,0 +1,68 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type listNode struct {
+	Value      int
+	Prev, Next *listNode
+}
+
+func TestCycle(t *testing.T) {
+	a := &listNode{Value: 1}
+	b := &listNode{Value: 2, Prev: a}
+	a.Next = b
+	want := "(*godump.listNode)\n" +
+		"  (godump.listNode)\n" +
+		"    Value(int) 1\n" +
+		"    Prev(*godump.listNode) nil\n" +
+		"    Next(*godump.listNode)\n" +
+		"      Next(godump.listNode)\n" +
+		"        Value(int) 2\n" +
+		fmt.Sprintf("        Prev(*godump.listNode) <cycle to %p>\n", a) +
+		"        Next(*godump.listNode) nil\n"
+	if got := Sdump(a); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	m := map[string]interface{}{"n": 1}
+	m["self"] = m
+	want = "(map[string]interface {})\n" +
+		"  n(int) 1\n" +
+		fmt.Sprintf("  self(map[string]interface {}) <cycle to %#x>\n", reflect.ValueOf(m).Pointer())
+	if got := Sdump(m, WithCanonical(true), WithHeader(false)); !strings.HasSuffix(got, want) {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	s := []interface{}{nil, 2}
+	s[0] = s
+	note := fmt.Sprintf("<cycle to %#x>", reflect.ValueOf(s).Pointer())
+	want = "([]interface {})\n" +
+		"  0([]interface {}) " + note + "\n" +
+		"  1(int) 2\n"
+	if got := Sdump(s); got != want {
+		t.Errorf("slice: got\n%s\nwant\n%s", got, want)
+	}
+	if got, want := Sdump(s, WithInline(200)), "([]interface {}) {"+note+", 2}\n"; got != want {
+		t.Errorf("inline slice: got %q, want %q", got, want)
+	}
+
+	want = fmt.Sprintf("(*godump.listNode) &{Value:1, Prev:nil, Next:&{Value:2, Prev:<cycle to %p>, Next:nil}}\n", a)
+	if got := Sdump(a, WithInline(200)); got != want {
+		t.Errorf("inline: got\n%s\nwant\n%s", got, want)
+	}
+
+	c := &listNode{Value: 1}
+	c.Next = &listNode{Value: 3, Prev: c}
+	if got, want := Diff(a, c), "Next.Value: -2 +3\n"; got != want {
+		t.Errorf("diff: got %q, want %q", got, want)
+	}
+}
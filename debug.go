@@ -0,0 +1,132 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Number of recent dumps kept for the debug handlers.
+const recentRecords = 32
+
+var (
+	// Guarded by streamMu.
+	recording bool
+	recent    []*Record
+)
+
+// remember adds rec to the recent recordings. streamMu must be held.
+func remember(rec *Record) {
+	if len(recent) == recentRecords {
+		copy(recent, recent[1:])
+		recent = recent[:recentRecords-1]
+	}
+	recent = append(recent, rec)
+}
+
+func recentRecordings() []*Record {
+	streamMu.Lock()
+	defer streamMu.Unlock()
+	recs := make([]*Record, len(recent))
+	copy(recs, recent)
+	return recs
+}
+
+// RegisterDebugHandlers installs Index on mux under /debug/godump/, following
+// the conventions of net/http/pprof. If mux is nil, http.DefaultServeMux is
+// used. From then on, dumps are also kept as recent recordings.
+func RegisterDebugHandlers(mux *http.ServeMux) {
+	if mux == nil {
+		mux = http.DefaultServeMux
+	}
+	streamMu.Lock()
+	recording = true
+	streamMu.Unlock()
+	mux.HandleFunc("/debug/godump/", Index)
+}
+
+// Index responds with an index of the registered values and recent
+// recordings. It also serves /debug/godump/value/<name> with the dump of a
+// registered value and /debug/godump/record/<seq> with a recorded dump.
+//
+// Dumps of values are paginated by the lines parameter: the response holds
+// at most that many lines and, if the dump goes on, a Godump-Resume header
+// whose value is passed as the resume parameter to get the next page.
+func Index(w http.ResponseWriter, r *http.Request) {
+	p := strings.TrimPrefix(r.URL.Path, "/debug/godump/")
+	switch {
+	case strings.HasPrefix(p, "value/"):
+		v, ok := lookup(strings.TrimPrefix(p, "value/"))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if r.FormValue("lines") == "" {
+			fmt.Fprint(w, Sdump(v))
+			return
+		}
+		lines, err := strconv.Atoi(r.FormValue("lines"))
+		if err != nil || lines <= 0 {
+			http.Error(w, "bad lines parameter", http.StatusBadRequest)
+			return
+		}
+		var b strings.Builder
+		next, err := DumpChunk(&b, v, r.FormValue("resume"), lines)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if next != "" {
+			w.Header().Set("Godump-Resume", next)
+		}
+		fmt.Fprint(w, b.String())
+	case strings.HasPrefix(p, "record/"):
+		seq, err := strconv.ParseUint(strings.TrimPrefix(p, "record/"), 10, 64)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		for _, rec := range recentRecordings() {
+			if rec.Seq == seq {
+				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+				fmt.Fprint(w, rec.Text)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	case p == "":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		indexTmpl.Execute(w, struct {
+			Values  []string
+			Records []*Record
+		}{Registered(), recentRecordings()})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+var indexTmpl = template.Must(template.New("index").Parse(`<html>
+<head>
+<title>/debug/godump/</title>
+</head>
+<body>
+<h2>Registered values</h2>
+<ul>
+{{range .Values}}<li><a href="value/{{.}}">{{.}}</a></li>
+{{else}}<li>none</li>
+{{end}}</ul>
+<h2>Recent dumps</h2>
+<ul>
+{{range .Records}}<li><a href="record/{{.Seq}}">#{{.Seq}}</a> {{.Time.Format "2006-01-02 15:04:05.000"}} {{.Label}} ({{.Type}})</li>
+{{else}}<li>none</li>
+{{end}}</ul>
+</body>
+</html>
+`))
@@ -0,0 +1,67 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func get(t *testing.T, url string) (int, string) {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(b)
+}
+
+func TestDebugHandlers(t *testing.T) {
+	mux := http.NewServeMux()
+	RegisterDebugHandlers(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	Register("config", []string{"x"})
+	defer Unregister("config")
+	DumpLabel("startup", 42)
+
+	code, body := get(t, srv.URL+"/debug/godump/")
+	if code != http.StatusOK || !strings.Contains(body, `href="value/config"`) || !strings.Contains(body, "startup (int)") {
+		t.Errorf("index: %d %s", code, body)
+	}
+
+	if _, body := get(t, srv.URL+"/debug/godump/value/config"); body != Sdump([]string{"x"}) {
+		t.Errorf("value: %q", body)
+	}
+
+	resp, err := http.Get(srv.URL + "/debug/godump/value/config?lines=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	next := resp.Header.Get("Godump-Resume")
+	if _, body := get(t, srv.URL+"/debug/godump/value/config?lines=1&resume="+next); next == "" || body != `  0(string) "x"`+"\n" {
+		t.Errorf("resumed value: %q after %q", body, next)
+	}
+
+	recs := recentRecordings()
+	last := recs[len(recs)-1]
+	if _, body := get(t, srv.URL+"/debug/godump/record/"+strconv.FormatUint(last.Seq, 10)); body != Sdump(42) {
+		t.Errorf("record: %q", body)
+	}
+
+	if code, _ := get(t, srv.URL+"/debug/godump/value/missing"); code != http.StatusNotFound {
+		t.Errorf("missing value: %d", code)
+	}
+}
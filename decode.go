@@ -0,0 +1,124 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Maximum size of decoded content
+	maxDecodedBytes = 64 << 10
+
+	// Maximum number of nested encodings undone, e.g. gzip inside base64
+	maxDecodeLevels = 3
+
+	// Minimum length of strings considered for base64 decoding
+	minBase64Len = 8
+)
+
+// WithDecode makes the Dumper recognize strings and byte slices holding
+// base64 encoded or gzip compressed content, and dump the decoded content
+// below them as a node named "decoded". Base64 is only decoded if the result
+// is text or gzip data, to avoid mistaking plain words for it. Decoding is
+// bounded; larger content is truncated.
+func WithDecode(enable bool) Option {
+	return func(d *Dumper) {
+		d.decode = enable
+	}
+}
+
+var base64Encodings = []*base64.Encoding{
+	base64.StdEncoding,
+	base64.URLEncoding,
+	base64.RawStdEncoding,
+	base64.RawURLEncoding,
+}
+
+// decodePayload undoes one layer of encoding of b, if it has one.
+func decodePayload(b []byte) (out []byte, how string, truncated, ok bool) {
+	if len(b) >= 2 && b[0] == 0x1f && b[1] == 0x8b {
+		zr, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, "", false, false
+		}
+		out, err = io.ReadAll(io.LimitReader(zr, maxDecodedBytes+1))
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return nil, "", false, false
+		}
+		if len(out) > maxDecodedBytes {
+			out, truncated = out[:maxDecodedBytes], true
+		}
+		return out, "gzip", truncated, true
+	}
+
+	s := strings.TrimSpace(string(b))
+	if len(s) < minBase64Len || base64.StdEncoding.DecodedLen(len(s)) > maxDecodedBytes {
+		return nil, "", false, false
+	}
+	for _, enc := range base64Encodings {
+		out, err := enc.DecodeString(s)
+		if err != nil {
+			continue
+		}
+		if isText(out) || len(out) >= 2 && out[0] == 0x1f && out[1] == 0x8b {
+			return out, "base64", false, true
+		}
+		return nil, "", false, false
+	}
+	return nil, "", false, false
+}
+
+// decoded dumps the decoded content of b, found at the given nesting level
+// of encodings, as a child of n. It reports whether b was decoded.
+func (v *variable) decoded(b []byte, n *Node, level int) bool {
+	if level == maxDecodeLevels {
+		return false
+	}
+	out, how, truncated, ok := decodePayload(b)
+	if !ok {
+		return false
+	}
+	note := "(" + how + ", " + strconv.Itoa(len(out)) + " bytes"
+	if truncated {
+		note += ", truncated"
+	}
+	note += ")"
+
+	d := n.Depth + 1
+	c := &Node{
+		Depth:  d,
+		Indent: v.indentation(d),
+		Path:   n.Path,
+		Name:   "decoded",
+		Note:   note,
+	}
+	if isText(out) {
+		c.Type = "string"
+		c.Kind = reflect.String
+		c.Leaf = true
+		c.Value = strconv.Quote(string(out))
+		v.open(c)
+		v.decoded(out, c, level+1)
+		v.close(c)
+		return true
+	}
+
+	c.Type = "[]uint8"
+	c.Kind = reflect.Slice
+	v.open(c)
+	if !v.decoded(out, c, level+1) {
+		out = out[:v.byteLimit(len(out))]
+		v.hexdump(out, c)
+	}
+	v.close(c)
+	return true
+}
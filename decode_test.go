@@ -0,0 +1,46 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"strconv"
+	"testing"
+)
+
+func gzipped(s string) []byte {
+	var b bytes.Buffer
+	zw := gzip.NewWriter(&b)
+	zw.Write([]byte(s))
+	zw.Close()
+	return b.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	d := NewDumper(WithDecode(true), WithByteDetection(true))
+
+	b64 := base64.StdEncoding.EncodeToString([]byte(`{"user":"bob"}`))
+	want := "(string) \"" + b64 + "\"\n" +
+		"  decoded(string) \"{\\\"user\\\":\\\"bob\\\"}\" (base64, 14 bytes)\n"
+	if got := d.Sdump(b64); got != want {
+		t.Errorf("base64: got\n%s\nwant\n%s", got, want)
+	}
+
+	z := gzipped("hello, world")
+	nested := base64.StdEncoding.EncodeToString(z)
+	got := d.Sdump(nested)
+	want = "(string) \"" + nested + "\"\n" +
+		"  decoded([]uint8) (base64, " + strconv.Itoa(len(z)) + " bytes)\n" +
+		"    decoded(string) \"hello, world\" (gzip, 12 bytes)\n"
+	if got != want {
+		t.Errorf("gzip in base64: got\n%s\nwant\n%s", got, want)
+	}
+
+	if got := d.Sdump("plain words"); got != Sdump("plain words") {
+		t.Errorf("plain string decoded: %q", got)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Describer is implemented by values exposing state that is not stored in
+// their fields, like the depth of a queue. The entries returned by Describe
+// are dumped, sorted by key, in a section named described below the fields
+// of the value:
+//
+//	(main.Queue)
+//	  Name(string) "jobs"
+//	  described
+//	    depth(int) 3
+type Describer interface {
+	Describe() map[string]interface{}
+}
+
+var describerType = reflect.TypeOf((*Describer)(nil)).Elem()
+
+// WithDescribe controls whether the Describe method of values implementing
+// Describer is called. It is enabled by default.
+func WithDescribe(enable bool) Option {
+	return func(d *Dumper) {
+		d.describe = enable
+	}
+}
+
+// isDescriber reports whether values of type t, or pointers to them, are
+// Describers.
+func isDescriber(t reflect.Type) bool {
+	return t.Implements(describerType) || t.Kind() != reflect.Ptr && reflect.PointerTo(t).Implements(describerType)
+}
+
+// described dumps the described section of n if val is a Describer.
+func (v *variable) described(val reflect.Value, n *Node) {
+	if !val.CanInterface() || val.Kind() == reflect.Ptr && val.IsNil() {
+		return
+	}
+	d, ok := val.Interface().(Describer)
+	if !ok {
+		return
+	}
+	desc, err := describe(d)
+
+	depth := n.Depth + 1
+	section := &Node{
+		Depth:  depth,
+		Indent: v.indentation(depth),
+		Path:   joinPath(n.Path, "described"),
+		Name:   "described",
+	}
+	if err != nil {
+		section.Note = err.Error()
+	}
+	v.open(section)
+	keys := make([]string, 0, len(desc))
+	for k := range desc {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	v.indent++
+	for _, k := range keys {
+		v.dump(reflect.ValueOf(desc[k]), k, section.Path+"["+k+"]")
+	}
+	v.indent--
+	v.close(section)
+}
+
+// describe calls the Describe method of d, recovering from panics.
+func describe(d Describer) (desc map[string]interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("<Describe panicked: %v>", r)
+		}
+	}()
+	return d.Describe(), nil
+}
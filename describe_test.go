@@ -0,0 +1,48 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import "testing"
+
+type queue struct {
+	Name  string
+	items []int
+}
+
+func (q *queue) Describe() map[string]interface{} {
+	return map[string]interface{}{"depth": len(q.items), "empty": len(q.items) == 0}
+}
+
+type broken struct{}
+
+func (broken) Describe() map[string]interface{} { panic("no") }
+
+func TestDescribe(t *testing.T) {
+	q := &queue{"jobs", []int{1, 2, 3}}
+	want := "(*godump.queue)\n" +
+		"  (godump.queue)\n" +
+		"    Name(string) \"jobs\"\n" +
+		"    described\n" +
+		"      depth(int) 3\n" +
+		"      empty(bool) false\n"
+	if got := Sdump(q); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got, want := Sdump(q, WithDescribe(false)), "(*godump.queue)\n  (godump.queue)\n    Name(string) \"jobs\"\n"; got != want {
+		t.Errorf("disabled: got\n%s\nwant\n%s", got, want)
+	}
+
+	want = "(godump.broken)\n  described <Describe panicked: no>\n"
+	if got := Sdump(broken{}); got != want {
+		t.Errorf("panic: got %q, want %q", got, want)
+	}
+}
+
+func TestDescribeInline(t *testing.T) {
+	want := "(godump.broken)\n  described <Describe panicked: no>\n"
+	if got := Sdump(broken{}, WithInline(80)); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,498 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Diff returns the paths where the values a and b differ, one per line, e.g.
+//
+//	User.Address.Zip: -"12345" +"54321"
+//
+// Elements of arrays and slices are aligned by their longest common
+// subsequence, so an inserted element is reported as such rather than as a
+// change of all elements after it:
+//
+//	Items[1]: +"new"
+//	Items[4]: moved to [2]
+//
+// Indices of removed and moved elements refer to a, those of inserted
+// elements to b. Elements named by WithKeyField are matched by key
+// instead. Values dumped as text, like errors, times and Stringers dumped
+// by their String method, are compared by their text. Of values masked by
+// WithRedact or dump:"redact" tags, only whether they differ is reported.
+// The result is empty if the values do not differ.
+func Diff(a, b interface{}, opts ...Option) string {
+	return std().With(opts...).Diff(a, b)
+}
+
+// Diff is the Dumper version of the package-level Diff.
+func (d *Dumper) Diff(a, b interface{}) string {
+	df := &differ{v: d.newVariable(nil, "")}
+	df.diff(reflect.ValueOf(a), reflect.ValueOf(b), "")
+	return df.out.String()
+}
+
+type differ struct {
+	v *variable
+
+	// Pairs of pointers or maps being compared, to stop at cycles
+	visiting map[[2]uintptr]bool
+
+	out strings.Builder
+
+	// Whether a difference was found, and whether to stop at the first
+	differs, first bool
+
+	// Whether masked values are compared in the clear, to tell whether
+	// they differ
+	clear bool
+
+	// Dumper of the keys of values
+	keys *Dumper
+}
+
+// keyDumper returns d without the limits, masks and decorations of its
+// dumps, so the dumps of values tell apart all values d compares.
+func keyDumper(d *Dumper) *Dumper {
+	return d.With(func(k *Dumper) {
+		k.renderer, k.treeRender, k.trace = textRenderer{}, nil, nil
+		k.maxDepth, k.maxElements, k.maxStringLen, k.maxKeyLen, k.maxBytes = 0, 0, 0, 0, 0
+		k.previewBytes, k.previewLines = 0, 0
+		k.typeDepth, k.pointerPolicies, k.followPointers = nil, nil, -1
+		k.downsampleMode, k.budget, k.cache, k.recency = 0, nil, nil, nil
+		k.filter, k.filterErr = nil, nil
+		k.redactRules, k.clearTags, k.ignored, k.provenance, k.classify = nil, true, nil, nil, false
+		k.redactionReport, k.truncationReport, k.classificationReport = nil, nil, nil
+		k.postProcessors, k.sinks, k.exportDir = nil, nil, ""
+		k.addresses, k.sharedPointers, k.color = false, false, colorOff
+	})
+}
+
+func (df *differ) line(path, s string) {
+	if df.first && df.differs {
+		return
+	}
+	df.differs = true
+	df.out.WriteString(tracePath(path) + ": " + s + "\n")
+}
+
+// text returns the single-line form of val, found at path.
+func (df *differ) text(val reflect.Value, path string) string {
+	if _, masked := df.masked(path, df.v.accessible(val)); masked {
+		return redacted
+	}
+	if b, ok := df.v.inline(nil, val, "", 0, math.MaxInt); ok {
+		return string(b)
+	}
+	return typeString(val) + "{...}"
+}
+
+// key returns the dump of val, identifying it in comparisons.
+func (df *differ) key(val reflect.Value) string {
+	if df.keys == nil {
+		df.keys = keyDumper(df.v.d)
+	}
+	var b strings.Builder
+	v := df.keys.newVariable(&b, "")
+	v.dump(val, "", "")
+	v.w.Flush()
+	return b.String()
+}
+
+func (df *differ) diff(a, b reflect.Value, path string) {
+	if df.first && df.differs {
+		return
+	}
+	a, b = df.v.accessible(a), df.v.accessible(b)
+	if !df.clear {
+		ruleA, maskedA := df.masked(path, a)
+		ruleB, maskedB := df.masked(path, b)
+		if maskedA || maskedB {
+			if ruleA != ignoredRule && ruleB != ignoredRule {
+				df.maskedDiff(a, b, path)
+			}
+			return
+		}
+	}
+	switch {
+	case !a.IsValid() || !b.IsValid() || a.Type() != b.Type():
+		if a.IsValid() != b.IsValid() || a.IsValid() && (a.Type() != b.Type() || df.key(a) != df.key(b)) {
+			df.line(path, "-"+df.text(a, path)+" +"+df.text(b, path))
+		}
+	case df.leafDiff(a, b, path):
+	case a.Kind() == reflect.Ptr || a.Kind() == reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				df.line(path, "-"+df.text(a, path)+" +"+df.text(b, path))
+			}
+			return
+		}
+		if a.Kind() == reflect.Interface {
+			df.diff(a.Elem(), b.Elem(), path)
+			return
+		}
+		if df.enter(a, b) {
+			df.diff(a.Elem(), b.Elem(), path)
+			df.leave(a, b)
+		}
+	case a.Kind() == reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			p := joinPath(path, a.Type().Field(i).Name)
+			x, okA := df.v.structField(a, i)
+			y, okB := df.v.structField(b, i)
+			if !okA && !okB || df.v.ignoredPath(p) {
+				continue
+			}
+			if !okA || !okB {
+				x, y = a.Field(i), b.Field(i)
+			}
+			if redactTagged(a.Type().Field(i)) && !df.clear {
+				df.maskedDiff(x, y, p)
+				continue
+			}
+			df.diff(x, y, p)
+		}
+	case a.Kind() == reflect.Map:
+		if df.enter(a, b) {
+			df.diffMaps(a, b, path)
+			df.leave(a, b)
+		}
+	case a.Kind() == reflect.Array || a.Kind() == reflect.Slice:
+		df.diffElements(a, b, path)
+	case (a.Kind() == reflect.Func || a.Kind() == reflect.Chan) && (a.IsNil() || b.IsNil()):
+		if a.IsNil() != b.IsNil() {
+			df.line(path, "-"+df.text(a, path)+" +"+df.text(b, path))
+		}
+	default:
+		if x, y := df.v.leafValue(a), df.v.leafValue(b); x != y {
+			df.line(path, "-"+x+" +"+y)
+		}
+	}
+}
+
+// enter marks the pair of pointers or maps a and b as being compared until
+// leave is called. It returns false if they are compared further up
+// already; their differences are reported there.
+func (df *differ) enter(a, b reflect.Value) bool {
+	pair := [2]uintptr{a.Pointer(), b.Pointer()}
+	if df.visiting[pair] {
+		return false
+	}
+	if df.visiting == nil {
+		df.visiting = make(map[[2]uintptr]bool)
+	}
+	df.visiting[pair] = true
+	return true
+}
+
+func (df *differ) leave(a, b reflect.Value) {
+	delete(df.visiting, [2]uintptr{a.Pointer(), b.Pointer()})
+}
+
+// masked returns the name of the rule masking val, found at path, if it
+// is valid.
+func (df *differ) masked(path string, val reflect.Value) (string, bool) {
+	if !val.IsValid() {
+		return "", false
+	}
+	return df.v.redactRule(path, val)
+}
+
+// maskedDiff compares the masked values a and b, found at path, reporting
+// only whether they differ.
+func (df *differ) maskedDiff(a, b reflect.Value, path string) {
+	c := &differ{v: df.v, first: true, clear: true, keys: df.keys}
+	c.diff(a, b, path)
+	if c.differs {
+		df.line(path, "redacted values differ")
+	}
+}
+
+// leafDiff compares a and b by the text they are rendered as instead of
+// their children, if they both are, e.g. errors by their message and times
+// by their instant rather than by their unexported fields. It reports
+// whether they were compared.
+func (df *differ) leafDiff(a, b reflect.Value, path string) bool {
+	x, ok := df.leafText(a)
+	if !ok {
+		return false
+	}
+	y, ok := df.leafText(b)
+	if !ok {
+		return false
+	}
+	if x != y {
+		df.line(path, "-"+x+" +"+y)
+	}
+	return true
+}
+
+// leafText returns the text val is rendered as instead of its children:
+// by a formatter, as a zero value, an error, a well-known type or by its
+// String method.
+func (df *differ) leafText(val reflect.Value) (string, bool) {
+	v := df.v
+	if isNil(val) {
+		return "", false
+	}
+	if s, ok := v.formatted(val); ok {
+		return s, true
+	}
+	if s, ok := v.zeroText(val); ok {
+		return s, true
+	}
+	if v.d.errorText && !v.d.fullExpansion {
+		if s, ok := errorText(val); ok {
+			return s, true
+		}
+	}
+	if v.d.wellKnown {
+		if s, ok := wellKnownText(val); ok {
+			return s, true
+		}
+	}
+	if s, p := v.stringerText(val, nil, false); p == StringerOnly {
+		return s, true
+	}
+	return "", false
+}
+
+func (df *differ) diffMaps(a, b reflect.Value, path string) {
+	entries := make(map[string][2]reflect.Value)
+	for i, m := range []reflect.Value{a, b} {
+		iter := m.MapRange()
+		for iter.Next() {
+			name := df.v.keyName(iter.Key())
+			e := entries[name]
+			e[i] = iter.Value()
+			entries[name] = e
+		}
+	}
+	df.diffEntries(entries, path)
+}
+
+// diffEntries diffs the pairs of values of entries, by name, reporting the
+// values missing from a or b as removed or inserted.
+func (df *differ) diffEntries(entries map[string][2]reflect.Value, path string) {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		e, p := entries[name], path+"["+name+"]"
+		switch {
+		case !e[1].IsValid():
+			df.line(p, "-"+df.text(e[0], p))
+		case !e[0].IsValid():
+			df.line(p, "+"+df.text(e[1], p))
+		default:
+			df.diff(e[0], e[1], p)
+		}
+	}
+}
+
+// diffElements aligns the elements of the arrays or slices a and b by
+// their longest common subsequence, as computed by align. Removals and insertions of the same
+// element are reported as moves; the remaining removed and inserted
+// elements between the same common ones are diffed pairwise, as changes.
+//
+// Elements named by a key field are matched by their names instead, like
+// the entries of maps, if all of them have unique ones.
+func (df *differ) diffElements(a, b reflect.Value, path string) {
+	if entries, ok := df.keyedElements(a, b, path); ok {
+		df.diffEntries(entries, path)
+		return
+	}
+	x := make([]string, a.Len())
+	for i := range x {
+		x[i] = df.key(a.Index(i))
+	}
+	y := make([]string, b.Len())
+	for j := range y {
+		y[j] = df.key(b.Index(j))
+	}
+
+	// Collect the runs of removed and inserted elements between the
+	// common ones.
+	type run struct{ removed, inserted []int }
+	var runs []run
+	var cur run
+	for _, e := range align(x, y) {
+		switch e.op {
+		case '=':
+			if cur.removed != nil || cur.inserted != nil {
+				runs = append(runs, cur)
+				cur = run{}
+			}
+		case '-':
+			cur.removed = append(cur.removed, e.i)
+		case '+':
+			cur.inserted = append(cur.inserted, e.j)
+		}
+	}
+	if cur.removed != nil || cur.inserted != nil {
+		runs = append(runs, cur)
+	}
+
+	// Match removals and insertions of equal elements as moves, each
+	// removal with the first unused insertion.
+	pending := make(map[string][]int)
+	for _, r := range runs {
+		for _, j := range r.inserted {
+			pending[y[j]] = append(pending[y[j]], j)
+		}
+	}
+	to := make(map[int]int)
+	used := make(map[int]bool)
+	for _, r := range runs {
+		for _, i := range r.removed {
+			if js := pending[x[i]]; len(js) > 0 {
+				to[i] = js[0]
+				used[js[0]] = true
+				pending[x[i]] = js[1:]
+			}
+		}
+	}
+
+	for _, r := range runs {
+		var removed, inserted []int
+		for _, i := range r.removed {
+			if j, ok := to[i]; ok {
+				df.line(path+"["+strconv.Itoa(i)+"]", "moved to ["+strconv.Itoa(j)+"]")
+			} else {
+				removed = append(removed, i)
+			}
+		}
+		for _, j := range r.inserted {
+			if !used[j] {
+				inserted = append(inserted, j)
+			}
+		}
+		for len(removed) > 0 && len(inserted) > 0 {
+			df.diff(a.Index(removed[0]), b.Index(inserted[0]), path+"["+strconv.Itoa(removed[0])+"]")
+			removed, inserted = removed[1:], inserted[1:]
+		}
+		for _, i := range removed {
+			p := path + "[" + strconv.Itoa(i) + "]"
+			df.line(p, "-"+df.text(a.Index(i), p))
+		}
+		for _, j := range inserted {
+			p := path + "[" + strconv.Itoa(j) + "]"
+			df.line(p, "+"+df.text(b.Index(j), p))
+		}
+	}
+}
+
+// maxLCSCells bounds the size of the table align computes the longest
+// common subsequence with. Longer sequences are aligned by position.
+const maxLCSCells = 1 << 22
+
+// edit is a step of the alignment of two sequences: op is '=' for the
+// common elements x[i] and y[j], '-' for the removal of x[i] and '+' for
+// the insertion of y[j].
+type edit struct {
+	op   byte
+	i, j int
+}
+
+// align returns the steps turning x into y, along their longest common
+// subsequence. Common prefixes and suffixes are matched first; if the rest
+// is too long for the table of the subsequence, it is aligned by position
+// instead.
+func align(x, y []string) []edit {
+	var edits []edit
+	p := 0
+	for p < len(x) && p < len(y) && x[p] == y[p] {
+		edits = append(edits, edit{'=', p, p})
+		p++
+	}
+	s := 0
+	for s < len(x)-p && s < len(y)-p && x[len(x)-1-s] == y[len(y)-1-s] {
+		s++
+	}
+	mx, my := x[p:len(x)-s], y[p:len(y)-s]
+
+	if (len(mx)+1)*(len(my)+1) > maxLCSCells {
+		for k := 0; k < len(mx) || k < len(my); k++ {
+			switch {
+			case k < len(mx) && k < len(my) && mx[k] == my[k]:
+				edits = append(edits, edit{'=', p + k, p + k})
+			default:
+				if k < len(mx) {
+					edits = append(edits, edit{'-', p + k, 0})
+				}
+				if k < len(my) {
+					edits = append(edits, edit{'+', 0, p + k})
+				}
+			}
+		}
+	} else {
+		// lcs[i][j] is the length of the LCS of mx[i:] and my[j:].
+		lcs := make([][]int, len(mx)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(my)+1)
+		}
+		for i := len(mx) - 1; i >= 0; i-- {
+			for j := len(my) - 1; j >= 0; j-- {
+				if mx[i] == my[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else if lcs[i+1][j] >= lcs[i][j+1] {
+					lcs[i][j] = lcs[i+1][j]
+				} else {
+					lcs[i][j] = lcs[i][j+1]
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(mx) || j < len(my) {
+			switch {
+			case i < len(mx) && j < len(my) && mx[i] == my[j]:
+				edits = append(edits, edit{'=', p + i, p + j})
+				i++
+				j++
+			case j == len(my) || (i < len(mx) && lcs[i+1][j] >= lcs[i][j+1]):
+				edits = append(edits, edit{'-', p + i, 0})
+				i++
+			default:
+				edits = append(edits, edit{'+', 0, p + j})
+				j++
+			}
+		}
+	}
+
+	for k := s; k > 0; k-- {
+		edits = append(edits, edit{'=', len(x) - k, len(y) - k})
+	}
+	return edits
+}
+
+// keyedElements returns the elements of the arrays or slices a and b by
+// their names, if all of them are named by a key field, uniquely. The
+// arrays or slices are found at path.
+func (df *differ) keyedElements(a, b reflect.Value, path string) (map[string][2]reflect.Value, bool) {
+	if df.v.d.keyField == "" {
+		return nil, false
+	}
+	entries := make(map[string][2]reflect.Value)
+	for side, val := range [2]reflect.Value{a, b} {
+		for i := 0; i < val.Len(); i++ {
+			name, ok := df.v.elementKey(val.Index(i), path+"["+strconv.Itoa(i)+"]")
+			if !ok || entries[name][side].IsValid() {
+				return nil, false
+			}
+			e := entries[name]
+			e[side] = val.Index(i)
+			entries[name] = e
+		}
+	}
+	return entries, true
+}
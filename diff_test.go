@@ -0,0 +1,68 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type order struct {
+	ID    int
+	Items []string
+	Attrs map[string]int
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		a, b interface{}
+		want string
+	}{
+		{1, 1, ""},
+		{1, 2, ".: -1 +2\n"},
+		{
+			order{1, []string{"a", "b", "c", "d"}, map[string]int{"x": 1, "y": 2}},
+			order{2, []string{"a", "new", "b", "c", "d"}, map[string]int{"y": 3, "z": 4}},
+			"ID: -1 +2\n" +
+				"Items[1]: +\"new\"\n" +
+				"Attrs[x]: -1\n" +
+				"Attrs[y]: -2 +3\n" +
+				"Attrs[z]: +4\n",
+		},
+		{[]string{"a", "b", "c"}, []string{"c", "a", "b"}, "[2]: moved to [0]\n"},
+		{[]string{"a", "b", "c"}, []string{"a", "x", "c"}, "[1]: -\"b\" +\"x\"\n"},
+		{[]*order{{ID: 1}}, []*order{{ID: 1}, nil}, "[1]: +nil\n"},
+		{[]chan int{nil}, []chan int{nil, nil}, "[1]: +nil\n"},
+		{
+			[]interface{}{time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), errors.New("a"), time.Time{}},
+			[]interface{}{time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), errors.New("b"), time.Time{}},
+			"[0]: -2024-01-02T00:00:00Z +2024-01-03T00:00:00Z\n" +
+				"[1]: -\"a\" +\"b\"\n",
+		},
+	}
+	for _, tt := range tests {
+		if got := Diff(tt.a, tt.b); got != tt.want {
+			t.Errorf("Diff(%v, %v): got\n%s\nwant\n%s", tt.a, tt.b, got, tt.want)
+		}
+	}
+	if got := Diff(struct{ F func() }{}, struct{ F func() }{func() {}}); !strings.HasPrefix(got, "F: -nil +(func())(0x") {
+		t.Errorf("nil func: got %q", got)
+	}
+}
+
+func TestDiffCycles(t *testing.T) {
+	a := map[string]interface{}{"v": 1}
+	a["self"] = a
+	b := map[string]interface{}{"v": 2}
+	b["self"] = b
+	if got, want := Diff(a, b), "[v]: -1 +2\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if !Equal(a, a) {
+		t.Error("map containing itself not equal to itself")
+	}
+}
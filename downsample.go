@@ -0,0 +1,100 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// DownsampleMode selects how numeric arrays and slices are downsampled.
+type DownsampleMode int
+
+const (
+	// EveryNth keeps evenly spaced elements, named by their index.
+	EveryNth DownsampleMode = iota + 1
+
+	// BucketMean splits the elements into evenly sized buckets and shows
+	// the mean of each, named by its index range.
+	BucketMean
+)
+
+// WithDownsample reduces arrays and slices of integers or floats with more
+// than points elements to points elements using mode, so dumps of long
+// series convey their shape without printing every sample.
+func WithDownsample(mode DownsampleMode, points int) Option {
+	return func(d *Dumper) {
+		d.downsampleMode = mode
+		d.downsamplePoints = points
+	}
+}
+
+func isNumeric(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
+
+// numeric returns the value of the integer or float val as a float64.
+func numeric(val reflect.Value) float64 {
+	switch {
+	case val.CanInt():
+		return float64(val.Int())
+	case val.CanUint():
+		return float64(val.Uint())
+	}
+	return val.Float()
+}
+
+// downsample dumps the elements of the numeric array or slice val reduced
+// to v.d.downsamplePoints elements, after annotating n. It reports false if
+// val does not need downsampling.
+func (v *variable) downsample(val reflect.Value, n *Node) bool {
+	l, points := val.Len(), v.d.downsamplePoints
+	if v.d.downsampleMode == 0 || points <= 0 || l <= points || !isNumeric(val.Type().Elem().Kind()) {
+		return false
+	}
+
+	d := int(v.indent) + 1
+	child := func(name, value string) {
+		c := &Node{
+			Depth:  d,
+			Indent: v.indentation(d),
+			Path:   n.Path + "[" + name + "]",
+			Name:   name,
+			Type:   typeName(val.Type().Elem()),
+			Kind:   val.Type().Elem().Kind(),
+			Leaf:   true,
+			Value:  value,
+		}
+		v.open(c)
+		v.close(c)
+	}
+
+	v.export(val, n)
+	switch v.d.downsampleMode {
+	case BucketMean:
+		n.annotate(fmt.Sprintf("downsampled %d -> %d (bucket mean)", l, points))
+		v.truncated(n.Path, TruncatedDownsample, l-points)
+		v.open(n)
+		for b := 0; b < points; b++ {
+			lo, hi := b*l/points, (b+1)*l/points
+			var sum float64
+			for i := lo; i < hi; i++ {
+				sum += numeric(val.Index(i))
+			}
+			mean := strconv.FormatFloat(sum/float64(hi-lo), 'g', -1, 64)
+			child(strconv.Itoa(lo)+"-"+strconv.Itoa(hi-1), mean)
+		}
+	default:
+		step := (l + points - 1) / points
+		n.annotate(fmt.Sprintf("downsampled %d -> %d (step %d)", l, (l+step-1)/step, step))
+		v.truncated(n.Path, TruncatedDownsample, l-(l+step-1)/step)
+		v.open(n)
+		for i := 0; i < l; i += step {
+			child(strconv.Itoa(i), leafValue(val.Index(i)))
+		}
+	}
+	return true
+}
@@ -0,0 +1,40 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import "testing"
+
+func TestDownsample(t *testing.T) {
+	v := []float64{1, 2, 3, 4, 5, 6, 7}
+	tests := []struct {
+		mode   DownsampleMode
+		points int
+		want   string
+	}{
+		{EveryNth, 4, "([]float64) downsampled 7 -> 4 (step 2)\n" +
+			"  0(float64) 1\n" +
+			"  2(float64) 3\n" +
+			"  4(float64) 5\n" +
+			"  6(float64) 7\n"},
+		{BucketMean, 3, "([]float64) downsampled 7 -> 3 (bucket mean)\n" +
+			"  0-1(float64) 1.5\n" +
+			"  2-3(float64) 3.5\n" +
+			"  4-6(float64) 6\n"},
+	}
+	for _, tt := range tests {
+		d := NewDumper(WithDownsample(tt.mode, tt.points))
+		if got := d.Sdump(v); got != tt.want {
+			t.Errorf("mode %d: got\n%s\nwant\n%s", tt.mode, got, tt.want)
+		}
+	}
+
+	d := NewDumper(WithDownsample(EveryNth, 3))
+	if got := d.Sdump([]int{1, 2, 3}); got != Sdump([]int{1, 2, 3}) {
+		t.Errorf("short slice downsampled: %q", got)
+	}
+	if got := d.Sdump([]string{"a", "b", "c", "d"}); got != Sdump([]string{"a", "b", "c", "d"}) {
+		t.Errorf("string slice downsampled: %q", got)
+	}
+}
@@ -5,89 +5,850 @@
 package godump
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
+	"io"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
 type variable struct {
-	// Output dump string
-	Out string
+	// Configuration of the dump
+	d *Dumper
+
+	// Output writer
+	w *bufio.Writer
+
+	// Renderer of the nodes
+	renderer Renderer
+
+	// Label of the dump
+	label string
+
+	// Correlation ID of the dump, if it has one
+	id string
+
+	// Optional writer receiving only the lines up to shallowDepth
+	shallow      *bufio.Writer
+	shallowDepth int64
+
+	// Depth at which values are elided; zero means unlimited
+	depthLimit int
+
+	// Part of the dump written, if only a chunk is requested
+	chunk *chunk
+
+	// Number of pointers followed to the current value
+	pointers int
+
+	// Lines of the values being cached
+	captures []*[]cachedLine
+
+	// Values cut short so far
+	truncations []Truncation
+
+	// Values masked so far
+	redactions []Redaction
+
+	// Values classified so far
+	classifications []Classification
+
+	// Pointers reachable more than once, by their number once dumped, and
+	// the number of them dumped so far
+	shared map[visit]int
+	refs   int
+
+	// Pointers and maps being dumped, and the number of cycles cut short
+	visiting map[visit]bool
+	cycles   int
+
+	// Whether the type of the next node is implied by its parent
+	typeImplied bool
+
+	// Whether the next node is an element of an array or slice
+	elem bool
+
+	// Whether the next node is the value pointed to by its parent
+	deref bool
+
+	// Whether the next node is an unexported embedded struct, or the value
+	// it points to, dumped for its promoted fields
+	promoted bool
+
+	// Note of the next node, if its map key is shortened
+	keyNote string
+
+	// Wire format of the next node, if it is a tagged struct field
+	wire *wireFormat
+
+	// Whether the next value is a field tagged to be masked, and the class
+	// it is tagged with
+	redactTag bool
+	classTag  string
+
+	// Line being built
+	line bytes.Buffer
+
+	// Indentations of the depths so far
+	indents []string
+
+	// Baseline the dump is marked against, if any
+	baseline *baseline
+
+	// Consumer of the lines of the dump, if they are iterated by Lines,
+	// the value the next line belongs to, and whether the consumer stopped
+	lines   func(Line) bool
+	at      Line
+	stopped bool
+
+	// Whether the budget ran out during the dump
+	exhausted bool
+
+	// Lines collected for WithPreview until the dump ends
+	preview *preview
+
+	// First error returned by the renderer
+	err error
+
+	// Running checksum of the output, if requested
+	sum hash.Hash
 
 	// Indent counter
 	indent int64
 }
 
-func (v *variable) dump(val reflect.Value, name string) {
+// begin writes the lines preceding the dump of the top-level value.
+func (v *variable) begin() {
+	if v.d.markers {
+		v.marker("BEGIN")
+	}
+	v.id = v.d.newCorrelationID()
+	if v.d.document() {
+		// Documents hold the ID themselves.
+		if r, ok := v.renderer.(*treeRenderer); ok {
+			r.id = v.id
+		} else if v.id != "" {
+			v.line.WriteString("#|id=" + v.id + "|# ")
+		}
+		return
+	}
+	if v.d.checksum {
+		v.sum = sha256.New()
+	}
+	if v.d.header {
+		v.line.WriteString(v.d.Header())
+		v.line.WriteByte('\n')
+		v.flushLine()
+	}
+	if v.d.sequence || v.d.timestamp || v.id != "" {
+		v.stamp(v.id)
+	}
+}
+
+var (
+	// Sequence number of the last stamped dump
+	dumpSeq uint64
+
+	// Clock of the timestamps, replaced by tests
+	now = time.Now
+)
+
+// stamp writes the line with the sequence number, time and correlation ID
+// of the dump.
+func (v *variable) stamp(id string) {
+	var fields []string
+	if v.d.sequence {
+		fields = append(fields, "seq="+strconv.FormatUint(atomic.AddUint64(&dumpSeq, 1), 10))
+	}
+	if v.d.timestamp {
+		fields = append(fields, "time="+now().Format(time.RFC3339Nano))
+	}
+	if id != "" {
+		fields = append(fields, "id="+id)
+	}
+	v.line.WriteString(strings.Join(fields, " "))
+	v.line.WriteByte('\n')
+	v.flushLine()
+}
+
+// end writes the lines following the dump of the top-level value.
+func (v *variable) end() {
+	v.flushPreview()
+	v.reportRedactions()
+	if v.d.classificationReport != nil && v.d.classify {
+		v.d.classificationReport(v.classifications)
+	}
+	if v.d.truncationReport != nil {
+		v.d.truncationReport(v.truncations)
+	}
+	if v.sum != nil {
+		sum := fmt.Sprintf("%s%x\n", checksumPrefix, v.sum.Sum(nil))
+		if v.lines != nil {
+			v.yieldLines([]byte(sum))
+		} else {
+			v.w.WriteString(sum)
+		}
+	}
+	if v.d.markers {
+		v.marker("END")
+	}
+}
+
+// marker writes a line delimiting the dump.
+func (v *variable) marker(what string) {
+	v.line.WriteString(markerPrefix + what + " dump")
+	if v.label != "" {
+		v.line.WriteString(" " + strconv.Quote(v.label))
+	}
+	v.line.WriteString(" =====\n")
+	v.flushLine()
+}
+
+const markerPrefix = "===== "
+
+const checksumPrefix = "godump-checksum sha256="
+
+// VerifyChecksum checks that dump, produced by a Dumper created
+// WithChecksum, ends with a checksum line matching the rest of its text.
+func VerifyChecksum(dump string) error {
+	body := strings.TrimSuffix(dump, "\n")
+	if strings.HasPrefix(body, markerPrefix+"BEGIN ") {
+		body = body[strings.IndexByte(body, '\n')+1:]
+	}
+	i := strings.LastIndexByte(body, '\n') + 1
+	if strings.HasPrefix(body[i:], markerPrefix+"END ") && i > 0 {
+		body = body[:i-1]
+		i = strings.LastIndexByte(body, '\n') + 1
+	}
+	if !strings.HasPrefix(body[i:], checksumPrefix) {
+		return errors.New("godump: checksum line missing")
+	}
+	sum := sha256.Sum256([]byte(body[:i]))
+	if body[i+len(checksumPrefix):] != hex.EncodeToString(sum[:]) {
+		return errors.New("godump: checksum mismatch")
+	}
+	return nil
+}
+
+func (v *variable) dump(val reflect.Value, name, path string) {
+	if v.chunk != nil && v.chunk.more || v.stopped || v.exhausted {
+		return
+	}
+	if v.d.budget != nil && !v.d.budget.spendNode() {
+		v.overBudget(path)
+		return
+	}
 	v.indent++
 
-	if val.IsValid() && val.CanInterface() {
+	var start time.Time
+	if v.d.trace != nil {
+		start = time.Now()
+		v.traceEnter(val, path)
+	}
+
+	n := &Node{
+		Depth:       int(v.indent),
+		Indent:      v.indentation(int(v.indent)),
+		Path:        path,
+		Name:        name,
+		TypeImplied: v.typeImplied,
+		Elem:        v.elem,
+		Note:        v.keyNote,
+	}
+	deref, promoted, wire, tagged, class := v.deref, v.promoted, v.wire, v.redactTag, v.classTag
+	v.typeImplied, v.elem, v.deref, v.promoted, v.keyNote, v.wire, v.redactTag, v.classTag = false, false, false, false, "", nil, false, ""
+	v.provenanceNote(n)
+	handler := "value"
+	val = v.accessible(val)
+	// Interfaces are dumped as their dynamic value, noted next to the
+	// interface type unless it is the empty interface.
+	var static reflect.Type
+	if val.Kind() == reflect.Interface && !val.IsNil() {
+		static = val.Type()
+		val = v.accessible(val.Elem())
+	}
+	if val.IsValid() && (val.CanInterface() || v.d.fields != ExportedOnly || promoted) {
 		typ := val.Type()
+		n.Kind = typ.Kind()
+		n.Type = typeString(val)
+		if static != nil && static.NumMethod() > 0 && !v.d.canonical {
+			n.Type = typeName(static) + " -> " + n.Type
+		}
+
+		if v.d.fields == AllWithUnsafe && !val.CanInterface() {
+			v.unaddressable(n, "unsafe access")
+		}
 
-		switch typ.Kind() {
-		case reflect.Array, reflect.Slice:
-			v.printType(name, val.Interface())
+		opaque, isOpaque := v.opaqueValue(val, path)
+		errText, isError := "", false
+		if v.d.errorText && !v.d.fullExpansion && !isOpaque {
+			errText, isError = errorText(v.receiver(val, errorType, n, deref))
+		}
+		if isError && v.d.verbosity >= 2 {
+			n.annotate(errText)
+		}
+		strText, stringer := v.stringerText(val, n, deref)
+		if stringer == StringerPlusFields && !isOpaque && !isError {
+			n.annotate(strText)
+		}
+
+		if v.d.mapSnapshot && typ.Kind() == reflect.Map {
+			val = snapshotMap(val, n)
+		}
+
+		rule, masked := v.redactRule(path, val)
+		if tagged {
+			rule, masked = tagRule, true
+		}
+		if v.d.classify {
+			v.classifyNode(n, val, rule, masked, class)
+		}
+		custom, isDumpable := dumpable(v.receiver(val, dumpableType, n, deref))
+
+		if k, ok := v.d.typeDepth[typ]; ok {
+			limit := n.Depth + k
+			if typ.Kind() == reflect.Ptr {
+				limit++
+			}
+			if v.depthLimit == 0 || limit < v.depthLimit {
+				defer func(saved int) { v.depthLimit = saved }(v.depthLimit)
+				v.depthLimit = limit
+			}
+		}
+
+		switch {
+		case masked:
+			handler = "redact"
+			v.redact(n, rule)
+		case isOpaque:
+			handler = "opaque"
+			n.Leaf = true
+			n.Value = opaque
+			v.open(n)
+		case v.d.handlers[typ] != nil:
+			handler = "handler"
+			v.handlerNode(v.d.handlers[typ], val, n)
+		case v.formattedNode(val, n):
+			handler = "formatter"
+		case v.zeroNode(val, n):
+			handler = "zero"
+		case isDumpable:
+			handler = "dumpable"
+			v.dumpableNode(custom, n)
+		case v.syncNode(val, n):
+			handler = "sync"
+		case isError && v.d.verbosity < 2:
+			handler = "error"
+			n.Leaf = true
+			n.Value = errText
+			v.open(n)
+		case stringer == StringerOnly && !isError:
+			handler = "stringer"
+			n.Leaf = true
+			n.Value = strText
+			v.open(n)
+		case (v.d.canonical || v.d.locale != nil) && v.timeNode(val, n):
+			handler = "time"
+		case v.d.wellKnown && v.wellKnownNode(val, n):
+			handler = "wellknown"
+		case v.d.binaryMarshaler && v.binaryNode(val, n, deref):
+			handler = "binary"
+		case isNil(val):
+			handler = "nil"
+			if typ.Kind() == reflect.Interface {
+				n.Type = typeName(typ)
+			}
+			n.Leaf = true
+			n.Value = "nil"
+			v.open(n)
+		case (v.d.byteDetection || v.d.hexdump) && isBytes(val):
+			handler = "bytes"
+			v.bytesNode(val, n, v.d.byteDetection)
+			if v.d.decode {
+				v.decoded(bytesOf(val, maxDecodedBytes), n, 0)
+			}
+		case typ.Kind() == reflect.Ptr && v.sharedRef(val, n):
+			handler = "ref"
+			v.open(n)
+		case (typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Map || typ.Kind() == reflect.Slice) && v.cycle(val):
+			handler = "cycle"
+			n.Leaf = true
+			n.Value = cycleNote(val)
+			v.open(n)
+		case typ.Kind() == reflect.Ptr && v.summarizePointer(val):
+			handler = "summary"
+			v.truncated(path, TruncatedPointer, 0)
+			n.Leaf = true
+			n.Value = v.pointerSummary(val)
+			v.open(n)
+		case typ.Kind() == reflect.Ptr && v.d.inlinePointers && v.primitivePointer(val, path):
+			handler = "inlineptr"
+			n.Leaf = true
+			n.Value = "&" + v.leafValue(val.Elem())
+			v.open(n)
+		case v.depthLimit > 0 && n.Depth >= v.depthLimit && hasChildren(val):
+			handler = "maxdepth"
+			v.truncated(path, TruncatedDepth, 0)
+			if id, ok := v.identify(val); ok {
+				n.Leaf = true
+				n.Value = id
+			}
+			n.Note = "...(max depth reached)"
+			v.open(n)
+		case v.d.kindHandler(typ.Kind()) != nil:
+			handler = "kind"
+			v.handlerNode(v.d.kindHandler(typ.Kind()), val, n)
+		case v.d.inlineWidth > 0 && isComposite(typ.Kind()) && v.inlineNode(val, n):
+			handler = "inline"
+		case (typ.Kind() == reflect.Array || typ.Kind() == reflect.Slice) && v.downsample(val, n):
+			handler = "downsample"
+		case typ.Kind() == reflect.Array || typ.Kind() == reflect.Slice:
+			handler = "array"
+			implied := false
+			if !v.d.elementTypes {
+				var elem string
+				elem, implied = elementType(val)
+				if implied && typ.Elem().Kind() == reflect.Interface {
+					n.annotate("elem=" + elem)
+				}
+			}
+			if v.d.maxElements > 0 && val.Len() > v.d.maxElements {
+				v.export(val, n)
+			}
+			v.open(n)
 			l := val.Len()
+			if typ.Kind() == reflect.Slice && l > 0 {
+				v.enter(val)
+			}
 			for i := 0; i < l; i++ {
-				v.dump(val.Index(i), strconv.Itoa(i))
-			}
-		case reflect.Map:
-			v.printType(name, val.Interface())
-			//l := val.Len()
-			keys := val.MapKeys()
-			for _, k := range keys {
-				v.dump(val.MapIndex(k), k.Interface().(string))
-			}
-		case reflect.Ptr:
-			v.printType(name, val.Interface())
-			v.dump(val.Elem(), name)
-		case reflect.Struct:
-			v.printType(name, val.Interface())
-			for i := 0; i < typ.NumField(); i++ {
+				if v.elideAt(i, l, path) {
+					break
+				}
+				v.typeImplied, v.elem = implied, true
+				name := v.elementName(val, i, path)
+				v.dump(val.Index(i), name, path+"["+name+"]")
+			}
+			if typ.Kind() == reflect.Slice && l > 0 {
+				v.leave(val)
+			}
+			if v.d.decode && isBytes(val) {
+				v.decoded(bytesOf(val, maxDecodedBytes), n, 0)
+			}
+		case typ.Kind() == reflect.Map:
+			handler = "map"
+			v.open(n)
+			l := val.Len()
+			keys, values, names := mapEntries(val, v.keyName)
+			v.enter(val)
+			for i, e := range values {
+				if v.elideAt(i, l, path) {
+					break
+				}
+				elemPath := path + "[" + names[i] + "]"
+				label := v.keyLabel(names[i], elemPath)
+				if v.d.format == "singleline" && isStringKey(keys[i]) {
+					// Single-line dumps are meant to read as Go.
+					label = strconv.Quote(label)
+				}
+				v.dump(e, label, elemPath)
+			}
+			v.leave(val)
+		case typ.Kind() == reflect.Ptr:
+			handler = "pointer"
+			v.numberPointer(val, n)
+			v.open(n)
+			v.pointers++
+			if !val.IsNil() {
+				v.enter(val)
+			}
+			v.promoted = promoted
+			v.cachedElem(val, n)
+			v.promoted = false
+			if !val.IsNil() {
+				v.leave(val)
+			}
+			v.pointers--
+		case typ.Kind() == reflect.Struct:
+			handler = "struct"
+			v.open(n)
+			order := v.fieldOrder(typ, path)
+			for j := 0; j < typ.NumField(); j++ {
+				i := j
+				if order != nil {
+					i = order[j]
+				}
 				field := typ.Field(i)
-				v.dump(val.FieldByIndex([]int{i}), field.Name)
+				fv, ok := v.structField(val, i)
+				if !ok || v.ignoredPath(joinPath(path, field.Name)) {
+					continue
+				}
+				v.promoted = v.d.fields == ExportedOnly && !field.IsExported()
+				v.wire, _ = wireFormatOf(field)
+				v.redactTag = !v.d.clearTags && redactTagged(field)
+				v.classTag = classTag(field)
+				v.dump(fv, v.fieldName(field), joinPath(path, field.Name))
 			}
 		default:
-			v.printValue(name, val.Interface())
+			n.Leaf = true
+			if typ.Kind() != reflect.String || !v.truncatedString(val, n) {
+				n.Value = v.leafValue(val)
+			}
+			if wire != nil {
+				v.annotateWire(val, n, wire)
+			}
+			v.open(n)
+			if v.d.decode && typ.Kind() == reflect.String {
+				v.decoded([]byte(val.String()), n, 0)
+			}
 		}
+		switch handler {
+		case "struct", "map", "array":
+			if v.d.describe {
+				v.described(v.receiver(val, describerType, n, false), n)
+			}
+		}
+	} else if val.IsValid() {
+		// Values obtained through unexported fields are not shown, but
+		// their type is.
+		handler = "unexported"
+		n.Leaf = true
+		n.Kind = val.Kind()
+		n.Type = typeString(val)
+		n.Value = "<unexported>"
+		v.open(n)
 	} else {
-		v.printValue(name, "")
+		handler = "invalid"
+		n.Leaf = true
+		n.Note = "<invalid>"
+		v.open(n)
+	}
+	v.close(n)
+
+	if v.d.trace != nil {
+		v.traceExit(path, handler, time.Since(start))
 	}
 
 	v.indent--
 }
 
-func (v *variable) printType(name string, vv interface{}) {
-	v.printIndent()
-	v.Out = fmt.Sprintf("%s%s(%T)\n", v.Out, name, vv)
+// leafValue returns the representation of the leaf val.
+func (v *variable) leafValue(val reflect.Value) string {
+	if v.d.funcNames && val.Kind() == reflect.Func {
+		return funcSummary(val)
+	}
+	if v.d.canonical {
+		return canonicalLeaf(val)
+	}
+	if v.d.locale != nil {
+		if s, ok := v.d.locale.leaf(val); ok {
+			return s
+		}
+	}
+	return leafValue(val)
+}
+
+// syncNode renders val as a leaf if it is a synchronization value.
+func (v *variable) syncNode(val reflect.Value, n *Node) bool {
+	s, ok := v.syncValue(val)
+	if !ok {
+		return false
+	}
+	n.Leaf = true
+	n.Value = s
+	v.open(n)
+	return true
+}
+
+// keyName returns the name of the map entry with key k.
+func (v *variable) keyName(k reflect.Value) string {
+	if v.d.canonical && k.Kind() != reflect.String {
+		return canonicalLeaf(k)
+	}
+	return keyString(k)
+}
+
+// timeNode renders n as a canonical or localized time if it is a time.Time.
+func (v *variable) timeNode(val reflect.Value, n *Node) bool {
+	var s string
+	var ok bool
+	if v.d.canonical {
+		s, ok = canonicalTime(val)
+	} else {
+		s, ok = v.d.locale.time(val)
+	}
+	if !ok {
+		return false
+	}
+	n.Leaf = true
+	n.Value = s
+	v.open(n)
+	return true
+}
+
+// binaryNode renders n as its marshaled bytes if it is a
+// encoding.BinaryMarshaler.
+func (v *variable) binaryNode(val reflect.Value, n *Node, deref bool) bool {
+	value, note, ok := marshalBinary(v.receiver(val, binaryMarshalerType, n, deref))
+	if !ok {
+		return false
+	}
+	n.Leaf = true
+	n.Value = value
+	n.Note = note
+	v.open(n)
+	return true
+}
+
+// inlineNode renders n on a single line if it fits.
+func (v *variable) inlineNode(val reflect.Value, n *Node) bool {
+	s, ok := v.tryInline(val, n)
+	if !ok {
+		return false
+	}
+	n.Leaf = true
+	n.Value = s
+	v.open(n)
+	return true
+}
+
+// elementType returns the type shared by all elements of the array or slice
+// val. For interface element types, this is the common dynamic type, if any.
+func elementType(val reflect.Value) (string, bool) {
+	et := val.Type().Elem()
+	if et.Kind() != reflect.Interface {
+		return typeName(et), true
+	}
+	var common reflect.Type
+	for i := 0; i < val.Len(); i++ {
+		e := val.Index(i)
+		if e.IsNil() {
+			return "", false
+		}
+		t := e.Elem().Type()
+		if common == nil {
+			common = t
+		} else if t != common {
+			return "", false
+		}
+	}
+	if common == nil {
+		return "", false
+	}
+	return typeName(common), true
+}
+
+// hasChildren reports whether the dump of val has nested nodes.
+func hasChildren(val reflect.Value) bool {
+	switch val.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map:
+		return val.Len() > 0
+	case reflect.Ptr:
+		return true
+	case reflect.Struct:
+		return val.NumField() > 0
+	}
+	return false
+}
+
+// isNil reports whether val is a nil pointer, map, slice or interface.
+func isNil(val reflect.Value) bool {
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return val.IsNil()
+	}
+	return false
+}
+
+var emptyInterfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+// nilInterface returns val, the value of an interface{} argument, or the
+// nil interface{} if the argument is nil, so it is dumped with its type.
+func nilInterface(val reflect.Value) reflect.Value {
+	if !val.IsValid() {
+		return reflect.Zero(emptyInterfaceType)
+	}
+	return val
+}
+
+// elideAt reports whether the element i of a collection of length l is over
+// the element limit. If it is the first one, a line summarizing the elided
+// elements is written in its place.
+func (v *variable) elideAt(i, l int, path string) bool {
+	if v.d.maxElements <= 0 || i < v.d.maxElements {
+		return false
+	}
+	v.truncated(path, TruncatedElements, l-i)
+	note := "... " + v.count(l-i) + " more elements"
+	if l-i == 1 {
+		note = "... 1 more element"
+	}
+	d := int(v.indent) + 1
+	n := &Node{
+		Depth:  d,
+		Indent: v.indentation(d),
+		Note:   note,
+		Leaf:   true,
+	}
+	v.open(n)
+	v.close(n)
+	return true
+}
+
+// joinPath appends a struct field name to path.
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// indentation returns the indentation of values at depth.
+func (v *variable) indentation(depth int) string {
+	for len(v.indents) <= depth {
+		indent := strings.Repeat(v.d.indentUnit, len(v.indents))
+		if v.baseline != nil {
+			// Column of the marks of DumpWithBaseline
+			indent = "  " + indent
+		}
+		v.indents = append(v.indents, indent)
+	}
+	return v.indents[depth]
 }
 
-func (v *variable) printValue(name string, vv interface{}) {
-	v.printIndent()
-	v.Out = fmt.Sprintf("%s%s(%T) %#v\n", v.Out, name, vv, vv)
+// open renders n, or the header of n if it has children.
+func (v *variable) open(n *Node) {
+	if v.baseline != nil {
+		v.baseline.mark(n)
+	}
+	if err := v.renderer.Open(&v.line, n); err != nil && v.err == nil {
+		v.err = err
+	}
+	v.flushNode(n)
 }
 
-func (v *variable) printIndent() {
-	var i int64
-	for i = 0; i < v.indent; i++ {
-		v.Out = fmt.Sprintf("%s  ", v.Out)
+// close finishes rendering n after its children were rendered.
+func (v *variable) close(n *Node) {
+	if v.baseline != nil {
+		v.baseline.removed(v, v.baseline.children(n))
 	}
+	if err := v.renderer.Close(&v.line, n); err != nil && v.err == nil {
+		v.err = err
+	}
+	v.flushNode(n)
+}
+
+// flushLine writes the finished line to the outputs it is routed to.
+func (v *variable) flushLine() {
+	if v.line.Len() == 0 {
+		return
+	}
+	line := v.line.Bytes()
+	if v.chunk != nil {
+		if line = v.chunk.keep(line); len(line) == 0 {
+			v.line.Reset()
+			return
+		}
+	}
+	if v.d.budget != nil {
+		v.d.budget.spendBytes(len(line))
+	}
+	for _, c := range v.captures {
+		*c = append(*c, cachedLine{v.indent, string(line)})
+	}
+	if len(v.d.postProcessors) > 0 {
+		line = v.postProcess(line)
+	}
+	if v.preview != nil {
+		v.preview.add(line, v.at)
+	} else {
+		v.emit(line, v.at)
+	}
+	v.line.Reset()
+}
+
+// emit writes the output chunk b, written for the value at, to the outputs.
+func (v *variable) emit(b []byte, at Line) {
+	if v.lines != nil {
+		v.at = at
+		v.yieldLines(b)
+		return
+	}
+	v.w.Write(b)
+	if v.sum != nil {
+		v.sum.Write(b)
+	}
+	if v.shallow != nil && v.indent <= v.shallowDepth {
+		v.shallow.Write(b)
+	}
+}
+
+func (v *variable) traceEnter(val reflect.Value, path string) {
+	kind := "invalid"
+	if val.IsValid() {
+		kind = val.Kind().String()
+	}
+	fmt.Fprintf(v.d.trace, "%s-> %s kind=%s\n", strings.Repeat("  ", int(v.indent)), tracePath(path), kind)
+}
+
+func (v *variable) traceExit(path, handler string, d time.Duration) {
+	fmt.Fprintf(v.d.trace, "%s<- %s handler=%s %v\n", strings.Repeat("  ", int(v.indent)), tracePath(path), handler, d)
+}
+
+func tracePath(path string) string {
+	if path == "" {
+		return "."
+	}
+	return path
 }
 
 // Print to standard out the value that is passed as the argument with indentation.
-// Pointers are dereferenced.
-func Dump(v interface{}) {
-	val := reflect.ValueOf(v)
-	dump := &variable{indent: -1}
-	dump.dump(val, "")
-	fmt.Printf("%s", dump.Out)
+// Pointers are dereferenced. Options adjust the default configuration for
+// this call only, e.g.
+//
+//	godump.Dump(v, godump.WithInline(80))
+func Dump(v interface{}, opts ...Option) {
+	std().With(opts...).Dump(v)
+}
+
+// DumpLabel is like Dump, but tags the dump with label when it is mirrored to
+// stream clients (see ListenAndStream).
+func DumpLabel(label string, v interface{}, opts ...Option) {
+	std().With(opts...).DumpLabel(label, v)
 }
 
 // Return the value that is passed as the argument with indentation.
-// Pointers are dereferenced.
-func Sdump(v interface{}) string {
-	val := reflect.ValueOf(v)
-	dump := &variable{indent: -1}
-	dump.dump(val, "")
-	return dump.Out
+// Pointers are dereferenced. Options are applied as by Dump.
+func Sdump(v interface{}, opts ...Option) string {
+	return std().With(opts...).Sdump(v)
+}
+
+// Fdump writes the value that is passed as the argument with indentation to
+// w, e.g. a log file or an HTTP response, without building the whole dump in
+// memory. Options are applied as by Dump.
+func Fdump(w io.Writer, v interface{}, opts ...Option) error {
+	return std().With(opts...).Fdump(w, v)
+}
+
+// DumpSplit writes the full dump of v to full and, in the same pass, only the
+// lines describing values nested at most depth levels deep to shallow. The
+// top-level value is at depth 0. This way a log can get a summary while the
+// complete dump goes elsewhere, e.g. to a file.
+func DumpSplit(shallow io.Writer, depth int, full io.Writer, v interface{}, opts ...Option) error {
+	return std().With(opts...).DumpSplit(shallow, depth, full, v)
+}
+
+// DumpToFile writes the dump of v to the file at path, creating or
+// truncating it. The output is streamed to disk, so values whose dump does
+// not fit in memory can be captured. If path ends in ".gz", the file is
+// gzip compressed.
+func DumpToFile(path string, v interface{}, opts ...Option) error {
+	return std().With(opts...).DumpToFile(path, v)
 }
@@ -5,8 +5,16 @@
 package godump
 
 import (
+	"bytes"
+	"compress/gzip"
 	"go/parser"
 	"go/token"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
 	"testing"
 )
 
@@ -50,3 +58,154 @@ func TestDump(t *testing.T) {
 	}
 	Dump(file)
 }
+
+func TestFdump(t *testing.T) {
+	var b bytes.Buffer
+	v := map[string]int{"satu": 1}
+	if err := Fdump(&b, v, WithHeader(true)); err != nil {
+		t.Fatal(err)
+	}
+	if want := Sdump(v, WithHeader(true)); b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestDumpToFile(t *testing.T) {
+	v := []int{1, 2, 3}
+	dir := t.TempDir()
+
+	plain := filepath.Join(dir, "dump.txt")
+	if err := DumpToFile(plain, v); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != Sdump(v) {
+		t.Errorf("plain file: %q", b)
+	}
+
+	compressed := filepath.Join(dir, "dump.txt.gz")
+	if err := DumpToFile(compressed, v); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err = io.ReadAll(zr); err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != Sdump(v) {
+		t.Errorf("compressed file: %q", b)
+	}
+}
+
+func TestDumpSplit(t *testing.T) {
+	v := [][]int{{1}, {2, 3}}
+	var shallow, full bytes.Buffer
+	if err := DumpSplit(&shallow, 1, &full, v); err != nil {
+		t.Fatal(err)
+	}
+	if full.String() != Sdump(v) {
+		t.Errorf("full: %q", full.String())
+	}
+	want := "([][]int)\n  0([]int)\n  1([]int)\n"
+	if shallow.String() != want {
+		t.Errorf("shallow: got %q, want %q", shallow.String(), want)
+	}
+}
+
+// BenchmarkFdump dumps slices of growing length; the time per element
+// stays the same as the output grows.
+func BenchmarkFdump(b *testing.B) {
+	for _, n := range []int{1e3, 1e4, 1e5} {
+		v := make([]S, n)
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Fdump(io.Discard, v)
+			}
+		})
+	}
+}
+
+func TestNil(t *testing.T) {
+	v := struct {
+		P *S
+		M map[string]int
+		L []int
+		E []int
+		R io.Reader
+		F func()
+		C chan int
+	}{E: []int{}}
+	want := "(struct { P *godump.S; M map[string]int; L []int; E []int; R io.Reader; F func(); C chan int })\n" +
+		"  P(*godump.S) nil\n" +
+		"  M(map[string]int) nil\n" +
+		"  L([]int) nil\n" +
+		"  E([]int)\n" +
+		"  R(io.Reader) nil\n" +
+		"  F(func()) nil\n" +
+		"  C(chan int) nil\n"
+	if got := Sdump(v); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got, want := Sdump(nil), "(interface {}) nil\n"; got != want {
+		t.Errorf("nil: got %q, want %q", got, want)
+	}
+	if got, want := Sdump(v, WithInline(200)), "("+typeName(reflect.TypeOf(v))+") {P:nil, M:nil, L:nil, E:{}, R:nil, F:nil, C:nil}\n"; got != want {
+		t.Errorf("inline: got %q, want %q", got, want)
+	}
+}
+
+type shape interface{ Area() int }
+
+type rect struct{ W, H int }
+
+func (r rect) Area() int { return r.W * r.H }
+
+func TestInterfaces(t *testing.T) {
+	v := struct {
+		Shape  shape
+		Shapes []shape
+		Any    interface{}
+		Typed  shape
+	}{rect{2, 3}, []shape{&rect{1, 1}}, 7, (*rect)(nil)}
+	want := "(struct { Shape godump.shape; Shapes []godump.shape; Any interface {}; Typed godump.shape })\n" +
+		"  Shape(godump.shape -> godump.rect)\n" +
+		"    W(int) 2\n" +
+		"    H(int) 3\n" +
+		"  Shapes([]godump.shape)\n" +
+		"    0(godump.shape -> *godump.rect)\n" +
+		"      0(godump.rect)\n" +
+		"        W(int) 1\n" +
+		"        H(int) 1\n" +
+		"  Any(int) 7\n" +
+		"  Typed(godump.shape -> *godump.rect) nil\n"
+	if got := Sdump(v); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestNaNKeys(t *testing.T) {
+	m := map[float64]int{math.NaN(): 1, 2: 3}
+	want := "(map[float64]int)\n" +
+		"  2(int) 3\n" +
+		"  NaN(int) 1\n"
+	if got := Sdump(m); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got, want := Sdump(m, WithInline(80)), "(map[float64]int) {2:3, NaN:1}\n"; got != want {
+		t.Errorf("inline: got %q, want %q", got, want)
+	}
+	if got := Diff(m, m); got != "" {
+		t.Errorf("Diff got %q", got)
+	}
+}
@@ -0,0 +1,786 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"maps"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Dumper dumps values according to its configuration. The package-level
+// functions use a Dumper with the default configuration.
+type Dumper struct {
+	renderer Renderer
+	trace    io.Writer
+
+	// Renders the whole tree of nodes instead of renderer, if set
+	treeRender func(d *Dumper, w io.Writer, root *tree) error
+
+	// Destination of Dump and friends without sinks; nil for standard out
+	out io.Writer
+
+	// Indentation of one level
+	indentUnit string
+
+	// Limits; zero means unlimited
+	maxDepth     int
+	maxElements  int
+	maxStringLen int
+	maxKeyLen    int
+
+	// Size of dumps cut down to a preview of previewLines lines at either
+	// end; zero for none
+	previewBytes, previewLines int
+
+	// Depth limits of the values of specific types, relative to them
+	typeDepth map[reflect.Type]int
+
+	// Renderers of specific types and kinds
+	handlers     map[reflect.Type]Handler
+	kindHandlers map[reflect.Kind]Handler
+
+	// Formatters of the values of given types, consulted before the
+	// registered ones
+	formatters map[reflect.Type]Formatter
+
+	// Copy maps before rendering them
+	mapSnapshot bool
+
+	// Pointers followed from the top-level value; negative for all
+	followPointers  int
+	pointerPolicies map[reflect.Type]PointerPolicy
+
+	// Identity of summarized values
+	identity func(reflect.Value) (string, bool)
+
+	// Fail dumps when features cannot be applied to unaddressable values
+	strict bool
+
+	// Output of values reached through pointers
+	cache    *Cache
+	cacheGen uint64
+
+	// Values shown instead of the whole value; filterErr if the query is
+	// invalid
+	filter    *Query
+	filterErr error
+
+	// Functions adjusting the output lines
+	postProcessors []func(string) string
+
+	// Dump the state exposed by Describe methods
+	describe bool
+
+	// Types never looked into
+	opaque map[reflect.Type]bool
+
+	// Dump the values of runtime-internal types in detail
+	internals bool
+
+	// Struct fields to dump, leaving out those with ignored names or paths
+	fields        FieldMode
+	ignoredFields map[string]bool
+	ignoredPaths  []string
+
+	// Name fields by their json tag
+	jsonNames bool
+
+	// Field naming the elements of arrays and slices of structs
+	keyField string
+
+	// Annotate elements of homogeneous arrays and slices with their type
+	elementTypes bool
+
+	// Render errors by their message
+	errorText bool
+
+	// Ignore the Error and String methods
+	fullExpansion bool
+
+	// Render funcs by name, leaving out nil func fields if omitNilFuncs
+	funcNames    bool
+	omitNilFuncs bool
+
+	// Use of the String method of fmt.Stringers, overridden by type
+	stringers        StringerPolicy
+	stringerPolicies map[reflect.Type]StringerPolicy
+
+	// Level of detail
+	verbosity int
+
+	// Render encoding.BinaryMarshaler values as bytes
+	binaryMarshaler bool
+
+	// Render bytes as text or hex dump
+	byteDetection bool
+
+	// Render bytes as hex dump, truncated after maxBytes unless zero
+	hexdump  bool
+	maxBytes int
+
+	// Destinations of Dump and friends
+	sinks []route
+
+	// Canonical output
+	canonical bool
+
+	// Conventions for numbers and dates; nil for Go syntax
+	locale *locale
+
+	// Redaction of sensitive values
+	redactRules      []RedactRule
+	redactionReport  func([]Redaction)
+	redactionSummary bool
+
+	// Leave fields tagged dump:"redact" in the clear, in the dumps that
+	// tell values apart in comparisons and are never written
+	clearTags bool
+
+	// Sources of the values, by path
+	provenance *Provenance
+
+	// Classification of values, by classifiers and the rules masking them
+	classify             bool
+	classifiers          []Classifier
+	ruleClasses          map[string]string
+	classificationReport func([]Classification)
+
+	// Paths of the values ignored by a Harness
+	ignored func(path string) bool
+
+	// Output shared with other dumps
+	budget *Budget
+
+	// Report of the values cut short
+	truncationReport func([]Truncation)
+
+	// Intern the strings of the JSON tree
+	stringTable bool
+
+	// Decode base64 and gzip content
+	decode bool
+
+	// Files receiving the numeric data cut short
+	exportDir    string
+	exportFormat ExportFormat
+
+	// Downsampling of numeric arrays and slices
+	downsampleMode   DownsampleMode
+	downsamplePoints int
+
+	// Maximum width of inlined composite values; zero disables inlining
+	inlineWidth int
+
+	// Render pointers to primitive values on the line of the pointer
+	inlinePointers bool
+
+	// Render well-known types like time.Time compactly
+	wellKnown bool
+
+	// Render the zero values of zeroTypes as <zero T>
+	zeroElision bool
+	zeroTypes   map[reflect.Type]bool
+
+	// Name of the style set by WithStyle, and of the output format
+	// replacing the default grammar, like json
+	style  string
+	format string
+
+	// Whether the default grammar is rendered in color
+	color colorMode
+
+	// Changes of the value watched by WatchRecent, dumped first
+	recency *recency
+
+	header   bool
+	checksum bool
+	markers  bool
+
+	// Stamp dumps with the time and a sequence number
+	timestamp bool
+	sequence  bool
+
+	// Annotate pointers with their addresses, and dump shared ones once
+	addresses      bool
+	sharedPointers bool
+
+	// Stamp dumps with this ID, or with IDs of their own
+	correlationID  string
+	correlationIDs bool
+}
+
+// FormatVersion identifies the grammar of the dump output. It is increased
+// whenever the output of an unchanged configuration changes.
+const FormatVersion = 21
+
+// Option configures a Dumper.
+type Option func(*Dumper)
+
+// The Dumper used by the package-level functions.
+var defaultDumper atomic.Value
+
+func init() {
+	defaultDumper.Store(NewDumper())
+}
+
+// std returns the Dumper used by the package-level functions.
+func std() *Dumper {
+	return defaultDumper.Load().(*Dumper)
+}
+
+// Default returns the Dumper used by the package-level functions.
+func Default() *Dumper {
+	return std()
+}
+
+// SetDefault makes the package-level functions use d from now on. Dumps in
+// progress finish with the previous Dumper.
+func SetDefault(d *Dumper) {
+	defaultDumper.Store(d)
+}
+
+// NewDumper returns a Dumper configured by opts.
+func NewDumper(opts ...Option) *Dumper {
+	d := &Dumper{
+		renderer:       textRenderer{},
+		indentUnit:     "  ",
+		errorText:      true,
+		elementTypes:   true,
+		verbosity:      1,
+		followPointers: -1,
+		describe:       true,
+		hexdump:        true,
+		maxBytes:       maxBytes,
+		wellKnown:      true,
+		zeroElision:    true,
+	}
+	d.zeroTypes = make(map[reflect.Type]bool)
+	for _, t := range zeroTypes {
+		d.zeroTypes[t] = true
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// With returns a Dumper configured like d with opts applied on top. It
+// returns d itself if there are no opts, and never modifies d.
+func (d *Dumper) With(opts ...Option) *Dumper {
+	if len(opts) == 0 {
+		return d
+	}
+	c := *d
+	c.typeDepth = maps.Clone(d.typeDepth)
+	c.handlers = maps.Clone(d.handlers)
+	c.kindHandlers = maps.Clone(d.kindHandlers)
+	c.formatters = maps.Clone(d.formatters)
+	c.zeroTypes = maps.Clone(d.zeroTypes)
+	c.opaque = maps.Clone(d.opaque)
+	c.ignoredFields = maps.Clone(d.ignoredFields)
+	c.pointerPolicies = maps.Clone(d.pointerPolicies)
+	c.stringerPolicies = maps.Clone(d.stringerPolicies)
+	c.sinks = d.sinks[:len(d.sinks):len(d.sinks)]
+	c.redactRules = d.redactRules[:len(d.redactRules):len(d.redactRules)]
+	c.classifiers = d.classifiers[:len(d.classifiers):len(d.classifiers)]
+	c.ruleClasses = maps.Clone(d.ruleClasses)
+	c.ignoredPaths = d.ignoredPaths[:len(d.ignoredPaths):len(d.ignoredPaths)]
+	c.postProcessors = d.postProcessors[:len(d.postProcessors):len(d.postProcessors)]
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return &c
+}
+
+// WithElementTypes controls whether the elements of arrays and slices whose
+// elements all have the same concrete type are annotated with it. When
+// disabled, the type is only given by the header of the array or slice;
+// interface typed ones note the common dynamic type as elem=T.
+func WithElementTypes(show bool) Option {
+	return func(d *Dumper) {
+		d.elementTypes = show
+	}
+}
+
+// WithIndent indents nested values by width spaces per level instead of
+// two.
+func WithIndent(width int) Option {
+	return func(d *Dumper) {
+		if width < 0 {
+			width = 0
+		}
+		d.indentUnit = strings.Repeat(" ", width)
+	}
+}
+
+// WithMaxDepth elides the children of values nested n levels deep, the
+// top-level value being at level 0, noting ...(max depth reached) in their
+// place. Zero means unlimited; this is the default.
+func WithMaxDepth(n int) Option {
+	return func(d *Dumper) {
+		d.maxDepth = n
+	}
+}
+
+// WithMaxElements dumps at most n elements of arrays, slices and maps,
+// followed by a line counting the elements left out. Zero means unlimited;
+// this is the default.
+func WithMaxElements(n int) Option {
+	return func(d *Dumper) {
+		d.maxElements = n
+	}
+}
+
+// WithMaxStringLen truncates strings after n bytes, on a character
+// boundary, noting their length:
+//
+//	Body(string) "lorem ipsum" (12,345 bytes, truncated)
+//
+// Zero means unlimited; this is the default.
+func WithMaxStringLen(n int) Option {
+	return func(d *Dumper) {
+		d.maxStringLen = n
+	}
+}
+
+// WithWriter makes Dump, DumpLabel and DumpSeverity write to w instead of
+// standard out. Sinks still take precedence.
+func WithWriter(w io.Writer) Option {
+	return func(d *Dumper) {
+		d.out = w
+	}
+}
+
+// output returns the writer Dump and friends write to without sinks.
+func (d *Dumper) output() io.Writer {
+	if d.out == nil {
+		return os.Stdout
+	}
+	return d.out
+}
+
+// WithTypeDepth limits the dumps of values of type t to n levels below
+// them, wherever they are found, so heavyweight types like *sql.DB are
+// summarized even inside otherwise complete dumps. For pointer types, the
+// levels are counted from the value pointed to.
+func WithTypeDepth(t reflect.Type, n int) Option {
+	return func(d *Dumper) {
+		if d.typeDepth == nil {
+			d.typeDepth = make(map[reflect.Type]int)
+		}
+		d.typeDepth[t] = n
+	}
+}
+
+// WithTrace makes the Dumper log to w when it enters and leaves every node,
+// along with the kind of the value, the handler that rendered it and the
+// time spent. It helps finding out why a value is not rendered as expected.
+func WithTrace(w io.Writer) Option {
+	return func(d *Dumper) {
+		d.trace = w
+	}
+}
+
+// WithHeader makes every dump start with a line identifying the format
+// version and the options affecting the output, e.g.
+//
+//	godump/1 opts=header
+//
+// so tools parsing dumps can detect output they do not understand. The
+// header is left out of documents like those of WithJSON, WithSExpr,
+// WithTOML and WithSingleLine, which it would invalidate, as are the lines
+// of WithChecksum, WithTimestamp, WithSequence and WithCorrelationID.
+func WithHeader(header bool) Option {
+	return func(d *Dumper) {
+		d.header = header
+	}
+}
+
+// WithChecksum makes every dump end with a line holding the SHA-256 checksum
+// of the preceding output, so truncated or mangled dumps can be detected
+// with VerifyChecksum. With DumpSplit, only the full output gets the line.
+func WithChecksum(checksum bool) Option {
+	return func(d *Dumper) {
+		d.checksum = checksum
+	}
+}
+
+// WithMarkers wraps every dump in lines like
+//
+//	===== BEGIN dump "cache state" =====
+//	===== END dump "cache state" =====
+//
+// carrying the label of the dump, so dumps can be extracted from interleaved
+// logs with simple text tools.
+func WithMarkers(markers bool) Option {
+	return func(d *Dumper) {
+		d.markers = markers
+	}
+}
+
+// WithTimestamp makes every dump start with the wall-clock time it was made
+// at, in RFC 3339 format with nanoseconds.
+func WithTimestamp(timestamp bool) Option {
+	return func(d *Dumper) {
+		d.timestamp = timestamp
+	}
+}
+
+// WithSequence makes every dump start with a sequence number. The numbers
+// increase monotonically across all Dumpers of the process, so dumps made
+// by different goroutines can be ordered.
+func WithSequence(sequence bool) Option {
+	return func(d *Dumper) {
+		d.sequence = sequence
+	}
+}
+
+// document reports whether dumps are rendered as documents of another
+// format, which the lines around dumps of the default grammar would
+// invalidate.
+func (d *Dumper) document() bool {
+	switch d.format {
+	case "json", "sexpr", "toml", "singleline":
+		return true
+	}
+	return false
+}
+
+// Header returns the header line written by a Dumper created WithHeader,
+// without the trailing newline.
+func (d *Dumper) Header() string {
+	var opts []string
+	if d.addresses {
+		opts = append(opts, "addresses")
+	}
+	if d.binaryMarshaler {
+		opts = append(opts, "binary")
+	}
+	if d.budget != nil {
+		opts = append(opts, "budget")
+	}
+	if d.byteDetection {
+		opts = append(opts, "bytes=detect")
+	}
+	if d.canonical {
+		opts = append(opts, "canonical")
+	}
+	if d.checksum {
+		opts = append(opts, "checksum")
+	}
+	if d.classify {
+		opts = append(opts, "classify")
+	}
+	if d.color == colorOn {
+		opts = append(opts, "color")
+	}
+	if d.correlationID != "" || d.correlationIDs {
+		opts = append(opts, "correlation")
+	}
+	if d.decode {
+		opts = append(opts, "decode")
+	}
+	if !d.describe {
+		opts = append(opts, "describe=false")
+	}
+	switch d.downsampleMode {
+	case EveryNth:
+		opts = append(opts, "downsample=nth:"+strconv.Itoa(d.downsamplePoints))
+	case BucketMean:
+		opts = append(opts, "downsample=mean:"+strconv.Itoa(d.downsamplePoints))
+	}
+	if !d.elementTypes {
+		opts = append(opts, "elemtypes=false")
+	}
+	if !d.errorText {
+		opts = append(opts, "errortext=false")
+	}
+	if d.fullExpansion {
+		opts = append(opts, "expand")
+	}
+	if d.exportDir != "" {
+		if d.exportFormat == NPY {
+			opts = append(opts, "export=npy")
+		} else {
+			opts = append(opts, "export=csv")
+		}
+	}
+	switch d.fields {
+	case AllFields:
+		opts = append(opts, "fields=all")
+	case AllWithUnsafe:
+		opts = append(opts, "fields=unsafe")
+	}
+	if d.filter != nil || d.filterErr != nil {
+		opts = append(opts, "filter")
+	}
+	if d.hasFormatters() {
+		opts = append(opts, "formatters")
+	}
+	if d.funcNames {
+		opts = append(opts, "funcnames")
+	}
+	if d.hasHandlers() {
+		opts = append(opts, "handlers")
+	}
+	if d.followPointers >= 0 {
+		opts = append(opts, "follow="+strconv.Itoa(d.followPointers))
+	}
+	if d.header {
+		opts = append(opts, "header")
+	}
+	if len(d.ignoredFields) > 0 {
+		opts = append(opts, "ignorefields")
+	}
+	if len(d.ignoredPaths) > 0 {
+		opts = append(opts, "ignorepaths")
+	}
+	if !d.hexdump {
+		opts = append(opts, "hexdump=false")
+	}
+	if d.jsonNames {
+		opts = append(opts, "jsonnames")
+	}
+	if d.keyField != "" {
+		opts = append(opts, "keyfield="+d.keyField)
+	}
+	if d.maxKeyLen > 0 {
+		opts = append(opts, "keylen="+strconv.Itoa(d.maxKeyLen))
+	}
+	if d.locale != nil {
+		opts = append(opts, "locale="+d.locale.tag)
+	}
+	if d.markers {
+		opts = append(opts, "markers")
+	}
+	if d.maxDepth > 0 {
+		opts = append(opts, "maxdepth="+strconv.Itoa(d.maxDepth))
+	}
+	if d.maxBytes != maxBytes {
+		opts = append(opts, "maxbytes="+strconv.Itoa(d.maxBytes))
+	}
+	if d.maxElements > 0 {
+		opts = append(opts, "maxelements="+strconv.Itoa(d.maxElements))
+	}
+	if d.maxStringLen > 0 {
+		opts = append(opts, "maxstring="+strconv.Itoa(d.maxStringLen))
+	}
+	if d.omitNilFuncs {
+		opts = append(opts, "omitnilfuncs")
+	}
+	if len(d.opaque) > 0 {
+		opts = append(opts, "opaque")
+	}
+	if len(d.postProcessors) > 0 {
+		opts = append(opts, "postprocess")
+	}
+	if d.previewBytes > 0 {
+		opts = append(opts, "preview="+strconv.Itoa(d.previewBytes)+":"+strconv.Itoa(d.previewLines))
+	}
+	if d.provenance != nil {
+		opts = append(opts, "provenance")
+	}
+	if len(d.redactRules) > 0 {
+		opts = append(opts, "redact")
+	}
+	if len(d.pointerPolicies) > 0 {
+		opts = append(opts, "pointers")
+	}
+	if d.sequence {
+		opts = append(opts, "sequence")
+	}
+	if d.sharedPointers {
+		opts = append(opts, "shared")
+	}
+	if d.strict {
+		opts = append(opts, "strict")
+	}
+	switch d.stringers {
+	case StringerOnly:
+		opts = append(opts, "stringers=only")
+	case StringerPlusFields:
+		opts = append(opts, "stringers=fields")
+	}
+	if len(d.stringerPolicies) > 0 {
+		opts = append(opts, "stringertypes")
+	}
+	if d.stringTable {
+		opts = append(opts, "stringtable")
+	}
+	if d.timestamp {
+		opts = append(opts, "timestamp")
+	}
+	if !d.wellKnown {
+		opts = append(opts, "wellknown=false")
+	}
+	if !d.zeroElision {
+		opts = append(opts, "zero=false")
+	} else if len(d.zeroTypes) > len(zeroTypes) {
+		opts = append(opts, "zerotypes")
+	}
+	if d.style != "" && d.style != StyleDefault.name {
+		opts = append(opts, "style="+d.style)
+	}
+	if d.format != "" {
+		opts = append(opts, d.format)
+	}
+	if d.identity != nil {
+		opts = append(opts, "identity")
+	}
+	if d.indentUnit != "  " {
+		opts = append(opts, "indent="+strconv.Itoa(len(d.indentUnit)))
+	}
+	if d.internals {
+		opts = append(opts, "internals")
+	}
+	if d.inlineWidth > 0 {
+		opts = append(opts, "inline="+strconv.Itoa(d.inlineWidth))
+	}
+	if d.inlinePointers {
+		opts = append(opts, "inlineptr")
+	}
+	if len(d.typeDepth) > 0 {
+		opts = append(opts, "typedepth")
+	}
+	if d.verbosity != 1 {
+		opts = append(opts, "verbosity="+strconv.Itoa(d.verbosity))
+	}
+	h := "godump/" + strconv.Itoa(FormatVersion)
+	if len(opts) > 0 {
+		h += " opts=" + strings.Join(opts, ",")
+	}
+	return h
+}
+
+// Dump prints the dump of v to standard out.
+func (d *Dumper) Dump(v interface{}) {
+	d.DumpLabel("", v)
+}
+
+// DumpLabel is like Dump, but tags the dump with label when it is mirrored to
+// stream clients.
+func (d *Dumper) DumpLabel(label string, v interface{}) {
+	d.DumpSeverity(Debug, label, v)
+}
+
+// Sdump returns the dump of v.
+func (d *Dumper) Sdump(v interface{}) string {
+	return d.sdump("", v)
+}
+
+func (d *Dumper) sdump(label string, v interface{}) string {
+	out, _ := d.sdumpID(label, v)
+	return out
+}
+
+// sdumpID returns the dump of v and its correlation ID, if it has one.
+func (d *Dumper) sdumpID(label string, v interface{}) (out, id string) {
+	var b strings.Builder
+	dump := d.newVariable(&b, label)
+	dump.begin()
+	dump.root(reflect.ValueOf(v))
+	dump.end()
+	dump.w.Flush()
+	return b.String(), dump.id
+}
+
+// Fdump writes the dump of v to w. The output is written as it is
+// produced, without building it in memory first.
+func (d *Dumper) Fdump(w io.Writer, v interface{}) error {
+	return d.fdump(w, "", v)
+}
+
+// DumpSplit is the Dumper version of the package-level DumpSplit.
+func (d *Dumper) DumpSplit(shallow io.Writer, depth int, full io.Writer, v interface{}) error {
+	dump := d.newVariable(full, "")
+	dump.shallow = bufio.NewWriter(shallow)
+	dump.shallowDepth = int64(depth)
+	dump.begin()
+	dump.root(reflect.ValueOf(v))
+	dump.end()
+	err := dump.w.Flush()
+	if serr := dump.shallow.Flush(); err == nil {
+		err = serr
+	}
+	if dump.err != nil {
+		err = dump.err
+	}
+	return err
+}
+
+// DumpToFile is the Dumper version of the package-level DumpToFile.
+func (d *Dumper) DumpToFile(path string, v interface{}) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	var w io.WriteCloser = f
+	if strings.HasSuffix(path, ".gz") {
+		w = gzip.NewWriter(f)
+	}
+
+	err = d.fdump(w, "", v)
+	if w != f {
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// newVariable returns the state of a dump with label written to w.
+func (d *Dumper) newVariable(w io.Writer, label string) *variable {
+	v := d.bufferedVariable(bufio.NewWriter(w), label)
+	if d.color == colorAuto && isTerminal(w) {
+		v.colorize()
+	}
+	return v
+}
+
+// bufferedVariable is like newVariable, writing to w as is.
+func (d *Dumper) bufferedVariable(w *bufio.Writer, label string) *variable {
+	r := d.renderer
+	if d.treeRender != nil {
+		r = &treeRenderer{d: d, render: d.treeRender}
+	}
+	v := &variable{
+		d:          d,
+		w:          w,
+		renderer:   r,
+		label:      label,
+		depthLimit: d.budget.depthLimit(d.maxDepth),
+		indent:     -1,
+	}
+	if d.previewBytes > 0 {
+		v.preview = &preview{maxBytes: d.previewBytes, lines: d.previewLines}
+	}
+	if d.color == colorOn {
+		v.colorize()
+	}
+	return v
+}
+
+// fdump writes the dump of v to w as it is produced, without holding the
+// whole output in memory.
+func (d *Dumper) fdump(w io.Writer, label string, v interface{}) error {
+	dump := d.newVariable(w, label)
+	dump.begin()
+	dump.root(reflect.ValueOf(v))
+	dump.end()
+	if err := dump.w.Flush(); err != nil {
+		return err
+	}
+	return dump.err
+}
@@ -0,0 +1,243 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestTrace(t *testing.T) {
+	var trace strings.Builder
+	d := NewDumper(WithTrace(&trace))
+	if got := d.Sdump(S{1, 2}); got != Sdump(S{1, 2}) {
+		t.Errorf("traced dump differs: %q", got)
+	}
+
+	want := []string{
+		`-> . kind=struct`,
+		`  -> A kind=int`,
+		`  <- A handler=value \S+`,
+		`  -> B kind=int`,
+		`  <- B handler=value \S+`,
+		`<- . handler=struct \S+`,
+	}
+	lines := strings.Split(strings.TrimSuffix(trace.String(), "\n"), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("got trace\n%s", trace.String())
+	}
+	for i, line := range lines {
+		if !regexp.MustCompile("^" + want[i] + "$").MatchString(line) {
+			t.Errorf("line %d: got %q, want %q", i, line, want[i])
+		}
+	}
+}
+
+func TestHeader(t *testing.T) {
+	d := NewDumper(WithHeader(true))
+	want := "godump/" + strconv.Itoa(FormatVersion) + " opts=header\n(int) 1\n"
+	if got := d.Sdump(1); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Documents of other formats are left alone but noted in the header.
+	tests := []struct {
+		opt    Option
+		format string
+	}{
+		{WithJSON(true), "json"},
+		{WithSExpr(true), "sexpr"},
+		{WithTOML(true), "toml"},
+		{WithSingleLine(true), "singleline"},
+	}
+	for _, tt := range tests {
+		d := NewDumper(tt.opt, WithHeader(true), WithChecksum(true), WithSequence(true))
+		if got, want := d.Sdump(S{1, 2}), NewDumper(tt.opt).Sdump(S{1, 2}); got != want {
+			t.Errorf("%s: got %q, want %q", tt.format, got, want)
+		}
+		_, opts, _ := strings.Cut(d.Header(), "opts=")
+		if h := d.Header(); !slices.Contains(strings.Split(opts, ","), tt.format) {
+			t.Errorf("%s: header %q", tt.format, h)
+		}
+	}
+	tmpl := template.Must(template.New("").Parse("{{.Name}}"))
+	if h := NewDumper(WithTemplate(tmpl)).Header(); !strings.HasSuffix(h, "=template") {
+		t.Errorf("template: header %q", h)
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	d := NewDumper(WithChecksum(true), WithHeader(true))
+	out := d.Sdump([]int{1, 2})
+	if !strings.HasPrefix(out, d.Header()+"\n"+Sdump([]int{1, 2})) {
+		t.Errorf("unexpected dump %q", out)
+	}
+	if err := VerifyChecksum(out); err != nil {
+		t.Error(err)
+	}
+
+	if err := VerifyChecksum(strings.Replace(out, "2", "3", 1)); err == nil {
+		t.Error("corrupted dump verified")
+	}
+	lines := strings.SplitAfter(out, "\n")
+	if err := VerifyChecksum(strings.Join(lines[:1], "") + lines[len(lines)-2]); err == nil {
+		t.Error("truncated dump verified")
+	}
+	if err := VerifyChecksum(Sdump(1)); err == nil {
+		t.Error("dump without checksum verified")
+	}
+}
+
+func TestElementTypes(t *testing.T) {
+	d := NewDumper(WithElementTypes(false))
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{[]int{1, 2}, "([]int)\n  0 1\n  1 2\n"},
+		{[]interface{}{1, 2}, "([]interface {}) elem=int\n  0 1\n  1 2\n"},
+		{[]interface{}{1, "a"}, "([]interface {})\n  0(int) 1\n  1(string) \"a\"\n"},
+		{[1]S{{1, 2}}, "([1]godump.S)\n  0\n    A(int) 1\n    B(int) 2\n"},
+	}
+	for _, tt := range tests {
+		if got := d.Sdump(tt.v); got != tt.want {
+			t.Errorf("%T: got %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestMarkers(t *testing.T) {
+	d := NewDumper(WithMarkers(true))
+	want := "===== BEGIN dump \"cache state\" =====\n(int) 1\n===== END dump \"cache state\" =====\n"
+	if got := d.sdump("cache state", 1); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	want = "===== BEGIN dump =====\n(int) 1\n===== END dump =====\n"
+	if got := d.Sdump(1); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	d = NewDumper(WithMarkers(true), WithChecksum(true))
+	if err := VerifyChecksum(d.sdump("x", []int{1})); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStamp(t *testing.T) {
+	defer func(f func() time.Time) { now = f }(now)
+	now = func() time.Time { return time.Date(2014, 5, 1, 12, 0, 0, 5, time.UTC) }
+
+	d := NewDumper(WithSequence(true), WithTimestamp(true))
+	first := d.Sdump(1)
+	var seq uint64
+	if _, err := fmt.Sscanf(first, "seq=%d time=2014-05-01T12:00:00.000000005Z\n(int) 1\n", &seq); err != nil {
+		t.Fatalf("%q: %v", first, err)
+	}
+	want := fmt.Sprintf("seq=%d\n(int) 1\n", seq+1)
+	if got := NewDumper(WithSequence(true)).Sdump(1); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	want = "time=2014-05-01T12:00:00.000000005Z\n(int) 1\n"
+	if got := NewDumper(WithTimestamp(true)).Sdump(1); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+type pool struct {
+	Conns [][]int
+}
+
+func TestTypeDepth(t *testing.T) {
+	v := struct {
+		Pool  *pool
+		Other [][]int
+	}{&pool{[][]int{{1}}}, [][]int{{2}}}
+	d := NewDumper(WithTypeDepth(reflect.TypeOf(&pool{}), 1))
+	want := "(struct { Pool *godump.pool; Other [][]int })\n" +
+		"  Pool(*godump.pool)\n" +
+		"    Pool(godump.pool)\n" +
+		"      Conns([][]int) ...(max depth reached)\n" +
+		"  Other([][]int)\n" +
+		"    0([]int)\n" +
+		"      0(int) 2\n"
+	if got := d.Sdump(v); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCallOptions(t *testing.T) {
+	want := "(struct { A []int }) {A:{1, 2}}\n"
+	if got := Sdump(struct{ A []int }{[]int{1, 2}}, WithInline(80)); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := Sdump(1); got != "(int) 1\n" {
+		t.Errorf("options leaked into std: %q", got)
+	}
+
+	d := NewDumper(WithTypeDepth(reflect.TypeOf(0), 1))
+	d.With(WithTypeDepth(reflect.TypeOf(""), 1))
+	if len(d.typeDepth) != 1 {
+		t.Errorf("With modified the Dumper: %v", d.typeDepth)
+	}
+}
+
+func TestDumperLimits(t *testing.T) {
+	var b strings.Builder
+	d := NewDumper(WithIndent(4), WithMaxDepth(2), WithMaxElements(2), WithWriter(&b))
+	d.Dump([][]int{{1, 2, 3}, {4}, {5}})
+	want := "([][]int)\n" +
+		"    0([]int)\n" +
+		"        0(int) 1\n" +
+		"        1(int) 2\n" +
+		"        ... 1 more element\n" +
+		"    1([]int)\n" +
+		"        0(int) 4\n" +
+		"    ... 1 more element\n"
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+
+	b.Reset()
+	d.With(WithIndent(1)).Dump(map[string][][]int{"a": {{1}}})
+	want = "(map[string][][]int)\n" +
+		" a([][]int)\n" +
+		"  0([]int) ...(max depth reached)\n"
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+	if got, want := d.Header(), "godump/21 opts=maxdepth=2,maxelements=2,indent=4"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+type treeNode struct {
+	Name   string
+	Parent *treeNode
+	Kids   []*treeNode
+}
+
+func TestMaxDepth(t *testing.T) {
+	root := &treeNode{Name: "root"}
+	root.Kids = []*treeNode{{Name: "kid", Parent: root}}
+	want := "(*godump.treeNode)\n" +
+		"  (godump.treeNode)\n" +
+		"    Name(string) \"root\"\n" +
+		"    Parent(*godump.treeNode) nil\n" +
+		"    Kids([]*godump.treeNode)\n" +
+		"      0(*godump.treeNode) ...(max depth reached)\n"
+	if got := Sdump(root, WithMaxDepth(3)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got := Sdump(root, WithMaxDepth(3), WithMaxDepth(0)); !strings.Contains(got, "<cycle to") {
+		t.Errorf("unlimited: got\n%s", got)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Duplicates reports the distinct pointers reachable from v whose values
+// are deeply equal, one set per line, e.g.
+//
+//	3 copies of identical Config found at Servers[0].Config, Servers[1].Config, Servers[2].Config
+//
+// Sharing a single copy of such values saves the memory of the others.
+// Pointers are found at the first path they are reached by, and values are
+// compared by their dump, so fields excluded from dumps are not compared.
+// Sets are reported in the order they were first found. The result is
+// empty if there are no duplicates.
+func Duplicates(v interface{}, opts ...Option) string {
+	return std().With(opts...).Duplicates(v)
+}
+
+// Duplicates is the Dumper version of the package-level Duplicates.
+func (d *Dumper) Duplicates(v interface{}) string {
+	f := &dupFinder{
+		v:    d.newVariable(nil, ""),
+		seen: make(map[visit]bool),
+		sets: make(map[string]*dupSet),
+	}
+	f.walk(reflect.ValueOf(v), "")
+
+	var b strings.Builder
+	for _, s := range f.order {
+		if len(s.paths) < 2 {
+			continue
+		}
+		b.WriteString(strconv.Itoa(len(s.paths)) + " copies of identical " + s.typ + " found at ")
+		b.WriteString(strings.Join(s.paths, ", ") + "\n")
+	}
+	return b.String()
+}
+
+type dupFinder struct {
+	v *variable
+
+	// Pointers already found
+	seen map[visit]bool
+
+	// Sets of pointers to equal values, by their type and dump
+	sets  map[string]*dupSet
+	order []*dupSet
+}
+
+type dupSet struct {
+	typ   string
+	paths []string
+}
+
+func (f *dupFinder) walk(val reflect.Value, path string) {
+	val = f.v.accessible(val)
+	if !val.IsValid() || !val.CanInterface() && f.v.d.fields == ExportedOnly {
+		return
+	}
+	switch val.Kind() {
+	case reflect.Interface:
+		if !val.IsNil() {
+			f.walk(val.Elem(), path)
+		}
+	case reflect.Ptr:
+		if val.IsNil() {
+			return
+		}
+		p := visitOf(val)
+		if f.seen[p] {
+			return
+		}
+		f.seen[p] = true
+		f.add(val, path)
+		f.walk(val.Elem(), path)
+	case reflect.Struct:
+		for i := 0; i < val.NumField(); i++ {
+			if fv, ok := f.v.structField(val, i); ok {
+				f.walk(fv, joinPath(path, val.Type().Field(i).Name))
+			}
+		}
+	case reflect.Map:
+		if val.IsNil() || f.v.visiting[visitOf(val)] {
+			return
+		}
+		f.v.enter(val)
+		_, values, names := mapEntries(val, f.v.keyName)
+		for i, e := range values {
+			f.walk(e, path+"["+names[i]+"]")
+		}
+		f.v.leave(val)
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < val.Len(); i++ {
+			f.walk(val.Index(i), path+"["+strconv.Itoa(i)+"]")
+		}
+	}
+}
+
+// add records the pointer val, found at path, with the pointers to values
+// equal to the one it points to.
+func (f *dupFinder) add(val reflect.Value, path string) {
+	var b strings.Builder
+	b.WriteString(typeName(val.Type()) + "\n")
+	v := f.v.d.newVariable(&b, "")
+	v.dump(val.Elem(), "", "")
+	v.w.Flush()
+
+	s := f.sets[b.String()]
+	if s == nil {
+		s = &dupSet{typ: shortTypeName(val.Type())}
+		f.sets[b.String()] = s
+		f.order = append(f.order, s)
+	}
+	s.paths = append(s.paths, tracePath(path))
+}
@@ -0,0 +1,46 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import "testing"
+
+type dupConfig struct {
+	Host string
+	Port int
+}
+
+type dupServer struct {
+	Name   string
+	Config *dupConfig
+}
+
+func TestDuplicates(t *testing.T) {
+	shared := &dupConfig{"db", 5432}
+	v := map[string]interface{}{
+		"servers": []dupServer{
+			{"a", &dupConfig{"web", 80}},
+			{"b", &dupConfig{"web", 80}},
+			{"c", shared},
+			{"d", shared},
+			{"e", &dupConfig{"web", 80}},
+			{"f", &dupConfig{"web", 8080}},
+		},
+		"backup": shared,
+	}
+	want := "3 copies of identical dupConfig found at [servers][0].Config, [servers][1].Config, [servers][4].Config\n"
+	if got := Duplicates(v); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := Duplicates(dupServer{"a", shared}); got != "" {
+		t.Errorf("no duplicates: got %q", got)
+	}
+
+	m := map[string]interface{}{"a": &dupConfig{"web", 80}, "b": &dupConfig{"web", 80}}
+	m["self"] = m
+	want = "2 copies of identical dupConfig found at [a], [b]\n"
+	if got := Duplicates(m); got != want {
+		t.Errorf("map cycle: got %q, want %q", got, want)
+	}
+}
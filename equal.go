@@ -0,0 +1,48 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Equal reports whether a and b are equal as compared by Diff, but stopping
+// at the first difference: leaves by their Go syntax, values dumped as text,
+// like errors and times, by their text, and composite values element by
+// element. Unlike reflect.DeepEqual, NaNs equal each other and funcs equal
+// each other if they have the same address. Unexported fields are compared
+// too, unless WithUnexported(false) or WithIgnoreFields leave them out:
+//
+//	godump.Equal(got, want, godump.WithIgnoreFields("ID", "CreatedAt"))
+//
+// Options are applied to a new Dumper rather than the default one, so the
+// result does not depend on the configuration of other dumps. Limits like
+// WithMaxElements do not apply to comparisons.
+func Equal(a, b interface{}, opts ...Option) bool {
+	_, ok := EqualReport(a, b, opts...)
+	return ok
+}
+
+// EqualReport is like Equal, also returning the first difference between
+// a and b as Diff reports it, e.g. User.Address.Zip: -"12345" +"54321",
+// if they are not equal.
+func EqualReport(a, b interface{}, opts ...Option) (string, bool) {
+	return NewDumper(WithUnexported(true)).With(opts...).EqualReport(a, b)
+}
+
+// Equal is the Dumper version of the package-level Equal. Unlike it, it
+// compares the struct fields d dumps.
+func (d *Dumper) Equal(a, b interface{}) bool {
+	_, ok := d.EqualReport(a, b)
+	return ok
+}
+
+// EqualReport is the Dumper version of the package-level EqualReport.
+func (d *Dumper) EqualReport(a, b interface{}) (string, bool) {
+	df := &differ{v: d.newVariable(nil, ""), first: true}
+	df.diff(reflect.ValueOf(a), reflect.ValueOf(b), "")
+	return strings.TrimSuffix(df.out.String(), "\n"), !df.differs
+}
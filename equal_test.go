@@ -0,0 +1,126 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEqual(t *testing.T) {
+	type account struct {
+		ID      int
+		Owner   string
+		Created time.Time
+		Err     error
+		balance float64
+	}
+	created := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	a := account{1, "bob", created, errors.New("frozen"), 10}
+	tests := []struct {
+		b      account
+		opts   []Option
+		report string
+	}{
+		{a, nil, ""},
+		{account{1, "bob", created.In(time.UTC), errors.New("frozen"), 10}, nil, ""},
+		{account{2, "ann", created, errors.New("frozen"), 10}, nil, "ID: -1 +2"},
+		{account{2, "bob", created, errors.New("frozen"), 10}, []Option{WithIgnoreFields("ID")}, ""},
+		{account{1, "bob", created, errors.New("closed"), 10}, nil, `Err: -"frozen" +"closed"`},
+		{account{1, "bob", created, errors.New("frozen"), 20}, nil, "balance: -10 +20"},
+		{account{1, "bob", created, errors.New("frozen"), 20}, []Option{WithUnexported(false)}, ""},
+	}
+	for i, tt := range tests {
+		report, ok := EqualReport(a, tt.b, tt.opts...)
+		if report != tt.report || ok != (tt.report == "") {
+			t.Errorf("%d: EqualReport = %q, %v, want %q", i, report, ok, tt.report)
+		}
+		if got := Equal(a, tt.b, tt.opts...); got != ok {
+			t.Errorf("%d: Equal = %v, EqualReport = %v", i, got, ok)
+		}
+	}
+
+	if !Equal(math.NaN(), math.NaN()) {
+		t.Error("NaNs not equal")
+	}
+	if Equal([]int{1}, []int64{1}) {
+		t.Error("values of different types equal")
+	}
+	if got := Sdump(a, WithIgnoreFields("Created", "Err")); got != "(godump.account)\n  ID(int) 1\n  Owner(string) \"bob\"\n" {
+		t.Errorf("dump with ignored fields: got\n%s", got)
+	}
+}
+
+func TestEqualRedacted(t *testing.T) {
+	type login struct {
+		User     string
+		Password string
+		Session  string `dump:"redact"`
+		Headers  map[string]string
+	}
+	a := login{"bob", "hunter2", "s1", map[string]string{"X-Auth-Token": "t1"}}
+	tests := []struct {
+		b      login
+		report string
+	}{
+		{a, ""},
+		{login{"bob", "hunter3", "s1", a.Headers}, "Password: redacted values differ"},
+		{login{"bob", "hunter2", "s2", a.Headers}, "Session: redacted values differ"},
+		{login{"bob", "hunter2", "s1", map[string]string{"X-Auth-Token": "t2"}}, "Headers[X-Auth-Token]: redacted values differ"},
+		{login{"bob", "hunter2", "s1", map[string]string{}}, "Headers[X-Auth-Token]: -***REDACTED***"},
+	}
+	for i, tt := range tests {
+		report, ok := EqualReport(a, tt.b, WithRedact(RedactNames()))
+		if report != tt.report || ok != (tt.report == "") {
+			t.Errorf("%d: EqualReport = %q, %v, want %q", i, report, ok, tt.report)
+		}
+	}
+	type cred struct {
+		User   string
+		Secret string `dump:"redact"`
+	}
+	report, ok := EqualReport([]cred{{"bob", "s3cr3t"}}, []cred{{"bob", "other"}})
+	if ok || report != "[0].Secret: redacted values differ" {
+		t.Errorf("slices differing in a tagged field: got %q, %v", report, ok)
+	}
+
+	want := "Password: redacted values differ\nSession: redacted values differ\n"
+	if got := Diff(a, login{"bob", "x", "y", a.Headers}, WithRedact(RedactNames())); got != want {
+		t.Errorf("Diff got %q, want %q", got, want)
+	}
+}
+
+func TestEqualLimits(t *testing.T) {
+	if Equal([][]int{{1, 2, 3}}, [][]int{{1, 2, 4}}, WithMaxElements(2)) {
+		t.Error("values differing after the element limit equal")
+	}
+	if Equal([]string{"abcdef"}, []string{"abcdeg"}, WithMaxStringLen(3)) {
+		t.Error("values differing after the string limit equal")
+	}
+
+	defer SetDefault(std())
+	SetDefault(NewDumper(WithIgnoreFields("ID")))
+	if Equal(struct{ ID int }{1}, struct{ ID int }{2}) {
+		t.Error("Equal depends on the default Dumper")
+	}
+}
+
+func TestEqualLarge(t *testing.T) {
+	// Too long to be aligned by their longest common subsequence.
+	a, b := make([]int, 5000), make([]int, 5000)
+	for i := range a {
+		a[i], b[i] = i, -i-1
+	}
+	if Equal(a, b) {
+		t.Error("different slices equal")
+	}
+	b = append([]int(nil), a...)
+	b[2500] = -1
+	if got, want := Diff(a, b), "[2500]: -2500 +-1\n"; got != want {
+		t.Errorf("Diff got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// WithErrorText controls whether values implementing error are rendered as
+// the quoted result of their Error method, annotated with their concrete
+// type, instead of their internals. It is enabled by default. At verbosity 2
+// and above, the fields of errors are expanded below the text.
+func WithErrorText(enable bool) Option {
+	return func(d *Dumper) {
+		d.errorText = enable
+	}
+}
+
+// WithFullExpansion dumps errors and Stringers by their contents at every
+// level, ignoring their Error and String methods regardless of
+// WithErrorText and the Stringer policies.
+func WithFullExpansion(enable bool) Option {
+	return func(d *Dumper) {
+		d.fullExpansion = enable
+	}
+}
+
+// WithVerbosity sets the level of detail of the dump. The default level is
+// 1; higher levels expand values that are summarized by default.
+func WithVerbosity(level int) Option {
+	return func(d *Dumper) {
+		d.verbosity = level
+	}
+}
+
+// errorText returns the quoted message of val if it is a non-nil error.
+// Error methods panicking are reported as such.
+func errorText(val reflect.Value) (s string, ok bool) {
+	if !val.CanInterface() || (val.Kind() == reflect.Interface || val.Kind() == reflect.Ptr) && val.IsNil() {
+		return "", false
+	}
+	err, ok := val.Interface().(error)
+	if !ok {
+		return "", false
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			s = "<Error panicked>"
+		}
+	}()
+	return strconv.Quote(err.Error()), true
+}
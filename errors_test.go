@@ -0,0 +1,60 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"errors"
+	"testing"
+)
+
+type codeError struct {
+	Code int
+}
+
+func (e *codeError) Error() string {
+	return "code " + string(rune('0'+e.Code))
+}
+
+type panicError struct{}
+
+func (panicError) Error() string {
+	panic("unreachable")
+}
+
+func TestErrorText(t *testing.T) {
+	v := struct {
+		Err  error
+		Code *codeError
+		Nil  *codeError
+	}{errors.New("boom"), &codeError{7}, nil}
+
+	want := "(struct { Err error; Code *godump.codeError; Nil *godump.codeError })\n" +
+		"  Err(error -> *errors.errorString) \"boom\"\n" +
+		"  Code(*godump.codeError) \"code 7\"\n" +
+		"  Nil(*godump.codeError) nil\n"
+	if got := Sdump(v); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	want = "(*godump.codeError) \"code 7\"\n" +
+		"  (godump.codeError)\n" +
+		"    Code(int) 7\n"
+	if got := NewDumper(WithVerbosity(2)).Sdump(&codeError{7}); got != want {
+		t.Errorf("verbose: got\n%s\nwant\n%s", got, want)
+	}
+
+	want = "(*godump.codeError)\n" +
+		"  (godump.codeError)\n" +
+		"    Code(int) 7\n"
+	if got := NewDumper(WithErrorText(false)).Sdump(&codeError{7}); got != want {
+		t.Errorf("disabled: got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestErrorTextPanic(t *testing.T) {
+	if got, want := Sdump(panicError{}), "(godump.panicError) <Error panicked>\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
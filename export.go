@@ -0,0 +1,163 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// ExportFormat selects the file format of exported numeric data.
+type ExportFormat int
+
+const (
+	// CSV writes one element per line.
+	CSV ExportFormat = iota + 1
+
+	// NPY writes a one-dimensional NumPy array of the element type.
+	NPY
+)
+
+// WithExport writes the elements of numeric arrays and slices whose dump
+// is cut short by the element limit or by downsampling to a new file in
+// dir, in format f, and notes the name of the file in the dump:
+//
+//	([]float64) (data: /tmp/Samples-1234.npy)
+//
+// so the dump stays small but no data is lost. Files are named after the
+// path of the value and never overwritten. An empty dir disables exports;
+// this is the default.
+func WithExport(dir string, f ExportFormat) Option {
+	return func(d *Dumper) {
+		d.exportDir = dir
+		d.exportFormat = f
+	}
+}
+
+// export writes the elements of the numeric array or slice val to a file
+// and annotates n with its name.
+func (v *variable) export(val reflect.Value, n *Node) {
+	if v.d.exportDir == "" || !isNumeric(val.Type().Elem().Kind()) {
+		return
+	}
+	name, err := v.writeExport(val, n.Path)
+	if err != nil {
+		n.annotate("(export failed: " + err.Error() + ")")
+		return
+	}
+	n.annotate("(data: " + name + ")")
+}
+
+func (v *variable) writeExport(val reflect.Value, path string) (string, error) {
+	ext := ".csv"
+	if v.d.exportFormat == NPY {
+		ext = ".npy"
+	}
+	f, err := os.CreateTemp(v.d.exportDir, exportName(path)+"-*"+ext)
+	if err != nil {
+		return "", err
+	}
+	w := bufio.NewWriter(f)
+	if v.d.exportFormat == NPY {
+		err = writeNPY(w, val)
+	} else {
+		err = writeCSV(w, val)
+	}
+	if ferr := w.Flush(); err == nil {
+		err = ferr
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// exportName returns the part of file names derived from path.
+func exportName(path string) string {
+	if path == "" {
+		return "value"
+	}
+	return strings.Map(func(r rune) rune {
+		if r < 0x80 && isPathByte(byte(r)) {
+			return r
+		}
+		return '_'
+	}, path)
+}
+
+func writeCSV(w io.Writer, val reflect.Value) error {
+	var b []byte
+	for i := 0; i < val.Len(); i++ {
+		b = append(b[:0], leafValue(val.Index(i))...)
+		if _, err := w.Write(append(b, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeNPY writes val in version 1.0 of the NPY format, little-endian.
+func writeNPY(w io.Writer, val reflect.Value) error {
+	t := val.Type().Elem()
+	code := "i"
+	switch {
+	case t.Kind() >= reflect.Float32:
+		code = "f"
+	case t.Kind() >= reflect.Uint:
+		code = "u"
+	}
+	size := int(t.Size())
+	dict := fmt.Sprintf("{'descr': '<%s%d', 'fortran_order': False, 'shape': (%d,), }", code, size, val.Len())
+	// The header is padded with spaces and a newline to a multiple of 64
+	// bytes, counting the magic string, the version and the length.
+	hlen := len(dict) + 1
+	hlen += (64 - (10+hlen)%64) % 64
+	header := make([]byte, 10, 10+hlen)
+	copy(header, "\x93NUMPY\x01\x00")
+	binary.LittleEndian.PutUint16(header[8:], uint16(hlen))
+	header = append(header, dict...)
+	for len(header) < 10+hlen-1 {
+		header = append(header, ' ')
+	}
+	header = append(header, '\n')
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	b := make([]byte, size)
+	for i := 0; i < val.Len(); i++ {
+		e := val.Index(i)
+		var u uint64
+		switch code {
+		case "f":
+			if size == 4 {
+				u = uint64(math.Float32bits(float32(e.Float())))
+			} else {
+				u = math.Float64bits(e.Float())
+			}
+		case "u":
+			u = e.Uint()
+		default:
+			u = uint64(e.Int())
+		}
+		for j := range b {
+			b[j] = byte(u >> (8 * j))
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"regexp"
+	"testing"
+)
+
+var exportNote = regexp.MustCompile(`\(data: ([^)]+)\)`)
+
+func TestExport(t *testing.T) {
+	dir := t.TempDir()
+	d := NewDumper(WithExport(dir, CSV))
+	d.maxElements = 2
+	v := struct{ Samples []int }{[]int{1, 2, 3, 4}}
+	out := d.Sdump(v)
+	m := exportNote.FindStringSubmatch(out)
+	if m == nil {
+		t.Fatalf("no data note in\n%s", out)
+	}
+	b, err := os.ReadFile(m[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "1\n2\n3\n4\n"; string(b) != want {
+		t.Errorf("got %q, want %q", b, want)
+	}
+
+	d = NewDumper(WithExport(dir, NPY), WithDownsample(EveryNth, 2))
+	out = d.Sdump([]float32{1.5, 2, 3})
+	m = exportNote.FindStringSubmatch(out)
+	if m == nil {
+		t.Fatalf("no data note in\n%s", out)
+	}
+	b, err = os.ReadFile(m[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(b, []byte("\x93NUMPY\x01\x00")) {
+		t.Fatalf("bad magic: %q", b)
+	}
+	hlen := int(binary.LittleEndian.Uint16(b[8:]))
+	if (10+hlen)%64 != 0 {
+		t.Errorf("header length %d not aligned", hlen)
+	}
+	header := string(b[10 : 10+hlen])
+	if want := "{'descr': '<f4', 'fortran_order': False, 'shape': (3,), }"; !bytes.HasPrefix([]byte(header), []byte(want)) {
+		t.Errorf("got header %q, want %q", header, want)
+	}
+	if data := b[10+hlen:]; !bytes.Equal(data, []byte{0, 0, 0xc0, 0x3f, 0, 0, 0, 0x40, 0, 0, 0x40, 0x40}) {
+		t.Errorf("got data % x", data)
+	}
+
+	if out := d.Sdump([]string{"a", "b", "c"}); exportNote.MatchString(out) {
+		t.Errorf("non-numeric slice exported:\n%s", out)
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// FieldMode selects which struct fields are dumped.
+type FieldMode int
+
+const (
+	// ExportedOnly dumps exported fields only. This is the default.
+	ExportedOnly FieldMode = iota
+
+	// AllFields also dumps unexported fields, as far as reflection can read
+	// them without unsafe. Basic values are shown, and composite values are
+	// traversed; values that cannot be read are marked <unexported>.
+	AllFields
+
+	// AllWithUnsafe dumps unexported fields like exported ones, reading them
+	// through package unsafe. This allows calling methods such as Error on
+	// them, which might observe inconsistent state.
+	AllWithUnsafe
+)
+
+// WithFields sets which struct fields are dumped.
+func WithFields(mode FieldMode) Option {
+	return func(d *Dumper) {
+		d.fields = mode
+	}
+}
+
+// WithUnexported makes dumps include unexported struct fields, read
+// through package unsafe like exported ones, when enable is true, as
+// WithFields(AllWithUnsafe) does. Private state is often what debugging is
+// after. When enable is false, only exported fields are dumped.
+func WithUnexported(enable bool) Option {
+	if enable {
+		return WithFields(AllWithUnsafe)
+	}
+	return WithFields(ExportedOnly)
+}
+
+// WithIgnoreFields leaves the struct fields with any of names out of dumps
+// and comparisons, wherever they are, e.g. WithIgnoreFields("Password",
+// "UpdatedAt").
+func WithIgnoreFields(names ...string) Option {
+	return func(d *Dumper) {
+		if d.ignoredFields == nil {
+			d.ignoredFields = make(map[string]bool)
+		}
+		for _, name := range names {
+			d.ignoredFields[name] = true
+		}
+	}
+}
+
+// WithIgnorePaths leaves the struct fields whose path matches any of
+// patterns out of dumps and comparisons, e.g. WithIgnorePaths(
+// "Config.TLS.Key", "Users[*].Avatar"). In patterns, * matches any part of
+// a path, dots and brackets included; other characters match themselves.
+func WithIgnorePaths(patterns ...string) Option {
+	return func(d *Dumper) {
+		d.ignoredPaths = append(d.ignoredPaths, patterns...)
+	}
+}
+
+// ignoredPath reports whether the struct field at p is ignored by
+// WithIgnorePaths.
+func (v *variable) ignoredPath(p string) bool {
+	for _, pattern := range v.d.ignoredPaths {
+		if matchPath(pattern, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath reports whether p matches pattern as described by
+// WithIgnorePaths.
+func matchPath(pattern, p string) bool {
+	first, rest, wildcard := strings.Cut(pattern, "*")
+	if !wildcard {
+		return p == pattern
+	}
+	if !strings.HasPrefix(p, first) {
+		return false
+	}
+	p = p[len(first):]
+	for i := 0; i <= len(p); i++ {
+		if matchPath(rest, p[i:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// skippedByTag reports whether the field f is tagged to be left out of
+// dumps, by dump:"-" or godump:"-".
+func skippedByTag(f reflect.StructField) bool {
+	return f.Tag.Get("dump") == "-" || f.Tag.Get("godump") == "-"
+}
+
+// structField returns the field i of the struct val as it is dumped, and
+// whether it is dumped at all. Embedded interfaces are replaced by their
+// dynamic values. Unexported embedded structs, and pointers to them, are
+// dumped for their promoted fields even if only exported fields are.
+// Ignored fields and fields tagged dump:"-" are left out, and nil funcs if
+// WithOmitNilFuncs says so.
+func (v *variable) structField(val reflect.Value, i int) (reflect.Value, bool) {
+	field := val.Type().Field(i)
+	if v.d.fields == ExportedOnly && !field.IsExported() && !promotesFields(field) ||
+		v.d.ignoredFields[field.Name] || skippedByTag(field) {
+		return reflect.Value{}, false
+	}
+	fv := val.Field(i)
+	if v.d.omitNilFuncs && nilFunc(fv) {
+		return reflect.Value{}, false
+	}
+	if field.Anonymous && fv.Kind() == reflect.Interface && !fv.IsNil() {
+		fv = fv.Elem()
+	}
+	return fv, true
+}
+
+// promotesFields reports whether f is an embedded struct or pointer to one.
+func promotesFields(f reflect.StructField) bool {
+	t := f.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return f.Anonymous && t.Kind() == reflect.Struct
+}
+
+// accessible returns val such that it can be used like an exported value,
+// if the field mode allows it. Values that are not addressable are copied
+// to make their unexported fields addressable.
+func (v *variable) accessible(val reflect.Value) reflect.Value {
+	if v.d.fields != AllWithUnsafe || !val.IsValid() {
+		return val
+	}
+	if !val.CanInterface() && val.CanAddr() {
+		return reflect.NewAt(val.Type(), unsafe.Pointer(val.UnsafeAddr())).Elem()
+	}
+	if val.CanInterface() && !val.CanAddr() && (val.Kind() == reflect.Struct || val.Kind() == reflect.Array) {
+		c := reflect.New(val.Type()).Elem()
+		c.Set(val)
+		return c
+	}
+	return val
+}
+
+// typeString returns the type of val as %T prints it, with the type
+// arguments of generic types written as in source.
+func typeString(val reflect.Value) string {
+	if val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return "<nil>"
+		}
+		return typeName(val.Elem().Type())
+	}
+	return typeName(val.Type())
+}
+
+// typeName returns the name of t as it is written in source. Unlike the
+// name given by reflect, the type arguments of instantiated generic types
+// are qualified by package name rather than import path and separated by
+// ", ", e.g. cache.LRU[string, *model.User].
+func typeName(t reflect.Type) string {
+	return cleanTypeName(t.String())
+}
+
+// cleanTypeName rewrites the type arguments in the type name s given by
+// reflect as typeName does.
+func cleanTypeName(s string) string {
+	if !strings.Contains(s, "]") || !strings.ContainsAny(s, "/,") {
+		return s
+	}
+	var b strings.Builder
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' && (i == 0 || s[i-1] != '\\'):
+			quoted = !quoted
+		case quoted:
+		case c == ',' && i+1 < len(s) && s[i+1] != ' ':
+			b.WriteString(", ")
+			continue
+		case c == '/':
+			// Drop the import path up to the package name.
+			j := b.Len()
+			out := b.String()
+			for j > 0 && isPathByte(out[j-1]) {
+				j--
+			}
+			b.Reset()
+			b.WriteString(out[:j])
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// isPathByte reports whether c may appear in an import path element.
+func isPathByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '.' || c == '-' || c == '_' || c == '~'
+}
+
+// leafValue returns the Go-syntax representation of val as %#v prints it.
+// Values that cannot be interfaced, because they were obtained through
+// unexported fields, are formatted from what reflection gives away.
+func leafValue(val reflect.Value) string {
+	if val.CanInterface() && !plainLeaf(val) {
+		return fmt.Sprintf("%#v", val.Interface())
+	}
+	switch val.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(val.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(val.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return fmt.Sprintf("%#v", val.Uint())
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(val.Float(), 'g', -1, val.Type().Bits())
+	case reflect.Complex64, reflect.Complex128:
+		return strconv.FormatComplex(val.Complex(), 'g', -1, val.Type().Bits())
+	case reflect.String:
+		return strconv.Quote(val.String())
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		if val.IsNil() {
+			return fmt.Sprintf("(%s)(nil)", val.Type())
+		}
+		return fmt.Sprintf("(%s)(%#x)", val.Type(), val.Pointer())
+	case reflect.Interface:
+		if val.IsNil() {
+			return "<nil>"
+		}
+		return leafValue(val.Elem())
+	}
+	return "<unexported>"
+}
+
+// plainLeaf reports whether val is a bool, integer or string of a
+// predeclared type, which has no methods changing how it is printed, so it
+// is formatted without the overhead of fmt.
+func plainLeaf(val reflect.Value) bool {
+	if val.Type().PkgPath() != "" {
+		return false
+	}
+	switch val.Kind() {
+	case reflect.Bool, reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+// keyString returns the name of the map entry with key k: strings as they
+// are, other keys in Go syntax, e.g. 42 or godump.S{A:1, B:2}. Interface
+// keys are named by their dynamic value.
+func keyString(k reflect.Value) string {
+	for k.Kind() == reflect.Interface && !k.IsNil() {
+		k = k.Elem()
+	}
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	return leafValue(k)
+}
+
+// isStringKey reports whether the map key k holds a string.
+func isStringKey(k reflect.Value) bool {
+	for k.Kind() == reflect.Interface && !k.IsNil() {
+		k = k.Elem()
+	}
+	return k.Kind() == reflect.String
+}
@@ -0,0 +1,259 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type account struct {
+	Name    string
+	balance float64
+	tags    []string
+	err     error
+	ch      chan int
+}
+
+func TestFields(t *testing.T) {
+	v := account{"bob", 1.5, []string{"vip"}, errors.New("overdrawn"), nil}
+	tests := []struct {
+		mode FieldMode
+		want string
+	}{
+		{ExportedOnly, "(godump.account)\n" +
+			"  Name(string) \"bob\"\n"},
+		{AllFields, "(godump.account)\n" +
+			"  Name(string) \"bob\"\n" +
+			"  balance(float64) 1.5\n" +
+			"  tags([]string)\n" +
+			"    0(string) \"vip\"\n" +
+			"  err(error -> *errors.errorString)\n" +
+			"    err(errors.errorString)\n" +
+			"      s(string) \"overdrawn\"\n" +
+			"  ch(chan int) nil\n"},
+		{AllWithUnsafe, "(godump.account)\n" +
+			"  Name(string) \"bob\"\n" +
+			"  balance(float64) 1.5\n" +
+			"  tags([]string)\n" +
+			"    0(string) \"vip\"\n" +
+			"  err(error -> *errors.errorString) \"overdrawn\"\n" +
+			"  ch(chan int) nil\n"},
+	}
+	for _, tt := range tests {
+		if got := NewDumper(WithFields(tt.mode)).Sdump(v); got != tt.want {
+			t.Errorf("mode %d: got\n%s\nwant\n%s", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestUnexported(t *testing.T) {
+	v := account{"bob", 1.5, nil, errors.New("overdrawn"), nil}
+	if got, want := Sdump(v, WithUnexported(true)), Sdump(v, WithFields(AllWithUnsafe)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got, want := Sdump(v, WithUnexported(true), WithUnexported(false)), Sdump(v); got != want {
+		t.Errorf("disabled: got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUnsafeFieldsThroughPointer(t *testing.T) {
+	v := &account{balance: 2}
+	d := NewDumper(WithFields(AllWithUnsafe), WithInline(80))
+	want := "(*godump.account) &{Name:\"\", balance:2, tags:nil, err:nil, ch:nil}\n"
+	if got := d.Sdump(v); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+type pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+type nested[K comparable, V any] struct {
+	Items map[K][]V
+}
+
+func TestGenericTypeNames(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{pair[string, *S]{}, "godump.pair[string, *godump.S]"},
+		{&nested[int, pair[S, []S]]{}, "*godump.nested[int, godump.pair[godump.S, []godump.S]]"},
+		{[]pair[map[string]S, struct{ X int }]{}, "[]godump.pair[map[string]godump.S, struct { X int }]"},
+		{pair[func(int, string), [2]S]{}, "godump.pair[func(int, string), [2]godump.S]"},
+		{map[string]int{}, "map[string]int"},
+	}
+	for _, tt := range tests {
+		if got := typeString(reflect.ValueOf(tt.v)); got != tt.want {
+			t.Errorf("got %s, want %s", got, tt.want)
+		}
+	}
+
+	want := "(godump.pair[godump.S, []int])\n" +
+		"  First(godump.S)\n" +
+		"    A(int) 1\n" +
+		"    B(int) 2\n" +
+		"  Second([]int)\n"
+	if got := Sdump(pair[S, []int]{S{1, 2}, []int{}}); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got := shortTypeName(reflect.TypeOf(pair[S, int]{})); got != "pair[godump.S, int]" {
+		t.Errorf("short name: got %s", got)
+	}
+}
+
+func TestMapKeys(t *testing.T) {
+	type point struct{ X, Y int }
+	x := 1
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{map[int]string{1: "a"}, "(map[int]string)\n  1(string) \"a\"\n"},
+		{map[float64]int{2.5: 1}, "(map[float64]int)\n  2.5(int) 1\n"},
+		{map[bool]int{true: 1}, "(map[bool]int)\n  true(int) 1\n"},
+		{map[point]int{{1, 2}: 3}, "(map[godump.point]int)\n  godump.point{X:1, Y:2}(int) 3\n"},
+		{map[[2]int]int{{1, 2}: 3}, "(map[[2]int]int)\n  [2]int{1, 2}(int) 3\n"},
+		{map[interface{}]int{"s": 1}, "(map[interface {}]int)\n  s(int) 1\n"},
+		{map[interface{}]int{7: 1}, "(map[interface {}]int)\n  7(int) 1\n"},
+		{map[*int]int{&x: 1}, fmt.Sprintf("(map[*int]int)\n  (*int)(%p)(int) 1\n", &x)},
+	}
+	for _, tt := range tests {
+		if got := Sdump(tt.v); got != tt.want {
+			t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+		}
+	}
+}
+
+type embeddedBase struct {
+	ID   int
+	note string
+}
+
+type embedding struct {
+	fmt.Stringer
+	embeddedBase
+	*account
+	Name string
+}
+
+type stringer string
+
+func (s stringer) String() string { return string(s) }
+
+func TestEmbedded(t *testing.T) {
+	v := embedding{Stringer: stringer("x"), embeddedBase: embeddedBase{7, "n"}, account: &account{Name: "bob"}, Name: "e"}
+	want := "(godump.embedding)\n" +
+		"  Stringer(godump.stringer) \"x\"\n" +
+		"  embeddedBase(godump.embeddedBase)\n" +
+		"    ID(int) 7\n" +
+		"  account(*godump.account)\n" +
+		"    account(godump.account)\n" +
+		"      Name(string) \"bob\"\n" +
+		"  Name(string) \"e\"\n"
+	if got := Sdump(v); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	want = "(godump.embedding) {Stringer:\"x\", embeddedBase:{ID:7}, account:&{Name:\"bob\"}, Name:\"e\"}\n"
+	if got := Sdump(v, WithInline(200)); got != want {
+		t.Errorf("inline: got\n%s\nwant\n%s", got, want)
+	}
+
+	want = "(godump.embedding)\n" +
+		"  Stringer(godump.stringer) \"x\"\n" +
+		"  embeddedBase(godump.embeddedBase)\n" +
+		"    ID(int) 7\n" +
+		"    note(string) \"n\"\n"
+	if got := Sdump(v, WithUnexported(true)); !strings.HasPrefix(got, want) {
+		t.Errorf("unexported: got\n%s\nwant prefix\n%s", got, want)
+	}
+}
+
+type quoted string
+
+func (q quoted) GoString() string { return "quoted(" + string(q) + ")" }
+
+func TestLeafValue(t *testing.T) {
+	for _, tt := range []struct {
+		v    interface{}
+		want string
+	}{
+		{42, "42"},
+		{int8(-3), "-3"},
+		{true, "true"},
+		{"a\"b\n", `"a\"b\n"`},
+		{uint(7), "0x7"},
+		{quoted("x"), "quoted(x)"},
+	} {
+		if got := leafValue(reflect.ValueOf(tt.v)); got != tt.want {
+			t.Errorf("leafValue(%#v) = %s, want %s", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestUnreadableValues(t *testing.T) {
+	v := NewDumper().newVariable(nil, "")
+	var b bytes.Buffer
+	v.w.Reset(&b)
+	v.dump(reflect.ValueOf(struct{ n int }{1}).Field(0), "n", "n")
+	v.dump(reflect.Value{}, "x", "x")
+	v.w.Flush()
+	if want := "n(int) <unexported>\nx <invalid>\n"; b.String() != want {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+}
+
+func TestIgnoredFields(t *testing.T) {
+	type tls struct {
+		Cert string
+		Key  string
+	}
+	type server struct {
+		Name     string
+		Password string
+		TLS      tls
+		Mirrors  []tls
+		Blob     []byte `dump:"-"`
+		Cache    []byte `godump:"-"`
+	}
+	v := server{"a", "secret", tls{"c", "k"}, []tls{{"c2", "k2"}}, []byte{1}, []byte{2}}
+	opts := []Option{WithIgnoreFields("Password"), WithIgnorePaths("TLS.Key", "Mirrors[*].Cert")}
+	want := `(godump.server) {Name:"a", TLS:{Cert:"c"}, Mirrors:{{Key:"k2"}}}
+`
+	if got := Sdump(v, append(opts, WithInline(80))...); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	w := v
+	w.Password, w.TLS.Key, w.Blob = "other", "other", nil
+	if !Equal(v, w, opts...) {
+		t.Errorf("ignored fields compared: %s", Diff(v, w, opts...))
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	for _, tt := range []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"TLS.Key", "TLS.Key", true},
+		{"TLS.Key", "TLS.KeyID", false},
+		{"*.Key", "Config.TLS.Key", true},
+		{"Users[*].Avatar", "Users[12].Avatar", true},
+		{"Users[*].Avatar", "Users[12].Name", false},
+		{"*", "", true},
+	} {
+		if got := matchPath(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchPath(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+type formatter struct {
+	v interface{}
+}
+
+// V wraps x so that the fmt functions print its dump for the verbs %D, %v
+// and %s. The width limits the depth of the dump and the precision the
+// number of elements shown per array, slice or map, so
+//
+//	fmt.Printf("state: %3.10D\n", godump.V(state))
+//
+// dumps state three levels deep with at most ten elements per collection.
+// The trailing newline of the dump is dropped.
+func V(x interface{}) fmt.Formatter {
+	return formatter{x}
+}
+
+func (f formatter) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'D', 'v', 's':
+	default:
+		fmt.Fprintf(s, "%%!%c(godump.V)", verb)
+		return
+	}
+
+	d := *std()
+	if w, ok := s.Width(); ok {
+		d.maxDepth = w
+	}
+	if p, ok := s.Precision(); ok {
+		d.maxElements = p
+	}
+	io.WriteString(s, strings.TrimSuffix(d.Sdump(f.v), "\n"))
+}
@@ -0,0 +1,31 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestV(t *testing.T) {
+	v := [][]int{{1, 2, 3}, {4}, {5}}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%D", strings.TrimSuffix(Sdump(v), "\n")},
+		{"%v", strings.TrimSuffix(Sdump(v), "\n")},
+		{"%1D", "([][]int)\n  0([]int) ...(max depth reached)\n  1([]int) ...(max depth reached)\n  2([]int) ...(max depth reached)"},
+		{"%.1D", "([][]int)\n  0([]int)\n    0(int) 1\n    ... 2 more elements\n  ... 2 more elements"},
+		{"%2.2D", "([][]int)\n  0([]int)\n    0(int) 1\n    1(int) 2\n    ... 1 more element\n  1([]int)\n    0(int) 4\n  ... 1 more element"},
+		{"%d", "%!d(godump.V)"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf(tt.format, V(v)); got != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.format, got, tt.want)
+		}
+	}
+}
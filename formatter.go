@@ -0,0 +1,99 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"maps"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Formatter returns the text a value is rendered as, e.g. a summary of a
+// domain object, instead of the dump of its contents.
+type Formatter func(v interface{}) string
+
+var (
+	// Formatters registered by RegisterFormatter, replaced as a whole
+	formattersMu sync.Mutex
+	formatters   atomic.Pointer[map[reflect.Type]Formatter]
+)
+
+// RegisterFormatter makes all Dumpers render the values of type t as the
+// text returned by f:
+//
+//	godump.RegisterFormatter(reflect.TypeOf(OrderID{}), func(v interface{}) string {
+//		return v.(OrderID).String()
+//	})
+//
+// It is meant to be called at init time. Formatters set by WithFormatter
+// take precedence.
+func RegisterFormatter(t reflect.Type, f Formatter) {
+	formattersMu.Lock()
+	defer formattersMu.Unlock()
+	m := make(map[reflect.Type]Formatter)
+	if old := formatters.Load(); old != nil {
+		m = maps.Clone(*old)
+	}
+	m[t] = f
+	formatters.Store(&m)
+}
+
+// WithFormatter renders the values of type t as the text returned by f, on
+// the line of their name like Order(main.OrderID) #1234. Formatters take
+// precedence over the rendering of Dumpables, errors and Stringers, but
+// not over handlers.
+func WithFormatter(t reflect.Type, f Formatter) Option {
+	return func(d *Dumper) {
+		if d.formatters == nil {
+			d.formatters = make(map[reflect.Type]Formatter)
+		}
+		d.formatters[t] = f
+	}
+}
+
+// formatter returns the formatter of the values of type t, if any.
+func (d *Dumper) formatter(t reflect.Type) Formatter {
+	if f := d.formatters[t]; f != nil {
+		return f
+	}
+	if m := formatters.Load(); m != nil {
+		return (*m)[t]
+	}
+	return nil
+}
+
+// hasFormatters reports whether the Dumper may use formatters.
+func (d *Dumper) hasFormatters() bool {
+	return len(d.formatters) > 0 || formatters.Load() != nil
+}
+
+// formatted returns the text val is rendered as by its formatter, if any.
+// Formatters panicking are reported as such.
+func (v *variable) formatted(val reflect.Value) (s string, ok bool) {
+	f := v.d.formatter(val.Type())
+	if f == nil || !val.CanInterface() {
+		return "", false
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			s, ok = "<formatter panicked>", true
+		}
+	}()
+	return f(val.Interface()), true
+}
+
+// formattedNode renders n as the text of its formatter if its value val has
+// one.
+func (v *variable) formattedNode(val reflect.Value, n *Node) bool {
+	s, ok := v.formatted(val)
+	if !ok {
+		return false
+	}
+	n.Leaf = true
+	n.Value = s
+	v.open(n)
+	return true
+}
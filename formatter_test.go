@@ -0,0 +1,51 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type orderID struct {
+	shard, seq int
+}
+
+func TestFormatters(t *testing.T) {
+	defer formatters.Store(nil)
+
+	type order struct {
+		ID    orderID
+		Items []orderID
+	}
+	v := order{orderID{1, 2}, []orderID{{3, 4}}}
+	idType := reflect.TypeOf(orderID{})
+	RegisterFormatter(idType, func(v interface{}) string {
+		id := v.(orderID)
+		return "#" + strconv.Itoa(id.shard) + "-" + strconv.Itoa(id.seq)
+	})
+
+	want := `(godump.order)
+  ID(godump.orderID) #1-2
+  Items([]godump.orderID)
+    0(godump.orderID) #3-4
+`
+	if got := Sdump(v); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	d := NewDumper(WithFormatter(idType, func(interface{}) string { return "id" }), WithInline(80), WithHeader(true))
+	want = "godump/21 opts=formatters,header,inline=80\n(godump.order) {ID:id, Items:{id}}\n"
+	if got := d.Sdump(v); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	d = NewDumper(WithFormatter(idType, func(interface{}) string { panic("boom") }))
+	if got := d.Sdump(v.ID); !strings.Contains(got, "<formatter panicked>") {
+		t.Errorf("panic not reported:\n%s", got)
+	}
+}
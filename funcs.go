@@ -0,0 +1,56 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// WithFuncNames renders funcs by whether they are set and, if so, the name
+// of the function, qualified by its package name, rather than by their
+// address:
+//
+//	OnDone(func(error)) set main.(*Server).finish-fm
+//	OnRetry(func() bool) nil
+//
+// This keeps dumps of options structs and middleware chains readable and
+// stable between runs. Closures are named after the function they are
+// defined in, e.g. main.run.func1.
+func WithFuncNames(enable bool) Option {
+	return func(d *Dumper) {
+		d.funcNames = enable
+	}
+}
+
+// WithOmitNilFuncs leaves struct fields out of dumps if they hold nil funcs,
+// e.g. the unset callbacks of options structs.
+func WithOmitNilFuncs(omit bool) Option {
+	return func(d *Dumper) {
+		d.omitNilFuncs = omit
+	}
+}
+
+// funcSummary returns the rendering of the func val by WithFuncNames.
+func funcSummary(val reflect.Value) string {
+	if val.IsNil() {
+		return "nil"
+	}
+	f := runtime.FuncForPC(val.Pointer())
+	if f == nil {
+		return "set"
+	}
+	name := f.Name()
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	return "set " + name
+}
+
+// nilFunc reports whether val is a nil func.
+func nilFunc(val reflect.Value) bool {
+	return val.Kind() == reflect.Func && val.IsNil()
+}
@@ -0,0 +1,58 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"strings"
+	"testing"
+)
+
+type callbacks struct {
+	Name    string
+	OnDone  func(error)
+	OnRetry func() int
+	Format  func(string, ...interface{}) string
+}
+
+func panicOnError(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestFuncNames(t *testing.T) {
+	c := callbacks{Name: "x", OnDone: func(error) {}}
+	tests := []struct {
+		opts []Option
+		want string
+	}{
+		{[]Option{WithFuncNames(true)}, `(godump.callbacks)
+  Name(string) "x"
+  OnDone(func(error)) set godump.TestFuncNames.func1
+  OnRetry(func() int) nil
+  Format(func(string, ...interface {}) string) nil
+`},
+		{[]Option{WithFuncNames(true), WithOmitNilFuncs(true)}, `(godump.callbacks)
+  Name(string) "x"
+  OnDone(func(error)) set godump.TestFuncNames.func1
+`},
+		{[]Option{WithFuncNames(true), WithOmitNilFuncs(true), WithInline(80)},
+			`(godump.callbacks) {Name:"x", OnDone:set godump.TestFuncNames.func1}
+`},
+	}
+	for i, tt := range tests {
+		if got := Sdump(c, tt.opts...); got != tt.want {
+			t.Errorf("%d: got\n%s\nwant\n%s", i, got, tt.want)
+		}
+	}
+
+	r := strings.NewReader("")
+	c = callbacks{OnDone: panicOnError, OnRetry: r.Len}
+	want := `(godump.callbacks) {Name:"", OnDone:set godump.panicOnError, OnRetry:set strings.(*Reader).Len-fm, Format:nil}
+`
+	if got := Sdump(c, WithFuncNames(true), WithInline(200)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,296 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"go/format"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Ptr returns a pointer to v. Sdumpc uses it for pointers to values that
+// cannot be written as &T{...}, like godump.Ptr[int](42).
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// Sdumpc returns v as a Go expression, formatted by gofmt, which evaluates
+// to a value deeply equal to it, for use as the expected data of tests:
+//
+//	&main.User{
+//		Name: "bob",
+//		Age:  godump.Ptr[int](42),
+//		Tags: []string{"a", "b"},
+//	}
+//
+// Types are qualified by their package name. Zero-valued struct fields are
+// left out, map entries are sorted by key, time.Time values are built by
+// time.Date and the values of interfaces of type error by errors.New with
+// their message. Values that cannot be written, like funcs, channels and
+// pointers back to values being written, are written as nil followed by a
+// comment naming them. Values masked by WithRedact or dump:"redact" tags are
+// written as "***REDACTED***" if they are strings and as nil with a comment
+// otherwise. Unexported fields are only written if the field mode includes
+// them, so the expression compiles in their package only. Options are
+// applied as by Dump; the limits and renderers of dumps do not apply.
+func Sdumpc(v interface{}, opts ...Option) string {
+	return std().With(opts...).Sdumpc(v)
+}
+
+// Sdumpc is the Dumper version of the package-level Sdumpc.
+func (d *Dumper) Sdumpc(v interface{}) string {
+	g := &goWriter{v: d.bufferedVariable(nil, ""), visiting: make(map[visit]bool)}
+	val := reflect.ValueOf(v)
+	if !val.IsValid() {
+		return "nil"
+	}
+	g.write(val, "", false, false)
+	src := g.b.String()
+	if b, err := format.Source([]byte("package p\n\nvar _ = " + src + "\n")); err == nil {
+		src = strings.TrimSuffix(strings.TrimPrefix(string(b), "package p\n\nvar _ = "), "\n")
+	}
+	return src
+}
+
+// goWriter writes values as Go expressions.
+type goWriter struct {
+	v *variable
+	b strings.Builder
+
+	// Pointers and maps being written, to stop at cycles
+	visiting map[visit]bool
+}
+
+// write appends the expression of val, found at path, to the buffer.
+// implied tells whether its type is given by the context, as for elements
+// of composite literals, so constants need no conversion; elided whether
+// the type of a composite literal, and the & of a pointer to one, may be
+// left out too.
+func (g *goWriter) write(val reflect.Value, path string, implied, elided bool) {
+	if rule, masked := g.v.redactRule(path, g.v.accessible(val)); masked {
+		g.masked(val, rule)
+		return
+	}
+	g.value(val, path, implied, elided)
+}
+
+// value appends the expression of val like write, without masking it.
+// Map keys are written by it, since only values are masked.
+func (g *goWriter) value(val reflect.Value, path string, implied, elided bool) {
+	val = g.v.accessible(val)
+	t := val.Type()
+	switch {
+	case !val.CanInterface():
+		g.b.WriteString("nil /* unexported " + typeName(t) + " */")
+		return
+	case t == timeType:
+		g.time(val.Interface().(time.Time))
+		return
+	}
+
+	switch val.Kind() {
+	case reflect.Interface:
+		switch {
+		case val.IsNil():
+			g.nilValue(t, implied)
+		case t == errorType:
+			g.b.WriteString("errors.New(" + strconv.Quote(val.Interface().(error).Error()) + ")")
+		default:
+			g.write(val.Elem(), path, false, false)
+		}
+	case reflect.Ptr:
+		p := visitOf(val)
+		switch {
+		case val.IsNil():
+			g.nilValue(t, implied)
+		case g.visiting[p]:
+			g.b.WriteString("nil /* cycle to " + typeName(t) + " */")
+		case isLiteral(t.Elem()):
+			g.visiting[p] = true
+			if !elided {
+				g.b.WriteByte('&')
+			}
+			g.write(val.Elem(), path, elided, elided)
+			delete(g.visiting, p)
+		default:
+			g.visiting[p] = true
+			g.b.WriteString("godump.Ptr[" + typeName(t.Elem()) + "](")
+			g.write(val.Elem(), path, true, false)
+			g.b.WriteByte(')')
+			delete(g.visiting, p)
+		}
+	case reflect.Struct:
+		g.open(t, elided)
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			fv, ok := g.v.structField(val, i)
+			if !ok || !f.IsExported() && g.v.d.fields == ExportedOnly || val.Field(i).IsZero() {
+				continue
+			}
+			g.b.WriteString(f.Name + ": ")
+			if redactTagged(f) {
+				g.masked(fv, tagRule)
+			} else {
+				g.write(fv, joinPath(path, f.Name), true, false)
+			}
+			g.b.WriteString(",\n")
+		}
+		g.b.WriteByte('}')
+	case reflect.Map:
+		p := visitOf(val)
+		switch {
+		case val.IsNil():
+			g.nilValue(t, implied)
+			return
+		case g.visiting[p]:
+			g.b.WriteString("nil /* cycle to " + typeName(t) + " */")
+			return
+		}
+		g.visiting[p] = true
+		g.open(t, elided)
+		keys, values, names := mapEntries(val, g.v.keyName)
+		for i, k := range keys {
+			g.value(k, path, true, true)
+			g.b.WriteString(": ")
+			g.write(values[i], path+"["+names[i]+"]", true, true)
+			g.b.WriteString(",\n")
+		}
+		g.b.WriteByte('}')
+		delete(g.visiting, p)
+	case reflect.Slice, reflect.Array:
+		if val.Kind() == reflect.Slice && val.IsNil() {
+			g.nilValue(t, implied)
+			return
+		}
+		if t.Elem().Kind() == reflect.Uint8 && val.Kind() == reflect.Slice {
+			if b := val.Bytes(); utf8.Valid(b) {
+				g.b.WriteString(typeName(t) + "(" + strconv.Quote(string(b)) + ")")
+				return
+			}
+		}
+		g.open(t, elided)
+		for i := 0; i < val.Len(); i++ {
+			g.write(val.Index(i), path+"["+strconv.Itoa(i)+"]", true, true)
+			g.b.WriteString(",\n")
+		}
+		g.b.WriteByte('}')
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if val.IsNil() {
+			g.nilValue(t, implied)
+			return
+		}
+		g.b.WriteString("nil /* " + typeName(t) + " */")
+	default:
+		g.constant(val, implied)
+	}
+}
+
+// masked writes the value val masked by rule: strings as ***REDACTED***,
+// other values as nil followed by a comment, like values that cannot be
+// written.
+func (g *goWriter) masked(val reflect.Value, rule string) {
+	switch {
+	case rule == ignoredRule:
+		g.b.WriteString("nil /* ignored */")
+	case val.Kind() == reflect.String:
+		g.b.WriteString(strconv.Quote(redacted))
+	default:
+		g.b.WriteString("nil /* redacted */")
+	}
+}
+
+// isLiteral reports whether values of type t are written as composite
+// literals, whose address may be taken.
+func isLiteral(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Uint8
+	case reflect.Struct, reflect.Map, reflect.Array:
+		return t != timeType
+	}
+	return false
+}
+
+// open starts the composite literal of type t, leaving out the type if it
+// is elided.
+func (g *goWriter) open(t reflect.Type, elided bool) {
+	if !elided {
+		g.b.WriteString(typeName(t))
+	}
+	g.b.WriteString("{\n")
+}
+
+// nilValue writes the nil value of type t.
+func (g *goWriter) nilValue(t reflect.Type, implied bool) {
+	if implied {
+		g.b.WriteString("nil")
+		return
+	}
+	g.b.WriteString("(" + typeName(t) + ")(nil)")
+}
+
+// constant writes the boolean, number or string val, converted to its type
+// unless it is implied or the default type of the constant.
+func (g *goWriter) constant(val reflect.Value, implied bool) {
+	var s string
+	def := ""
+	switch val.Kind() {
+	case reflect.Bool:
+		s, def = strconv.FormatBool(val.Bool()), "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s, def = strconv.FormatInt(val.Int(), 10), "int"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		s = strconv.FormatUint(val.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		s, def = goFloat(val.Float(), val.Type().Bits()), "float64"
+	case reflect.Complex64, reflect.Complex128:
+		c := val.Complex()
+		bits := val.Type().Bits() / 2
+		s, def = "complex("+goFloat(real(c), bits)+", "+goFloat(imag(c), bits)+")", "complex128"
+	case reflect.String:
+		s, def = strconv.Quote(val.String()), "string"
+	}
+	if implied || val.Type().String() == def {
+		g.b.WriteString(s)
+		return
+	}
+	g.b.WriteString(typeName(val.Type()) + "(" + s + ")")
+}
+
+// goFloat returns f as a Go expression, a floating-point literal unless it
+// is not finite.
+func goFloat(f float64, bits int) string {
+	switch {
+	case math.IsNaN(f):
+		return "math.NaN()"
+	case math.IsInf(f, 1):
+		return "math.Inf(1)"
+	case math.IsInf(f, -1):
+		return "math.Inf(-1)"
+	}
+	s := strconv.FormatFloat(f, 'g', -1, bits)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+// time writes t as a call of time.Date.
+func (g *goWriter) time(t time.Time) {
+	loc := "time.Local"
+	switch name, offset := t.Zone(); {
+	case t.Location() == time.UTC:
+		loc = "time.UTC"
+	case t.Location() != time.Local:
+		loc = "time.FixedZone(" + strconv.Quote(name) + ", " + strconv.Itoa(offset) + ")"
+	}
+	g.b.WriteString("time.Date(" + strconv.Itoa(t.Year()) + ", time." + t.Month().String() + ", " +
+		strconv.Itoa(t.Day()) + ", " + strconv.Itoa(t.Hour()) + ", " + strconv.Itoa(t.Minute()) + ", " +
+		strconv.Itoa(t.Second()) + ", " + strconv.Itoa(t.Nanosecond()) + ", " + loc + ")")
+}
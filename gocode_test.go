@@ -0,0 +1,146 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGoCode(t *testing.T) {
+	type item struct {
+		SKU   string
+		Price float32
+	}
+	type order struct {
+		ID      int64
+		Note    *string
+		Items   []*item
+		Counts  map[string]uint8
+		Raw     []byte
+		Created time.Time
+		Timeout time.Duration
+		Err     error
+		Ratio   float64
+		Next    *order
+		Done    func()
+	}
+	note := "gift"
+	o := &order{
+		ID:      7,
+		Note:    &note,
+		Items:   []*item{{"a-1", 2.5}, {SKU: "b-2"}},
+		Counts:  map[string]uint8{"b": 2, "a": 1},
+		Raw:     []byte("hi"),
+		Created: time.Date(2024, time.March, 1, 12, 0, 0, 5, time.UTC),
+		Timeout: 90 * time.Second,
+		Err:     errors.New("out of stock"),
+		Ratio:   math.Inf(-1),
+		Done:    func() {},
+	}
+	o.Next = o
+	want := `&godump.order{
+	ID:   7,
+	Note: godump.Ptr[string]("gift"),
+	Items: []*godump.item{
+		{
+			SKU:   "a-1",
+			Price: 2.5,
+		},
+		{
+			SKU: "b-2",
+		},
+	},
+	Counts: map[string]uint8{
+		"a": 1,
+		"b": 2,
+	},
+	Raw:     []uint8("hi"),
+	Created: time.Date(2024, time.March, 1, 12, 0, 0, 5, time.UTC),
+	Timeout: 90000000000,
+	Err:     errors.New("out of stock"),
+	Ratio:   math.Inf(-1),
+	Next:    nil, /* cycle to *godump.order */
+	Done:    nil, /* func() */
+}`
+	if got := Sdumpc(o); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{nil, "nil"},
+		{42, "42"},
+		{int8(-1), "int8(-1)"},
+		{uint(3), "uint(3)"},
+		{1.0, "1.0"},
+		{float32(0.1), "float32(0.1)"},
+		{complex(1, -2), "complex(1.0, -2.0)"},
+		{"a\"b", `"a\"b"`},
+		{time.Second, "time.Duration(1000000000)"},
+		{[]int(nil), "([]int)(nil)"},
+		{(*int)(nil), "(*int)(nil)"},
+		{[]interface{}{1, "x", int64(2), nil}, "[]interface{}{\n\t1,\n\t\"x\",\n\tint64(2),\n\tnil,\n}"},
+		{[2][]int{{1}, {}}, "[2][]int{\n\t{\n\t\t1,\n\t},\n\t{},\n}"},
+		{[]byte{0xff}, "[]uint8{\n\t255,\n}"},
+		{Ptr([]byte("x")), `godump.Ptr[[]uint8]([]uint8("x"))`},
+	}
+	for _, tt := range tests {
+		if got := Sdumpc(tt.v); got != tt.want {
+			t.Errorf("Sdumpc(%#v) = %s, want %s", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestPtr(t *testing.T) {
+	p := Ptr(42)
+	if *p != 42 {
+		t.Errorf("*Ptr(42) = %d", *p)
+	}
+	if q := Ptr(42); p == q {
+		t.Error("Ptr returned the same pointer twice")
+	}
+}
+
+func TestGoCodeRedact(t *testing.T) {
+	type login struct {
+		User     string
+		Password string
+		PIN      int
+		Headers  map[string]string
+		Session  string `dump:"redact"`
+	}
+	v := login{"bob", "hunter2", 1234, map[string]string{"Accept": "*/*", "X-Auth-Token": "t0k3n"}, "s3ss10n"}
+	pin := RedactRule{"pin", func(path string, _ reflect.Value) bool { return path == "PIN" }}
+	want := `godump.login{
+	User:     "bob",
+	Password: "***REDACTED***",
+	PIN:      nil, /* redacted */
+	Headers: map[string]string{
+		"Accept":       "*/*",
+		"X-Auth-Token": "***REDACTED***",
+	},
+	Session: "***REDACTED***",
+}`
+	if got := Sdumpc(v, WithRedact(RedactNames(), pin)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGoCodeMaps(t *testing.T) {
+	m := map[string]interface{}{}
+	m["self"] = m
+	if got, want := Sdumpc(m), "map[string]interface{}{\n\t\"self\": nil, /* cycle to map[string]interface {} */\n}"; got != want {
+		t.Errorf("cycle: got %q, want %q", got, want)
+	}
+	if got, want := Sdumpc(map[float64]int{math.NaN(): 1}), "map[float64]int{\n\tmath.NaN(): 1,\n}"; got != want {
+		t.Errorf("NaN key: got %q, want %q", got, want)
+	}
+}
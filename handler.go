@@ -0,0 +1,115 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"maps"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Handler renders values of a registered type. The line naming the value is
+// written before the Handler is called; the Handler writes what follows
+// through c, typically a mix of its own lines and values dumped as usual.
+type Handler func(c *Context, val reflect.Value)
+
+// WithHandler registers h for the values of type t.
+func WithHandler(t reflect.Type, h Handler) Option {
+	return func(d *Dumper) {
+		if d.handlers == nil {
+			d.handlers = make(map[reflect.Type]Handler)
+		}
+		d.handlers[t] = h
+	}
+}
+
+var (
+	// Kind handlers registered by RegisterKindHandler, replaced as a whole
+	kindHandlersMu sync.Mutex
+	kindHandlers   atomic.Pointer[map[reflect.Kind]Handler]
+)
+
+// RegisterKindHandler makes all Dumpers render the values of kind k with h,
+// e.g. to sample the elements of all slices or render all maps as tables,
+// in place of the standard rendering of the kind. Values rendered in their
+// own way, e.g. nil values, errors, times, or values beyond the depth
+// limit, are still rendered as usual. It is meant to be called at init
+// time. Kind handlers set by WithKindHandler take precedence.
+func RegisterKindHandler(k reflect.Kind, h Handler) {
+	kindHandlersMu.Lock()
+	defer kindHandlersMu.Unlock()
+	m := make(map[reflect.Kind]Handler)
+	if old := kindHandlers.Load(); old != nil {
+		m = maps.Clone(*old)
+	}
+	m[k] = h
+	kindHandlers.Store(&m)
+}
+
+// WithKindHandler renders the values of kind k with h, as by
+// RegisterKindHandler. Handlers of their type take precedence.
+func WithKindHandler(k reflect.Kind, h Handler) Option {
+	return func(d *Dumper) {
+		if d.kindHandlers == nil {
+			d.kindHandlers = make(map[reflect.Kind]Handler)
+		}
+		d.kindHandlers[k] = h
+	}
+}
+
+// kindHandler returns the handler of the values of kind k, if any.
+func (d *Dumper) kindHandler(k reflect.Kind) Handler {
+	if h := d.kindHandlers[k]; h != nil {
+		return h
+	}
+	if m := kindHandlers.Load(); m != nil {
+		return (*m)[k]
+	}
+	return nil
+}
+
+// hasHandlers reports whether the Dumper may use handlers.
+func (d *Dumper) hasHandlers() bool {
+	return len(d.handlers) > 0 || len(d.kindHandlers) > 0 || kindHandlers.Load() != nil
+}
+
+// Context gives a Handler access to the dump of the value it renders.
+type Context struct {
+	v *variable
+	n *Node
+	w *Writer
+}
+
+// Path returns the path of the value being rendered.
+func (c *Context) Path() string {
+	return c.n.Path
+}
+
+// Writer returns a Writer for lines one level below the value.
+func (c *Context) Writer() *Writer {
+	return c.w
+}
+
+// Dump dumps child, usually part of the value, as its child named name,
+// with the standard traversal. A reflect.Value child is dumped as the
+// value it holds, so values reached through unexported fields need not be
+// interfaced.
+func (c *Context) Dump(child interface{}, name string) {
+	c.w.Flush()
+	val, ok := child.(reflect.Value)
+	if !ok {
+		val = reflect.ValueOf(child)
+	}
+	c.v.dump(val, name, joinPath(c.n.Path, name))
+}
+
+// handlerNode renders n, whose value is val, with h.
+func (v *variable) handlerNode(h Handler, val reflect.Value, n *Node) {
+	v.open(n)
+	c := &Context{v: v, n: n, w: v.newWriter(n)}
+	h(c, val)
+	c.w.Flush()
+}
@@ -0,0 +1,65 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type request struct {
+	Method string
+	Body   []int
+}
+
+func TestHandler(t *testing.T) {
+	d := NewDumper(WithHandler(reflect.TypeOf(request{}), func(c *Context, val reflect.Value) {
+		c.Writer().Printf("%s %s\n", val.Field(0).String(), c.Path())
+		c.Dump(val.Field(1), "Body")
+	}))
+	v := map[string]request{"r": {"GET", []int{1}}}
+	want := "(map[string]godump.request)\n" +
+		"  r(godump.request)\n" +
+		"    GET [r]\n" +
+		"    Body([]int)\n" +
+		"      0(int) 1\n"
+	if got := d.Sdump(v); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestKindHandler(t *testing.T) {
+	// Samples every other element of slices.
+	every2nd := func(c *Context, val reflect.Value) {
+		for i := 0; i < val.Len(); i += 2 {
+			c.Dump(val.Index(i), strconv.Itoa(i))
+		}
+	}
+	v := request{"GET", []int{1, 2, 3}}
+	want := "(godump.request)\n" +
+		"  Method(string) \"GET\"\n" +
+		"  Body([]int)\n" +
+		"    0(int) 1\n" +
+		"    2(int) 3\n"
+	if got := Sdump(v, WithKindHandler(reflect.Slice, every2nd), WithInline(80)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	defer kindHandlers.Store(nil)
+	RegisterKindHandler(reflect.String, func(c *Context, val reflect.Value) {
+		c.Writer().Printf("%d bytes\n", val.Len())
+	})
+	want = "(godump.request)\n" +
+		"  Method(string)\n" +
+		"    3 bytes\n" +
+		"  Body([]int)\n" +
+		"    0(int) 1\n" +
+		"    1(int) 2\n" +
+		"    2(int) 3\n"
+	if got := Sdump(v); got != want {
+		t.Errorf("registered: got\n%s\nwant\n%s", got, want)
+	}
+}
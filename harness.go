@@ -0,0 +1,285 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Harness records labeled dumps during a test run into a bundle and
+// compares it with a baseline bundle committed with the tests, like a
+// snapshot test of a whole request flow:
+//
+//	h := godump.NewHarness()
+//	h.Ignore("*", "*.CreatedAt")
+//	h.Record("request", req)
+//	h.Record("response", resp)
+//	if err := h.Verify("testdata/checkout.dump", *update); err != nil {
+//		t.Fatal(err)
+//	}
+//
+// Harnesses are safe for concurrent use.
+type Harness struct {
+	d *Dumper
+
+	mu     sync.Mutex
+	ignore []ignoreRule
+	bundle Bundle
+}
+
+// ignoreRule selects the values left out of the dumps with matching labels.
+type ignoreRule struct {
+	label, path string
+}
+
+// NewHarness returns a Harness recording dumps configured by opts. Dumps
+// are recorded without markers, which delimit them in the bundle.
+func NewHarness(opts ...Option) *Harness {
+	return &Harness{d: std().With(opts...).With(WithMarkers(false))}
+}
+
+// Ignore masks the values whose path matches the pattern path, in the dumps
+// recorded from now on whose label matches the pattern label, e.g. time
+// stamps or generated IDs differing between runs. Patterns are matched by
+// path.Match, so * matches any part of a path, dots included. The values
+// are rendered as <ignored>.
+func (h *Harness) Ignore(label, path string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ignore = append(h.ignore, ignoreRule{label, path})
+}
+
+// Record adds the dump of v, labeled label, to the bundle. Labels recorded
+// before are suffixed with #2, #3 and so on.
+func (h *Harness) Record(label string, v interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	unique := label
+	for i := 2; ; i++ {
+		if _, ok := h.bundle.lookup(unique); !ok {
+			break
+		}
+		unique = label + " #" + strconv.Itoa(i)
+	}
+
+	var rules []string
+	for _, r := range h.ignore {
+		if ok, _ := path.Match(r.label, label); ok {
+			rules = append(rules, r.path)
+		}
+	}
+	d := h.d
+	if len(rules) > 0 {
+		d = d.With(func(d *Dumper) {
+			d.ignored = func(p string) bool {
+				for _, r := range rules {
+					if ok, _ := path.Match(r, p); ok {
+						return true
+					}
+				}
+				return false
+			}
+		})
+	}
+	dump := d.Sdump(v)
+	if !strings.HasSuffix(dump, "\n") {
+		dump += "\n"
+	}
+	h.bundle = append(h.bundle, BundleEntry{unique, dump})
+}
+
+// Bundle returns the dumps recorded so far.
+func (h *Harness) Bundle() Bundle {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append(Bundle(nil), h.bundle...)
+}
+
+// Compare returns the differences between the dumps recorded so far and
+// those of baseline, by label, in the order of the recorded dumps followed
+// by those missing from them.
+func (h *Harness) Compare(baseline Bundle) []LabelDiff {
+	bundle := h.Bundle()
+	var diffs []LabelDiff
+	for _, e := range bundle {
+		want, ok := baseline.lookup(e.Label)
+		switch {
+		case !ok:
+			diffs = append(diffs, LabelDiff{e.Label, "not in the baseline\n"})
+		case want != e.Dump:
+			diffs = append(diffs, LabelDiff{e.Label, diffLines(want, e.Dump)})
+		}
+	}
+	for _, e := range baseline {
+		if _, ok := bundle.lookup(e.Label); !ok {
+			diffs = append(diffs, LabelDiff{e.Label, "not recorded\n"})
+		}
+	}
+	return diffs
+}
+
+// Verify compares the dumps recorded so far with the baseline bundle in the
+// file at path, returning an error listing the differences. If update is
+// true, or the file does not exist, the bundle is written to the file
+// instead, making it the baseline.
+func (h *Harness) Verify(path string, update bool) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) || err == nil && update {
+		if f != nil {
+			f.Close()
+		}
+		return h.save(path)
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	baseline, err := ReadBundle(f)
+	if err != nil {
+		return fmt.Errorf("godump: %s: %v", path, err)
+	}
+	diffs := h.Compare(baseline)
+	if len(diffs) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "godump: %d dumps differ from %s:", len(diffs), path)
+	for _, d := range diffs {
+		b.WriteString("\n" + strings.TrimSuffix(d.String(), "\n"))
+	}
+	return errors.New(b.String())
+}
+
+func (h *Harness) save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	_, err = h.Bundle().WriteTo(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// LabelDiff is the difference between the dumps labeled Label of a bundle
+// and its baseline: the lines removed from the baseline prefixed by - and
+// those added by +, or a note if one of them is missing.
+type LabelDiff struct {
+	Label string
+	Diff  string
+}
+
+func (d LabelDiff) String() string {
+	return strconv.Quote(d.Label) + ":\n" + d.Diff
+}
+
+// Bundle is a sequence of labeled dumps. In files, every dump is enclosed
+// in marker lines naming its label:
+//
+//	===== BEGIN dump "request" =====
+//	(*http.Request)
+//	  ...
+//	===== END dump "request" =====
+type Bundle []BundleEntry
+
+// BundleEntry is a dump of a Bundle.
+type BundleEntry struct {
+	Label string
+	Dump  string
+}
+
+// Dump returns the dump labeled label, or an empty string if there is none.
+func (b Bundle) Dump(label string) string {
+	dump, _ := b.lookup(label)
+	return dump
+}
+
+func (b Bundle) lookup(label string) (string, bool) {
+	for _, e := range b {
+		if e.Label == label {
+			return e.Dump, true
+		}
+	}
+	return "", false
+}
+
+// WriteTo writes the bundle to w in the format read by ReadBundle.
+func (b Bundle) WriteTo(w io.Writer) (int64, error) {
+	var n int64
+	for _, e := range b {
+		label := strconv.Quote(e.Label)
+		m, err := io.WriteString(w, markerPrefix+"BEGIN dump "+label+" =====\n"+e.Dump+
+			markerPrefix+"END dump "+label+" =====\n")
+		n += int64(m)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// ReadBundle reads a bundle written by Bundle.WriteTo.
+func ReadBundle(r io.Reader) (Bundle, error) {
+	var b Bundle
+	var cur *BundleEntry
+	var dump strings.Builder
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<30)
+	for line := 1; s.Scan(); line++ {
+		text := s.Text()
+		if label, ok := bundleMarker(text, "BEGIN"); ok {
+			if cur != nil {
+				return nil, fmt.Errorf("line %d: dump %q not ended", line, cur.Label)
+			}
+			cur = &BundleEntry{Label: label}
+			continue
+		}
+		if label, ok := bundleMarker(text, "END"); ok {
+			if cur == nil || label != cur.Label {
+				return nil, fmt.Errorf("line %d: end of dump %q not begun", line, label)
+			}
+			cur.Dump = dump.String()
+			b = append(b, *cur)
+			cur = nil
+			dump.Reset()
+			continue
+		}
+		if cur == nil {
+			return nil, fmt.Errorf("line %d: text outside of dumps", line)
+		}
+		dump.WriteString(text + "\n")
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if cur != nil {
+		return nil, fmt.Errorf("dump %q not ended", cur.Label)
+	}
+	return b, nil
+}
+
+// bundleMarker returns the label of the marker line of a bundle of kind
+// what, BEGIN or END.
+func bundleMarker(line, what string) (string, bool) {
+	rest, ok := strings.CutPrefix(line, markerPrefix+what+" dump ")
+	if !ok {
+		return "", false
+	}
+	rest, ok = strings.CutSuffix(rest, " =====")
+	if !ok {
+		return "", false
+	}
+	label, err := strconv.Unquote(rest)
+	return label, err == nil
+}
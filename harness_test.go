@@ -0,0 +1,74 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHarness(t *testing.T) {
+	type order struct {
+		ID    int
+		Items []string
+		Stamp int64
+	}
+	record := func(items ...string) *Harness {
+		h := NewHarness(WithMarkers(true))
+		h.Ignore("order*", "Stamp")
+		h.Record("order", order{1, items, 1234})
+		h.Record("order", order{2, nil, 5678})
+		h.Record("total", len(items))
+		return h
+	}
+
+	file := filepath.Join(t.TempDir(), "flow.dump")
+	if err := record("book").Verify(file, false); err != nil {
+		t.Fatalf("creating the baseline: %v", err)
+	}
+	if err := record("book").Verify(file, false); err != nil {
+		t.Errorf("same run: %v", err)
+	}
+
+	err := record("book", "pen").Verify(file, false)
+	want := `godump: 2 dumps differ from ` + file + `:
+"order":
++     1(string) "pen"
+"total":
+- (int) 1
++ (int) 2`
+	if err == nil || err.Error() != want {
+		t.Errorf("got %v, want\n%s", err, want)
+	}
+
+	h := record("book")
+	b := h.Bundle()
+	if got := b.Dump("order #2"); !strings.Contains(got, "Stamp(int64) <ignored>") {
+		t.Errorf("second order:\n%s", got)
+	}
+	var out strings.Builder
+	b.WriteTo(&out)
+	read, err := ReadBundle(strings.NewReader(out.String()))
+	if err != nil || !reflect.DeepEqual(read, b) {
+		t.Errorf("read back %v, %v\n%s", read, err, out.String())
+	}
+
+	diffs := h.Compare(append(b[:1:1], BundleEntry{"gone", "(int) 0\n"}))
+	if len(diffs) != 3 || diffs[0].Label != "order #2" || diffs[2].Diff != "not recorded\n" {
+		t.Errorf("got %v", diffs)
+	}
+
+	for _, bad := range []string{
+		"(int) 1\n",
+		"===== BEGIN dump \"a\" =====\n(int) 1\n",
+		"===== END dump \"a\" =====\n",
+	} {
+		if _, err := ReadBundle(strings.NewReader(bad)); err == nil {
+			t.Errorf("%q read", bad)
+		}
+	}
+}
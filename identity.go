@@ -0,0 +1,50 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import "reflect"
+
+// WithIdentity sets the function extracting the identity of values, e.g.
+// the primary key of a database entity. Whenever a pointer or struct is
+// summarized rather than dumped, because of the depth limit or a pointer
+// policy, and id reports an identity for it, or for the value it points
+// to, it is shown as its type name and identity, like User#42.
+func WithIdentity(id func(v reflect.Value) (string, bool)) Option {
+	return func(d *Dumper) {
+		d.identity = id
+	}
+}
+
+// identify returns the identity of val, following pointers and interfaces
+// until the identity function reports one.
+func (v *variable) identify(val reflect.Value) (string, bool) {
+	if v.d.identity == nil {
+		return "", false
+	}
+	for val.IsValid() {
+		if val.CanInterface() {
+			if id, ok := v.d.identity(val); ok {
+				return shortTypeName(val.Type()) + "#" + id, true
+			}
+		}
+		if val.Kind() != reflect.Ptr && val.Kind() != reflect.Interface || val.IsNil() {
+			break
+		}
+		val = val.Elem()
+	}
+	return "", false
+}
+
+// shortTypeName returns the name of t without its package, or the literal
+// of t if it is unnamed.
+func shortTypeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr && t.Name() == "" {
+		t = t.Elem()
+	}
+	if t.Name() == "" {
+		return typeName(t)
+	}
+	return cleanTypeName(t.Name())
+}
@@ -0,0 +1,38 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func userID(v reflect.Value) (string, bool) {
+	if u, ok := v.Interface().(user); ok {
+		return strconv.Itoa(u.ID), true
+	}
+	return "", false
+}
+
+func TestIdentity(t *testing.T) {
+	u := &user{ID: 8, Manager: &user{ID: 7}}
+	want := "(*godump.user)\n" +
+		"  (godump.user)\n" +
+		"    ID(int) 8\n" +
+		"    Manager(*godump.user) -> user#7\n" +
+		"    Team(*godump.team) nil\n"
+	if got := Sdump(u, WithFollowPointers(1), WithIdentity(userID)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	d := NewDumper(WithIdentity(userID))
+	d.maxDepth = 1
+	want = "([]godump.user)\n" +
+		"  0(godump.user) user#8 ...(max depth reached)\n"
+	if got := d.Sdump([]user{*u}); got != want {
+		t.Errorf("max depth: got\n%s\nwant\n%s", got, want)
+	}
+}
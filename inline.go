@@ -0,0 +1,271 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// WithInline renders arrays, slices, maps, pointers and structs on a single
+// line, like {A:1, B:"x"}, when the whole line fits in width bytes. Larger
+// values are expanded over several lines as usual. Zero disables inlining.
+func WithInline(width int) Option {
+	return func(d *Dumper) {
+		d.inlineWidth = width
+	}
+}
+
+// WithInlinePointers renders pointers to booleans, numbers and strings on a
+// single line, like Age(*int) &42, rather than as the pointer followed by
+// the value pointed to. This tightens dumps of models using pointers for
+// optional values. Values with their own rendering, e.g. Dumpables or
+// errors, are still dumped below the pointer.
+func WithInlinePointers(enable bool) Option {
+	return func(d *Dumper) {
+		d.inlinePointers = enable
+	}
+}
+
+// primitivePointer reports whether the pointer val, found at path, points
+// to a primitive value rendered as is.
+func (v *variable) primitivePointer(val reflect.Value, path string) bool {
+	if val.IsNil() || v.d.addresses || v.isShared(val) {
+		return false
+	}
+	elem := val.Elem()
+	switch elem.Kind() {
+	case reflect.Bool, reflect.String, reflect.Complex64, reflect.Complex128,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+	default:
+		return false
+	}
+	if v.d.handlers[elem.Type()] != nil || v.d.kindHandler(elem.Kind()) != nil || v.d.formatter(elem.Type()) != nil {
+		return false
+	}
+	if _, ok := wellKnownText(elem); ok && v.d.wellKnown {
+		return false
+	}
+	if _, ok := dumpable(elem); ok {
+		return false
+	}
+	if _, ok := v.opaqueValue(elem, path); ok {
+		return false
+	}
+	if _, masked := v.redactRule(path, elem); masked {
+		return false
+	}
+	if v.d.errorText && !v.d.fullExpansion {
+		if _, ok := errorText(elem); ok {
+			return false
+		}
+	}
+	if _, p := v.stringerText(elem, nil, true); p != IgnoreStringer {
+		return false
+	}
+	return true
+}
+
+// isComposite reports whether values of kind k are dumped with children.
+func isComposite(k reflect.Kind) bool {
+	switch k {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.Ptr, reflect.Struct:
+		return true
+	}
+	return false
+}
+
+// tryInline returns the single-line form of val, the composite value of n,
+// if the line of n fits in the inline width.
+func (v *variable) tryInline(val reflect.Value, n *Node) (string, bool) {
+	limit := v.d.inlineWidth - len(n.Indent) - len(n.Name) - len(n.Type) - 3
+	if limit <= 0 {
+		return "", false
+	}
+	b, ok := v.inline(nil, val, n.Path, n.Depth, limit)
+	if !ok {
+		return "", false
+	}
+	return string(b), true
+}
+
+// inline appends the single-line form of val, found at depth, to b. It gives
+// up as soon as b grows past limit, or when the value would exceed the depth
+// or element limits, or contains redacted values, values with a recorded
+// source, classified values or fields with a wire format, which are only
+// reported by the expanded form.
+func (v *variable) inline(b []byte, val reflect.Value, path string, depth, limit int) ([]byte, bool) {
+	deref := v.deref
+	v.deref = false
+	if len(b) > limit {
+		return b, false
+	}
+	if _, masked := v.redactRule(path, val); masked && depth > 0 || (v.hasSource(path) || v.classified(path, val)) && depth > 0 {
+		return b, false
+	}
+	if val.Kind() == reflect.Interface && !val.IsNil() {
+		val = val.Elem()
+	}
+	if !val.IsValid() {
+		return append(b, "<invalid>"...), true
+	}
+	if isNil(val) {
+		return append(b, "nil"...), true
+	}
+	if opaque, ok := v.opaqueValue(val, path); ok {
+		return append(b, opaque...), len(b)+len(opaque) <= limit
+	}
+	if s, ok := v.formatted(val); ok && v.d.handlers[val.Type()] == nil {
+		return append(b, s...), len(b)+len(s) <= limit
+	}
+	if s, ok := v.zeroText(val); ok {
+		return append(b, s...), len(b)+len(s) <= limit
+	}
+	if v.d.errorText && !v.d.fullExpansion {
+		recv, ok := val, true
+		if !deref {
+			recv, ok = addrReceiver(val, errorType)
+		}
+		if !ok {
+			return b, false
+		}
+		if s, ok := errorText(recv); ok {
+			return append(b, s...), len(b)+len(s) <= limit
+		}
+	}
+	if v.d.wellKnown {
+		if s, ok := wellKnownText(val); ok {
+			return append(b, s...), len(b)+len(s) <= limit
+		}
+	}
+	if s, ok := v.syncValue(val); ok {
+		return append(b, s...), len(b)+len(s) <= limit
+	}
+	if _, ok := dumpable(val); ok || v.d.handlers[val.Type()] != nil || v.d.kindHandler(val.Kind()) != nil {
+		return b, false
+	}
+	if v.d.describe && isDescriber(val.Type()) {
+		return b, false
+	}
+	switch s, p := v.stringerText(val, nil, deref); p {
+	case StringerOnly:
+		return append(b, s...), len(b)+len(s) <= limit
+	case StringerPlusFields:
+		return b, false
+	}
+	if isComposite(val.Kind()) && hasChildren(val) && v.depthLimit > 0 && depth >= v.depthLimit {
+		return b, false
+	}
+	if _, ok := v.d.typeDepth[val.Type()]; ok && depth > 0 {
+		return b, false
+	}
+
+	ok := true
+	switch val.Kind() {
+	case reflect.Array, reflect.Slice:
+		if v.d.maxElements > 0 && val.Len() > v.d.maxElements {
+			return b, false
+		}
+		slice := val.Kind() == reflect.Slice && val.Len() > 0
+		if slice && v.cycle(val) {
+			return append(b, cycleNote(val)...), true
+		}
+		if slice {
+			v.enter(val)
+		}
+		b = append(b, '{')
+		for i := 0; i < val.Len() && ok; i++ {
+			if i > 0 {
+				b = append(b, ", "...)
+			}
+			b, ok = v.inline(b, val.Index(i), path+"["+strconv.Itoa(i)+"]", depth+1, limit)
+		}
+		b = append(b, '}')
+		if slice {
+			v.leave(val)
+		}
+	case reflect.Map:
+		if v.d.maxElements > 0 && val.Len() > v.d.maxElements {
+			return b, false
+		}
+		if v.cycle(val) {
+			return append(b, cycleNote(val)...), true
+		}
+		b = append(b, '{')
+		keys, values, names := mapEntries(val, v.keyName)
+		for _, name := range names {
+			if v.longKey(name) {
+				return b, false
+			}
+		}
+		v.enter(val)
+		for i, k := range keys {
+			if !ok {
+				break
+			}
+			if i > 0 {
+				b = append(b, ", "...)
+			}
+			b = append(b, v.leafValue(k)...)
+			b = append(b, ':')
+			b, ok = v.inline(b, values[i], path+"["+names[i]+"]", depth+1, limit)
+		}
+		v.leave(val)
+		b = append(b, '}')
+	case reflect.Ptr:
+		if v.d.addresses || v.isShared(val) {
+			return b, false
+		}
+		if v.summarizePointer(val) {
+			b = append(b, v.pointerSummary(val)...)
+			break
+		}
+		if v.cycle(val) {
+			b = append(b, cycleNote(val)...)
+			break
+		}
+		b = append(b, '&')
+		v.pointers++
+		v.enter(val)
+		v.deref = true
+		b, ok = v.inline(b, val.Elem(), path, depth+1, limit)
+		v.leave(val)
+		v.pointers--
+	case reflect.Struct:
+		b = append(b, '{')
+		first := true
+		order := v.fieldOrder(val.Type(), path)
+		for j := 0; j < val.NumField() && ok; j++ {
+			i := j
+			if order != nil {
+				i = order[j]
+			}
+			field := val.Type().Field(i)
+			fv, include := v.structField(val, i)
+			if !include || v.ignoredPath(joinPath(path, field.Name)) {
+				continue
+			}
+			if _, ok := wireFormatOf(field); ok || !v.d.clearTags && redactTagged(field) || v.d.classify && classTag(field) != "" {
+				return b, false
+			}
+			if !first {
+				b = append(b, ", "...)
+			}
+			first = false
+			b = append(b, v.fieldName(field)...)
+			b = append(b, ':')
+			b, ok = v.inline(b, fv, joinPath(path, field.Name), depth+1, limit)
+		}
+		b = append(b, '}')
+	default:
+		if val.Kind() == reflect.String && v.d.maxStringLen > 0 && val.Len() > v.d.maxStringLen {
+			return b, false
+		}
+		b = append(b, v.leafValue(val)...)
+	}
+	return b, ok && len(b) <= limit
+}
@@ -0,0 +1,70 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"strconv"
+	"testing"
+)
+
+type line struct {
+	From, To *S
+	Tags     []string
+}
+
+func TestInline(t *testing.T) {
+	v := line{&S{1, 2}, nil, []string{"a", "b"}}
+	tests := []struct {
+		width int
+		want  string
+	}{
+		{80, `(godump.line) {From:&{A:1, B:2}, To:nil, Tags:{"a", "b"}}` + "\n"},
+		{30, "(godump.line)\n" +
+			"  From(*godump.S) &{A:1, B:2}\n" +
+			"  To(*godump.S) nil\n" +
+			"  Tags([]string) {\"a\", \"b\"}\n"},
+		{10, Sdump(v)},
+	}
+	for _, tt := range tests {
+		if got := NewDumper(WithInline(tt.width)).Sdump(v); got != tt.want {
+			t.Errorf("width %d: got\n%s\nwant\n%s", tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestInlineLimits(t *testing.T) {
+	d := NewDumper(WithInline(80))
+	d.maxElements = 1
+	want := "([]int)\n  0(int) 1\n  ... 1 more element\n"
+	if got := d.Sdump([]int{1, 2}); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+type profile struct {
+	Name  *string
+	Age   *int
+	Admin *bool
+	Email *string
+	Code  *errCode
+}
+
+type errCode int
+
+func (c errCode) Error() string { return "code " + strconv.Itoa(int(c)) }
+
+func TestInlinePointers(t *testing.T) {
+	name, age, code := "ann", 42, errCode(7)
+	v := profile{Name: &name, Age: &age, Code: &code}
+	want := "(godump.profile)\n" +
+		"  Name(*string) &\"ann\"\n" +
+		"  Age(*int) &42\n" +
+		"  Admin(*bool) nil\n" +
+		"  Email(*string) nil\n" +
+		"  Code(*godump.errCode) \"code 7\"\n"
+	if got := Sdump(v, WithInlinePointers(true)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"reflect"
+)
+
+// jsonNode is the JSON form of a node and its children.
+type jsonNode struct {
+	ID       string      `json:"id,omitempty"`
+	Path     string      `json:"path,omitempty"`
+	Name     string      `json:"name,omitempty"`
+	Type     string      `json:"type,omitempty"`
+	Kind     string      `json:"kind,omitempty"`
+	Value    string      `json:"value,omitempty"`
+	Note     string      `json:"note,omitempty"`
+	Classes  []string    `json:"classes,omitempty"`
+	Children []*jsonNode `json:"children,omitempty"`
+}
+
+// WithJSON renders dumps as a JSON tree of nodes, one object per value:
+//
+//	{"type":"main.T","kind":"struct","children":[{"path":"A","name":"A","type":"int","kind":"int","value":"1"}]}
+//
+// Value holds the Go-syntax representation of leaves; nodes without a type
+// stand in for elided values and carry a note. Classified values carry
+// their classes, and the top-level object the correlation ID of the dump,
+// if it has one. The document is written at once, followed by a newline,
+// when the dump is complete. WithJSON(false) restores the default grammar
+// if JSON was selected, and leaves other renderers alone.
+func WithJSON(enable bool) Option {
+	return func(d *Dumper) {
+		if !enable && d.format != "json" {
+			return
+		}
+		d.renderer = textRenderer{}
+		d.treeRender = nil
+		d.style = ""
+		d.format = ""
+		if enable {
+			d.treeRender = renderJSON
+			d.format = "json"
+		}
+	}
+}
+
+func renderJSON(d *Dumper, w io.Writer, root *tree) error {
+	var j interface{}
+	if d.stringTable {
+		it := internJSON(root)
+		it.ID = root.id
+		j = it
+	} else {
+		n := toJSON(root)
+		n.ID = root.id
+		j = n
+	}
+	b, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+func toJSON(t *tree) *jsonNode {
+	j := &jsonNode{
+		Path:    t.Path,
+		Name:    t.Name,
+		Type:    t.Type,
+		Value:   t.Value,
+		Note:    t.Note,
+		Classes: t.Classes,
+	}
+	if t.Type != "" {
+		j.Kind = t.Kind.String()
+	}
+	for _, c := range t.children {
+		j.Children = append(j.Children, toJSON(c))
+	}
+	return j
+}
+
+// WithStringTable makes the JSON tree of WithJSON and DumpBoth refer to
+// its strings by their index in a table, so the type names and field
+// names repeated throughout large dumps are written only once:
+//
+//	{"strings":["","godump.S","struct","A","int","1"],"root":{"type":1,"kind":2,"children":[{"path":3,"name":3,"type":4,"kind":4,"value":5}]}}
+//
+// Index 0 stands for the empty string and is left out like empty strings
+// are without the table.
+func WithStringTable(enable bool) Option {
+	return func(d *Dumper) {
+		d.stringTable = enable
+	}
+}
+
+// internedNode is the JSON form of a node with a string table.
+type internedNode struct {
+	Path     int             `json:"path,omitempty"`
+	Name     int             `json:"name,omitempty"`
+	Type     int             `json:"type,omitempty"`
+	Kind     int             `json:"kind,omitempty"`
+	Value    int             `json:"value,omitempty"`
+	Note     int             `json:"note,omitempty"`
+	Classes  []int           `json:"classes,omitempty"`
+	Children []*internedNode `json:"children,omitempty"`
+}
+
+type internedTree struct {
+	ID      string        `json:"id,omitempty"`
+	Strings []string      `json:"strings"`
+	Root    *internedNode `json:"root"`
+}
+
+func internJSON(root *tree) *internedTree {
+	it := &internedTree{Strings: []string{""}}
+	index := map[string]int{"": 0}
+	intern := func(s string) int {
+		i, ok := index[s]
+		if !ok {
+			i = len(it.Strings)
+			index[s] = i
+			it.Strings = append(it.Strings, s)
+		}
+		return i
+	}
+	var conv func(t *tree) *internedNode
+	conv = func(t *tree) *internedNode {
+		j := toJSON(&tree{Node: t.Node})
+		n := &internedNode{
+			Path:  intern(j.Path),
+			Name:  intern(j.Name),
+			Type:  intern(j.Type),
+			Kind:  intern(j.Kind),
+			Value: intern(j.Value),
+			Note:  intern(j.Note),
+		}
+		for _, c := range j.Classes {
+			n.Classes = append(n.Classes, intern(c))
+		}
+		for _, c := range t.children {
+			n.Children = append(n.Children, conv(c))
+		}
+		return n
+	}
+	it.Root = conv(root)
+	return it
+}
+
+// teeRenderer renders the nodes with two renderers, the second writing
+// to w.
+type teeRenderer struct {
+	a, b Renderer
+	w    io.Writer
+}
+
+func (r teeRenderer) Open(w io.Writer, n *Node) error {
+	err := r.a.Open(w, n)
+	if berr := r.b.Open(r.w, n); err == nil {
+		err = berr
+	}
+	return err
+}
+
+func (r teeRenderer) Close(w io.Writer, n *Node) error {
+	err := r.a.Close(w, n)
+	if berr := r.b.Close(r.w, n); err == nil {
+		err = berr
+	}
+	return err
+}
+
+// DumpBoth writes the dump of v to w as configured and, from the same
+// traversal, its JSON tree as WithJSON renders it to jw, so huge values
+// need not be dumped twice to get both forms.
+func (d *Dumper) DumpBoth(w, jw io.Writer, v interface{}) error {
+	jb := bufio.NewWriter(jw)
+	dump := d.newVariable(w, "")
+	tr := &treeRenderer{d: d, render: renderJSON}
+	dump.renderer = teeRenderer{dump.renderer, tr, jb}
+	dump.begin()
+	tr.id = dump.id
+	dump.root(reflect.ValueOf(v))
+	dump.end()
+	err := dump.w.Flush()
+	if jerr := jb.Flush(); err == nil {
+		err = jerr
+	}
+	if dump.err != nil {
+		err = dump.err
+	}
+	return err
+}
+
+// DumpBoth is the package-level version of Dumper.DumpBoth.
+func DumpBoth(w, jw io.Writer, v interface{}, opts ...Option) error {
+	return std().With(opts...).DumpBoth(w, jw, v)
+}
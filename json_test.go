@@ -0,0 +1,66 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestJSON(t *testing.T) {
+	want := `{"type":"godump.S","kind":"struct","children":[` +
+		`{"path":"A","name":"A","type":"int","kind":"int","value":"1"},` +
+		`{"path":"B","name":"B","type":"int","kind":"int","value":"2"}]}` + "\n"
+	if got := Sdump(S{1, 2}, WithJSON(true)); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestDumpBoth(t *testing.T) {
+	var text, js strings.Builder
+	v := []S{{1, 2}}
+	if err := DumpBoth(&text, &js, v, WithHeader(true)); err != nil {
+		t.Fatal(err)
+	}
+	if want := Sdump(v, WithHeader(true)); text.String() != want {
+		t.Errorf("text: got\n%s\nwant\n%s", text.String(), want)
+	}
+	if want := Sdump(v, WithJSON(true)); js.String() != want {
+		t.Errorf("json: got %s, want %s", js.String(), want)
+	}
+}
+
+func TestStringTable(t *testing.T) {
+	want := `{"strings":["","godump.S","struct","A","int","1","B","2"],"root":{"type":1,"kind":2,"children":[` +
+		`{"path":3,"name":3,"type":4,"kind":4,"value":5},` +
+		`{"path":6,"name":6,"type":4,"kind":4,"value":7}]}}` + "\n"
+	if got := Sdump(S{1, 2}, WithJSON(true), WithStringTable(true)); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	var text, js strings.Builder
+	if err := DumpBoth(&text, &js, S{1, 2}, WithStringTable(true)); err != nil {
+		t.Fatal(err)
+	}
+	if js.String() != want {
+		t.Errorf("DumpBoth: got %s, want %s", js.String(), want)
+	}
+}
+
+func TestJSONDisabled(t *testing.T) {
+	tmpl := template.Must(template.New("").Parse("{{.Name}}={{.Value}}"))
+	want := Sdump(S{1, 2}, WithTemplate(tmpl))
+	for _, opt := range []Option{WithJSON(false), WithSExpr(false)} {
+		if got := Sdump(S{1, 2}, WithTemplate(tmpl), opt); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+	if got, want := Sdump(S{1, 2}, WithJSON(true), WithJSON(false)), Sdump(S{1, 2}); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := Sdump(S{1, 2}, WithSExpr(true), WithSExpr(false)), Sdump(S{1, 2}); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// WithKeyField names the elements of arrays and slices of structs, and of
+// pointers to structs, by the value of their field name rather than by
+// their index:
+//
+//	Users([]main.User)
+//	  ID=42(main.User)
+//	    ID(int) 42
+//
+// Paths use the names too, e.g. Users[ID=42].Name, so redaction rules can
+// select elements by key, and baselines and Diff match elements by key
+// rather than by position. Elements without the field, whose field is not
+// a boolean, number or string, or whose field is masked by its dump:"redact"
+// tag or a redaction rule keep their index, so keys do not give away masked
+// values. An empty name names elements by index; this is the default.
+func WithKeyField(name string) Option {
+	return func(d *Dumper) {
+		d.keyField = name
+	}
+}
+
+// elementName returns the name of the element i of an array or slice, val,
+// found at path.
+func (v *variable) elementName(val reflect.Value, i int, path string) string {
+	if name, ok := v.elementKey(val.Index(i), path+"["+strconv.Itoa(i)+"]"); ok {
+		return name
+	}
+	return strconv.Itoa(i)
+}
+
+// elementKey returns the name of the element elem, found at path by its
+// index, by its key field, if it has one.
+func (v *variable) elementKey(elem reflect.Value, path string) (string, bool) {
+	if v.d.keyField == "" {
+		return "", false
+	}
+	for elem.Kind() == reflect.Interface || elem.Kind() == reflect.Ptr {
+		if elem.IsNil() {
+			return "", false
+		}
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return "", false
+	}
+	f, ok := elem.Type().FieldByName(v.d.keyField)
+	if !ok || redactTagged(f) {
+		return "", false
+	}
+	key, err := elem.FieldByIndexErr(f.Index)
+	if err != nil {
+		return "", false
+	}
+	if _, masked := v.redactRule(joinPath(path, f.Name), key); masked {
+		return "", false
+	}
+	for key.Kind() == reflect.Interface && !key.IsNil() {
+		key = key.Elem()
+	}
+	switch key.Kind() {
+	case reflect.Bool, reflect.String, reflect.Complex64, reflect.Complex128,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return v.d.keyField + "=" + v.keyName(key), true
+	}
+	return "", false
+}
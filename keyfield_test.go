@@ -0,0 +1,81 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKeyField(t *testing.T) {
+	type user struct {
+		ID   int
+		Name string
+	}
+	type team struct {
+		Users  []*user
+		Others []interface{}
+	}
+	v := team{
+		Users:  []*user{{42, "bob"}, nil},
+		Others: []interface{}{user{7, "ann"}, "x"},
+	}
+	secret := RedactRule{"id42", func(path string, _ reflect.Value) bool {
+		return path == "Users[ID=42].Name"
+	}}
+	want := `(godump.team)
+  Users([]*godump.user)
+    ID=42(*godump.user)
+      ID=42(godump.user)
+        ID(int) 42
+        Name(string) ***REDACTED***
+    1(*godump.user) nil
+  Others([]interface {})
+    ID=7(godump.user)
+      ID(int) 7
+      Name(string) "ann"
+    1(string) "x"
+`
+	if got := Sdump(v, WithKeyField("ID"), WithRedact(secret)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	a := []user{{1, "a"}, {2, "b"}, {3, "c"}}
+	b := []user{{3, "c"}, {1, "a"}, {2, "x"}, {4, "d"}}
+	want = "[ID=2].Name: -\"b\" +\"x\"\n" +
+		"[ID=4]: +{ID:4, Name:\"d\"}\n"
+	if got := Diff(a, b, WithKeyField("ID")); got != want {
+		t.Errorf("Diff: got\n%s\nwant\n%s", got, want)
+	}
+	dup := []user{{1, "a"}, {1, "b"}}
+	if got, want := Diff(dup, dup[:1], WithKeyField("ID")), "[1]: -{ID:1, Name:\"b\"}\n"; got != want {
+		t.Errorf("Diff with duplicate keys: got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestKeyFieldRedacted(t *testing.T) {
+	type cred struct {
+		User  string
+		Token string `dump:"redact"`
+	}
+	v := []cred{{"bob", "s3cr3t"}}
+	want := "([]godump.cred)\n" +
+		"  0(godump.cred)\n" +
+		"    User(string) \"bob\"\n" +
+		"    Token(string) ***REDACTED***\n"
+	if got := Sdump(v, WithKeyField("Token")); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got, want := Diff(v, []cred{{"bob", "other"}}, WithKeyField("Token")), "[0].Token: redacted values differ\n"; got != want {
+		t.Errorf("Diff: got %q, want %q", got, want)
+	}
+	// Keys masked by redaction rules are not used either.
+	if got := Sdump([]cred{{"s3cr3t", "x"}}, WithKeyField("User"), WithRedact(RedactRule{"user", func(path string, _ reflect.Value) bool {
+		return path == "[0].User"
+	}})); strings.Contains(got, "s3cr3t") {
+		t.Errorf("masked key field in\n%s", got)
+	}
+}
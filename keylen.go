@@ -0,0 +1,46 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// WithMaxKeyLen shortens map keys longer than n characters to their first
+// n, followed by an ellipsis and annotated with their full length:
+//
+//	https://ex…(int) 1 (key len=32)
+//
+// Paths keep the full keys. The limit applies to the keys only, not to the
+// values. Zero disables it; this is the default.
+func WithMaxKeyLen(n int) Option {
+	return func(d *Dumper) {
+		d.maxKeyLen = n
+	}
+}
+
+// longKey reports whether name exceeds the key length limit.
+func (v *variable) longKey(name string) bool {
+	return v.d.maxKeyLen > 0 && utf8.RuneCountInString(name) > v.d.maxKeyLen
+}
+
+// keyLabel returns name shortened to the key length limit, and sets the
+// note of the next node if it is.
+func (v *variable) keyLabel(name, path string) string {
+	if !v.longKey(name) {
+		return name
+	}
+	l := utf8.RuneCountInString(name)
+	i, r := 0, 0
+	for r < v.d.maxKeyLen {
+		_, size := utf8.DecodeRuneInString(name[i:])
+		i += size
+		r++
+	}
+	v.keyNote = "(key len=" + strconv.Itoa(l) + ")"
+	v.truncated(path, TruncatedKey, l-v.d.maxKeyLen)
+	return name[:i] + "…"
+}
@@ -0,0 +1,36 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMaxKeyLen(t *testing.T) {
+	var got []Truncation
+	m := map[string]int{"https://example.com/abcdefghijkl": 1, "ab": 2, "äöüäöüäöüäöü": 3}
+	want := "godump/21 opts=canonical,header,keylen=10\n" +
+		"(map[string]int)\n" +
+		"  ab(int) 2\n" +
+		"  https://ex…(int) 1 (key len=32)\n" +
+		"  äöüäöüäöüä…(int) 3 (key len=12)\n"
+	if s := Sdump(m, WithMaxKeyLen(10), WithCanonical(true), WithTruncationReport(func(ts []Truncation) { got = ts })); s != want {
+		t.Errorf("got\n%s\nwant\n%s", s, want)
+	}
+	wantTrunc := []Truncation{
+		{"[https://example.com/abcdefghijkl]", TruncatedKey, 22},
+		{"[äöüäöüäöüäöü]", TruncatedKey, 2},
+	}
+	if !reflect.DeepEqual(got, wantTrunc) {
+		t.Errorf("got %v, want %v", got, wantTrunc)
+	}
+
+	long := map[string]int{"https://example.com/abcdefghijkl": 1}
+	want = "(map[string]int)\n  https://ex…(int) 1 (key len=32)\n"
+	if s := Sdump(long, WithMaxKeyLen(10), WithInline(80)); s != want {
+		t.Errorf("inline: got\n%s\nwant\n%s", s, want)
+	}
+}
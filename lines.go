@@ -0,0 +1,75 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"bytes"
+	"iter"
+	"reflect"
+)
+
+// Line is a line of a dump, as iterated by Lines.
+type Line struct {
+	// Nesting level of the value the line belongs to
+	Depth int
+
+	// Location of the value inside the top-level value, e.g. Users[0].Name
+	Path string
+
+	// Type of the value as printed by %T. Lines not rendering a value,
+	// like the header or the lines written by a Dumpable, have none.
+	Type string
+
+	// Text of the line without its newline
+	Text string
+}
+
+// Lines returns an iterator over the lines of the dump of v:
+//
+//	for l := range godump.Lines(v) {
+//		if l.Depth <= 1 {
+//			fmt.Println(l.Text)
+//		}
+//	}
+//
+// The dump is produced while the lines are consumed, so no output is held
+// in memory, and stops when the loop does. Options are applied as by Dump.
+func Lines(v interface{}, opts ...Option) iter.Seq[Line] {
+	return std().With(opts...).Lines(v)
+}
+
+// Lines is the Dumper version of the package-level Lines. With renderers
+// writing the whole dump at once, like JSON, all lines belong to the
+// top-level value.
+func (d *Dumper) Lines(v interface{}) iter.Seq[Line] {
+	return func(yield func(Line) bool) {
+		dump := d.bufferedVariable(nil, "")
+		dump.lines = yield
+		dump.begin()
+		dump.root(reflect.ValueOf(v))
+		dump.end()
+	}
+}
+
+// flushNode writes the finished line of n.
+func (v *variable) flushNode(n *Node) {
+	if v.lines != nil || v.preview != nil {
+		v.at = Line{Depth: n.Depth, Path: n.Path, Type: n.Type}
+	}
+	v.flushLine()
+	v.at = Line{}
+}
+
+// yieldLines passes the lines of b, a chunk of output, to the consumer of
+// Lines until it stops.
+func (v *variable) yieldLines(b []byte) {
+	for len(b) > 0 && !v.stopped {
+		text, rest, _ := bytes.Cut(b, []byte{'\n'})
+		l := v.at
+		l.Text = string(text)
+		v.stopped = !v.lines(l)
+		b = rest
+	}
+}
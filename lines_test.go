@@ -0,0 +1,59 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	type user struct {
+		Name string
+		Tags []string
+	}
+	v := user{"bob", []string{"a", "b"}}
+
+	var texts []string
+	for l := range Lines(v) {
+		texts = append(texts, l.Text+"\n")
+	}
+	if got, want := strings.Join(texts, ""), Sdump(v); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	var got []Line
+	for l := range Lines(v) {
+		if l.Path == "Tags[1]" {
+			break
+		}
+		got = append(got, l)
+	}
+	want := []Line{
+		{0, "", "godump.user", "(godump.user)"},
+		{1, "Name", "string", `  Name(string) "bob"`},
+		{1, "Tags", "[]string", "  Tags([]string)"},
+		{2, "Tags[0]", "string", `    0(string) "a"`},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d lines, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	n := 0
+	for l := range Lines(v, WithHeader(true), WithChecksum(true)) {
+		if n == 0 && !strings.HasPrefix(l.Text, "godump/") || l.Type == "" && n > 0 && !strings.HasPrefix(l.Text, checksumPrefix) {
+			t.Errorf("line %d: %+v", n, l)
+		}
+		n++
+	}
+	if n != 7 {
+		t.Errorf("got %d lines with header and checksum, want 7", n)
+	}
+}
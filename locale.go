@@ -0,0 +1,112 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// locale holds the conventions of a locale for numbers and dates.
+type locale struct {
+	tag     string
+	decimal string // decimal separator
+	group   string // separator of thousands
+	date    string // time layout
+}
+
+// The supported locales, by BCP 47 tag or language.
+var locales = map[string]locale{
+	"en":    {"en", ".", ",", "Jan 2, 2006 3:04:05 PM MST"},
+	"en-GB": {"en-GB", ".", ",", "02/01/2006 15:04:05 MST"},
+	"de":    {"de", ",", ".", "02.01.2006 15:04:05 MST"},
+	"es":    {"es", ",", ".", "02/01/2006 15:04:05 MST"},
+	"fr":    {"fr", ",", " ", "02/01/2006 15:04:05 MST"},
+	"it":    {"it", ",", ".", "02/01/2006 15:04:05 MST"},
+	"ja":    {"ja", ".", ",", "2006/01/02 15:04:05 MST"},
+	"nl":    {"nl", ",", ".", "02-01-2006 15:04:05 MST"},
+	"pl":    {"pl", ",", " ", "02.01.2006 15:04:05 MST"},
+	"pt":    {"pt", ",", ".", "02/01/2006 15:04:05 MST"},
+	"ru":    {"ru", ",", " ", "02.01.2006 15:04:05 MST"},
+	"sv":    {"sv", ",", " ", "2006-01-02 15:04:05 MST"},
+	"zh":    {"zh", ".", ",", "2006/01/02 15:04:05 MST"},
+}
+
+// WithLocale formats numbers, with grouped thousands, and time.Time values
+// by the conventions of the locale with the BCP 47 tag, e.g. "de-DE", for
+// dumps meant for people rather than tools. Tags are matched in full, then
+// by language; the output is left alone for unsupported locales and an
+// empty tag. Canonical output ignores the locale.
+//
+// The conventions are built in, covering widely used locales, so no
+// localization package is needed.
+func WithLocale(tag string) Option {
+	return func(d *Dumper) {
+		d.locale = nil
+		if l, ok := locales[tag]; ok {
+			d.locale = &l
+		} else if l, ok := locales[strings.SplitN(tag, "-", 2)[0]]; ok {
+			d.locale = &l
+		}
+	}
+}
+
+// leaf returns the localized form of the number val.
+func (l *locale) leaf(val reflect.Value) (string, bool) {
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return l.number(strconv.FormatInt(val.Int(), 10)), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return l.number(strconv.FormatUint(val.Uint(), 10)), true
+	case reflect.Float32, reflect.Float64:
+		f := val.Float()
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return strconv.FormatFloat(f, 'g', -1, 64), true
+		}
+		format := byte('f')
+		if a := math.Abs(f); a >= 1e21 || a != 0 && a < 1e-6 {
+			format = 'g'
+		}
+		return l.number(strconv.FormatFloat(f, format, -1, val.Type().Bits())), true
+	}
+	return "", false
+}
+
+// number localizes the decimal number s, as formatted by strconv.
+func (l *locale) number(s string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, rest := s, ""
+	if i := strings.IndexAny(s, ".e"); i >= 0 {
+		intPart, rest = s[:i], s[i:]
+	}
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(l.group)
+		}
+		b.WriteRune(c)
+	}
+	if strings.HasPrefix(rest, ".") {
+		b.WriteString(l.decimal)
+		rest = rest[1:]
+	}
+	b.WriteString(rest)
+	return b.String()
+}
+
+// time returns the localized form of the time.Time val.
+func (l *locale) time(val reflect.Value) (string, bool) {
+	if val.Type() != timeType || !val.CanInterface() {
+		return "", false
+	}
+	return val.Interface().(time.Time).Format(l.date), true
+}
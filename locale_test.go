@@ -0,0 +1,42 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLocale(t *testing.T) {
+	v := struct {
+		N  int
+		F  float64
+		At time.Time
+	}{-1234567, 9876.5, time.Date(2026, 3, 1, 14, 5, 0, 0, time.UTC)}
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"de-DE", "(struct { N int; F float64; At time.Time })\n" +
+			"  N(int) -1.234.567\n" +
+			"  F(float64) 9.876,5\n" +
+			"  At(time.Time) 01.03.2026 14:05:00 UTC\n"},
+		{"en-US", "(struct { N int; F float64; At time.Time })\n" +
+			"  N(int) -1,234,567\n" +
+			"  F(float64) 9,876.5\n" +
+			"  At(time.Time) Mar 1, 2026 2:05:00 PM UTC\n"},
+		{"xx", Sdump(v)},
+	}
+	for _, tt := range tests {
+		if got := Sdump(v, WithLocale(tt.tag)); got != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.tag, got, tt.want)
+		}
+	}
+
+	l := locales["fr"]
+	if got := l.number("1e+21"); got != "1e+21" {
+		t.Errorf("exponent: got %q", got)
+	}
+}
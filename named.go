@@ -0,0 +1,93 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// namedValues holds the values dumped together by DumpNamed, in order.
+type namedValues []namedValue
+
+type namedValue struct {
+	name string
+	val  interface{}
+}
+
+var namedValuesType = reflect.TypeOf(namedValues(nil))
+
+// namedPairs turns alternating names and values into namedValues. Names
+// that are not strings are formatted by fmt; a value missing its name is
+// named !BADKEY.
+func namedPairs(kv []interface{}) namedValues {
+	var nv namedValues
+	for len(kv) > 0 {
+		if len(kv) == 1 {
+			nv = append(nv, namedValue{"!BADKEY", kv[0]})
+			break
+		}
+		name, ok := kv[0].(string)
+		if !ok {
+			name = fmt.Sprint(kv[0])
+		}
+		nv = append(nv, namedValue{name, kv[1]})
+		kv = kv[2:]
+	}
+	return nv
+}
+
+// DumpNamed prints the dumps of several values in one call, each named by
+// the string preceding it, to standard out:
+//
+//	godump.DumpNamed("req", req, "resp", resp)
+//
+// prints
+//
+//	req(*http.Request)
+//	  ...
+//	resp(*http.Response)
+//	  ...
+//
+// The values share the markers, header and checksum of a single dump. To
+// adjust the configuration, use Default().With(opts...).DumpNamed.
+func DumpNamed(kv ...interface{}) {
+	std().DumpNamed(kv...)
+}
+
+// SdumpNamed returns the dumps of several values named as by DumpNamed.
+func SdumpNamed(kv ...interface{}) string {
+	return std().SdumpNamed(kv...)
+}
+
+// DumpNamed is the Dumper version of the package-level DumpNamed.
+func (d *Dumper) DumpNamed(kv ...interface{}) {
+	d.Dump(namedPairs(kv))
+}
+
+// SdumpNamed is the Dumper version of the package-level SdumpNamed.
+func (d *Dumper) SdumpNamed(kv ...interface{}) string {
+	return d.Sdump(namedPairs(kv))
+}
+
+// named dumps the values of nv, each as a top-level value named by its
+// name. Filters apply to every value, their matches named by the name of
+// the value followed by their path.
+func (v *variable) named(nv namedValues) {
+	for _, e := range nv {
+		val := nilInterface(addressable(reflect.ValueOf(e.val)))
+		if v.d.filter == nil {
+			v.dump(val, e.name, e.name)
+			continue
+		}
+		for _, m := range v.d.filter.eval(val) {
+			path := e.name + m.path
+			if m.path != "" && m.path[0] != '[' {
+				path = e.name + "." + m.path
+			}
+			v.dump(m.val, path, path)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import "testing"
+
+func TestDumpNamed(t *testing.T) {
+	req, resp := &S{1, 2}, []int{3}
+	want := "req(*godump.S)\n" +
+		"  req(godump.S)\n" +
+		"    A(int) 1\n" +
+		"    B(int) 2\n" +
+		"resp([]int)\n" +
+		"  0(int) 3\n" +
+		"!BADKEY(string) \"odd\"\n"
+	if got := SdumpNamed("req", req, "resp", resp, "odd"); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	want = "req.A(int) 1\nreq.B(int) 2\nresp[0](int) 3\n"
+	if got := NewDumper(WithFilterExpr("*")).SdumpNamed("req", S{1, 2}, "resp", resp); got != want {
+		t.Errorf("filtered: got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"strings"
+)
+
+// WithOpaqueTypes renders values of the given types as <opaque: T> without
+// looking into them, not even through their Error or String methods. This
+// keeps key material like that of *tls.Conn, and huge runtime-internal
+// structures, out of dumps. Interface values are matched by their dynamic
+// type.
+func WithOpaqueTypes(types ...reflect.Type) Option {
+	return func(d *Dumper) {
+		if d.opaque == nil {
+			d.opaque = make(map[reflect.Type]bool)
+		}
+		for _, t := range types {
+			d.opaque[t] = true
+		}
+	}
+}
+
+// WithRuntimeInternals controls whether values of the runtime and reflect
+// packages, and of the internal packages of the standard library, are
+// dumped in detail when they are found inside the top-level value, e.g.
+// through interfaces holding runtime structures. By default they are
+// rendered as <internal: T>, since their dumps are huge, change between Go
+// releases and are rarely of interest. The top-level value itself, and the
+// value it points to, are always dumped in detail.
+func WithRuntimeInternals(enable bool) Option {
+	return func(d *Dumper) {
+		d.internals = enable
+	}
+}
+
+// opaqueValue returns the rendering of val, found at path, if its type, or
+// the dynamic type of the interfaces holding it, is opaque or internal.
+func (v *variable) opaqueValue(val reflect.Value, path string) (string, bool) {
+	internals := v.d.internals || path == ""
+	if len(v.d.opaque) == 0 && internals {
+		return "", false
+	}
+	for {
+		if v.d.opaque[val.Type()] {
+			return "<opaque: " + typeName(val.Type()) + ">", true
+		}
+		if !internals && isInternal(val.Type()) {
+			return "<internal: " + typeName(val.Type()) + ">", true
+		}
+		if val.Kind() != reflect.Interface || val.IsNil() {
+			return "", false
+		}
+		val = val.Elem()
+	}
+}
+
+// isInternal reports whether t is, or points to, a type of the runtime or
+// reflect packages or of an internal package of the standard library.
+func isInternal(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Name() == "" {
+		return false
+	}
+	path := t.PkgPath()
+	if path == "runtime" || path == "reflect" {
+		return true
+	}
+	// Import paths of the standard library have no dot in their first
+	// element.
+	if first, _, _ := strings.Cut(path, "/"); strings.Contains(first, ".") {
+		return false
+	}
+	return strings.HasPrefix(path, "internal/") || strings.Contains(path, "/internal/")
+}
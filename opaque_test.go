@@ -0,0 +1,63 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type secret struct {
+	Key []byte
+}
+
+func (secret) Error() string { return "leaked" }
+
+func TestOpaqueTypes(t *testing.T) {
+	v := struct {
+		Direct *secret
+		Boxed  interface{}
+	}{&secret{[]byte{1}}, secret{}}
+	d := NewDumper(WithOpaqueTypes(reflect.TypeOf(&secret{}), reflect.TypeOf(secret{})))
+	want := "(struct { Direct *godump.secret; Boxed interface {} })\n" +
+		"  Direct(*godump.secret) <opaque: *godump.secret>\n" +
+		"  Boxed(godump.secret) <opaque: godump.secret>\n"
+	if got := d.Sdump(v); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	d = NewDumper(WithOpaqueTypes(reflect.TypeOf(&secret{})), WithInline(80))
+	want = "([]*godump.secret) {<opaque: *godump.secret>}\n"
+	if got := d.Sdump([]*secret{{}}); got != want {
+		t.Errorf("inline: got %q, want %q", got, want)
+	}
+}
+
+func TestRuntimeInternals(t *testing.T) {
+	type frames struct {
+		Frame runtime.Frame
+		Type  interface{}
+		N     int
+	}
+	v := frames{Type: reflect.TypeOf(0), N: 1}
+	want := "(godump.frames)\n" +
+		"  Frame(runtime.Frame) <internal: runtime.Frame>\n" +
+		"  Type(*reflect.rtype) <internal: *reflect.rtype>\n" +
+		"  N(int) 1\n"
+	if got := Sdump(v); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got := Sdump(v, WithRuntimeInternals(true)); got == want {
+		t.Errorf("internals summarized with WithRuntimeInternals")
+	}
+	if got := Sdump(&runtime.MemStats{}); strings.Contains(got, "<internal") {
+		t.Errorf("top-level value summarized:\n%s", got)
+	}
+	if isInternal(reflect.TypeOf(secret{})) {
+		t.Error("godump.secret is internal")
+	}
+}
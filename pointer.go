@@ -0,0 +1,78 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// PointerPolicy tells whether pointers of a type are followed.
+type PointerPolicy int
+
+const (
+	// Follow dumps the values pointed to, beyond the WithFollowPointers
+	// limit too.
+	Follow PointerPolicy = iota
+
+	// Summarize renders pointers as references to the values pointed to,
+	// like -> User(ID=7), without dumping them.
+	Summarize
+)
+
+// WithFollowPointers makes the Dumper follow at most n pointers along the
+// way from the top-level value to any value, counting the top-level value
+// itself if it is a pointer. Pointers beyond are summarized as references
+// to the values they point to, which keeps dumps of densely linked graphs
+// readable. A negative n follows all pointers; this is the default.
+func WithFollowPointers(n int) Option {
+	return func(d *Dumper) {
+		d.followPointers = n
+	}
+}
+
+// WithPointerPolicy sets the policy for the pointers of type t, overriding
+// the WithFollowPointers limit.
+func WithPointerPolicy(t reflect.Type, p PointerPolicy) Option {
+	return func(d *Dumper) {
+		if d.pointerPolicies == nil {
+			d.pointerPolicies = make(map[reflect.Type]PointerPolicy)
+		}
+		d.pointerPolicies[t] = p
+	}
+}
+
+// summarizePointer reports whether the pointer val is summarized rather
+// than followed.
+func (v *variable) summarizePointer(val reflect.Value) bool {
+	if val.IsNil() {
+		return false
+	}
+	if p, ok := v.d.pointerPolicies[val.Type()]; ok {
+		return p == Summarize
+	}
+	return v.d.followPointers >= 0 && v.pointers >= v.d.followPointers
+}
+
+// pointerSummary returns the reference to the value pointed to by val. The
+// value is identified by its identity, its ID field, if it is a struct with
+// one, or else by its address.
+func (v *variable) pointerSummary(val reflect.Value) string {
+	if id, ok := v.identify(val); ok {
+		return "-> " + id
+	}
+	elem := val.Elem()
+	name := shortTypeName(elem.Type())
+	if elem.Kind() == reflect.Struct {
+		for i := 0; i < elem.NumField(); i++ {
+			f := elem.Type().Field(i)
+			if f.IsExported() && strings.EqualFold(f.Name, "id") && !isComposite(f.Type.Kind()) {
+				return fmt.Sprintf("-> %s(%s=%s)", name, f.Name, leafValue(elem.Field(i)))
+			}
+		}
+	}
+	return fmt.Sprintf("-> %s(%#x)", name, val.Pointer())
+}
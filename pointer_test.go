@@ -0,0 +1,45 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type user struct {
+	ID      int
+	Manager *user
+	Team    *team
+}
+
+type team struct {
+	Name string
+}
+
+func TestFollowPointers(t *testing.T) {
+	boss := &user{ID: 7}
+	u := &user{ID: 8, Manager: boss, Team: &team{"core"}}
+	want := "(*godump.user)\n" +
+		"  (godump.user)\n" +
+		"    ID(int) 8\n" +
+		"    Manager(*godump.user) -> user(ID=7)\n" +
+		"    Team(*godump.team) -> team(" + fmt.Sprintf("%p", u.Team) + ")\n"
+	if got := Sdump(u, WithFollowPointers(1)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	want = "(*godump.user) -> user(ID=8)\n"
+	if got := Sdump(u, WithPointerPolicy(reflect.TypeOf(u), Summarize)); got != want {
+		t.Errorf("summarize: got %q, want %q", got, want)
+	}
+
+	want = `(*godump.user) &{ID:8, Manager:&{ID:7, Manager:nil, Team:nil}, Team:&{Name:"core"}}` + "\n"
+	d := NewDumper(WithFollowPointers(0), WithPointerPolicy(reflect.TypeOf(u), Follow), WithPointerPolicy(reflect.TypeOf(u.Team), Follow), WithInline(200))
+	if got := d.Sdump(u); got != want {
+		t.Errorf("follow: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import "bytes"
+
+// WithPostProcessor makes the Dumper pass every line of output through f,
+// without its trailing newline, and write what f returns instead. It allows
+// last-mile adjustments like replacing host names. Post-processors apply in
+// the order they were added, before checksums are computed, and see the
+// output of renderers writing partial lines, like WithSExpr, piecewise.
+func WithPostProcessor(f func(line string) string) Option {
+	return func(d *Dumper) {
+		d.postProcessors = append(d.postProcessors, f)
+	}
+}
+
+// postProcess returns the lines of b passed through the post-processors.
+func (v *variable) postProcess(b []byte) []byte {
+	var out bytes.Buffer
+	for len(b) > 0 {
+		line, nl := b, false
+		if i := bytes.IndexByte(b, '\n'); i >= 0 {
+			line, nl = b[:i], true
+			b = b[i+1:]
+		} else {
+			b = nil
+		}
+		s := string(line)
+		for _, f := range v.d.postProcessors {
+			s = f(s)
+		}
+		out.WriteString(s)
+		if nl {
+			out.WriteByte('\n')
+		}
+	}
+	return out.Bytes()
+}
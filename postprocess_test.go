@@ -0,0 +1,27 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPostProcessor(t *testing.T) {
+	v := struct{ Host, Peer string }{"db1.internal", "db2.internal"}
+	hide := func(line string) string { return strings.ReplaceAll(line, ".internal", ".example") }
+	upper := func(line string) string { return strings.ToUpper(line) }
+	want := "(STRUCT { HOST STRING; PEER STRING })\n" +
+		"  HOST(STRING) \"DB1.EXAMPLE\"\n" +
+		"  PEER(STRING) \"DB2.EXAMPLE\"\n"
+	if got := Sdump(v, WithPostProcessor(hide), WithPostProcessor(upper)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	d := NewDumper(WithPostProcessor(hide), WithChecksum(true))
+	if err := VerifyChecksum(d.Sdump(v)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WithPreview cuts dumps larger than maxBytes down to a preview: their
+// first and last lines lines, and in between a line summarizing the lines
+// left out by the types of their values, most frequent first:
+//
+//	([]main.Order)
+//	  0(main.Order)
+//	    ID(int) 1
+//	... 41,230 lines omitted: 20,000 string, 10,000 main.Item, 1,230 int
+//	  999(main.Order)
+//	    ID(int) 1000
+//
+// Unlike a plain byte limit, the preview shows how the dump ends and what
+// it holds. Dumps are held in memory up to maxBytes to find out whether
+// they need a preview. Zero disables previews; this is the default.
+func WithPreview(maxBytes, lines int) Option {
+	return func(d *Dumper) {
+		d.previewBytes, d.previewLines = maxBytes, lines
+	}
+}
+
+// preview collects the lines of a dump written with WithPreview.
+type preview struct {
+	maxBytes, lines int
+
+	// Bytes of the dump so far, and whether they exceed maxBytes
+	size int
+	over bool
+
+	// All lines while the dump fits, then the first lines
+	head []previewLine
+
+	// Last lines once the dump is too large
+	tail []previewLine
+
+	// Lines left out, in total and by type
+	omitted int
+	types   map[string]int
+}
+
+type previewLine struct {
+	at   Line
+	text []byte
+}
+
+// add collects the output chunk b, written for the value at.
+func (p *preview) add(b []byte, at Line) {
+	for len(b) > 0 {
+		i := bytes.IndexByte(b, '\n') + 1
+		if i == 0 {
+			i = len(b)
+		}
+		l := previewLine{at, append([]byte(nil), b[:i]...)}
+		b = b[i:]
+		p.size += len(l.text)
+		if p.over {
+			p.push(l)
+			continue
+		}
+		p.head = append(p.head, l)
+		if p.size > p.maxBytes {
+			p.over = true
+			rest := p.head[min(p.lines, len(p.head)):]
+			p.head = p.head[:len(p.head)-len(rest)]
+			for _, l := range rest {
+				p.push(l)
+			}
+		}
+	}
+}
+
+// push adds l to the last lines, leaving out the first of them if there
+// are too many.
+func (p *preview) push(l previewLine) {
+	p.tail = append(p.tail, l)
+	if len(p.tail) <= p.lines {
+		return
+	}
+	if p.types == nil {
+		p.types = make(map[string]int)
+	}
+	p.omitted++
+	if t := p.tail[0].at.Type; t != "" {
+		p.types[t]++
+	}
+	p.tail = p.tail[1:]
+}
+
+// summary returns the line summarizing the lines left out.
+func (p *preview) summary(count func(int) string) string {
+	types := make([]string, 0, len(p.types))
+	for t := range p.types {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if p.types[types[i]] != p.types[types[j]] {
+			return p.types[types[i]] > p.types[types[j]]
+		}
+		return types[i] < types[j]
+	})
+	s := "... " + count(p.omitted) + " lines omitted"
+	for i, t := range types {
+		if i == 0 {
+			s += ": "
+		} else {
+			s += ", "
+		}
+		s += count(p.types[t]) + " " + t
+	}
+	return s + "\n"
+}
+
+// flushPreview writes the preview of the dump, or the whole dump if it
+// fits.
+func (v *variable) flushPreview() {
+	p := v.preview
+	if p == nil {
+		return
+	}
+	v.preview = nil
+	for _, l := range p.head {
+		v.emit(l.text, l.at)
+	}
+	if !p.over {
+		return
+	}
+	if p.omitted > 0 {
+		v.truncated("", TruncatedPreview, p.omitted)
+		v.emit([]byte(p.summary(v.count)), Line{})
+	}
+	for _, l := range p.tail {
+		v.emit(l.text, l.at)
+	}
+}
+
+// previewOption parses the value of the preview setting, BYTES:LINES.
+func previewOption(s string) (Option, error) {
+	b, l, _ := strings.Cut(s, ":")
+	maxBytes, err := strconv.Atoi(b)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := strconv.Atoi(l)
+	return WithPreview(maxBytes, lines), err
+}
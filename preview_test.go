@@ -0,0 +1,47 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPreview(t *testing.T) {
+	type item struct {
+		SKU string
+		Qty int
+	}
+	items := make([]item, 1000)
+	var truncations []Truncation
+	d := NewDumper(WithPreview(200, 3), WithTruncationReport(func(ts []Truncation) { truncations = ts }))
+	want := `([]godump.item)
+  0(godump.item)
+    SKU(string) ""
+... 2,995 lines omitted: 999 int, 998 godump.item, 998 string
+  999(godump.item)
+    SKU(string) ""
+    Qty(int) 0
+`
+	if got := d.Sdump(items); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if want := []Truncation{{"", TruncatedPreview, 2995}}; !reflect.DeepEqual(truncations, want) {
+		t.Errorf("truncations: got %+v, want %+v", truncations, want)
+	}
+
+	want = "([]godump.item)\n  0(godump.item)\n    SKU(string) \"\"\n    Qty(int) 0\n"
+	if got := d.Sdump(items[:1]); got != want {
+		t.Errorf("small dump: got\n%s\nwant\n%s", got, want)
+	}
+
+	var lines []Line
+	for l := range d.Lines(items) {
+		lines = append(lines, l)
+	}
+	if len(lines) != 7 || lines[6] != (Line{2, "[999].Qty", "int", "    Qty(int) 0"}) {
+		t.Errorf("Lines: got %+v", lines)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"sort"
+	"sync"
+)
+
+// Provenance records where the values of a value came from, by path, e.g.
+// the sources of the fields of a configuration merged from defaults, files,
+// environment variables and flags. The loader records the source of every
+// value it sets:
+//
+//	p := godump.NewProvenance()
+//	cfg.Port = 8080
+//	p.Set("Port", "default")
+//	if s := os.Getenv("APP_PORT"); s != "" {
+//		cfg.Port, _ = strconv.Atoi(s)
+//		p.Set("Port", "env APP_PORT")
+//	}
+//	godump.Dump(cfg, godump.WithProvenance(p))
+//
+// and dumps annotate the values with their sources:
+//
+//	Port(int) 9090 (from env APP_PORT)
+//
+// Paths are written as in dumps, e.g. DB.Hosts[0] or Limits[cpu]. A
+// Provenance is safe for concurrent use.
+type Provenance struct {
+	mu      sync.RWMutex
+	sources map[string]string
+}
+
+// NewProvenance returns an empty Provenance.
+func NewProvenance() *Provenance {
+	return &Provenance{sources: make(map[string]string)}
+}
+
+// Set records source as the source of the value at path, replacing the one
+// recorded before, e.g. when a flag overrides an environment variable.
+func (p *Provenance) Set(path, source string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sources[path] = source
+}
+
+// Hook returns a function recording source as the source of the paths it
+// is called with, relative to prefix, for decoders reporting the fields
+// they set. An empty prefix records the paths as they are.
+func (p *Provenance) Hook(prefix, source string) func(path string) {
+	return func(path string) {
+		p.Set(joinPath(prefix, path), source)
+	}
+}
+
+// Source returns the source recorded for the value at path.
+func (p *Provenance) Source(path string) (string, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	s, ok := p.sources[path]
+	return s, ok
+}
+
+// Paths returns the paths with a recorded source, sorted.
+func (p *Provenance) Paths() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	paths := make([]string, 0, len(p.sources))
+	for path := range p.sources {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// WithProvenance annotates the values whose source is recorded in p with
+// it, as (from SOURCE). Values with a source are not inlined, so every one
+// is annotated on its own line.
+func WithProvenance(p *Provenance) Option {
+	return func(d *Dumper) {
+		d.provenance = p
+	}
+}
+
+// provenanceNote annotates n with the source of its value, if recorded.
+func (v *variable) provenanceNote(n *Node) {
+	if v.d.provenance == nil {
+		return
+	}
+	if s, ok := v.d.provenance.Source(n.Path); ok {
+		n.annotate("(from " + s + ")")
+	}
+}
+
+// hasSource reports whether a source is recorded for the value at path.
+func (v *variable) hasSource(path string) bool {
+	if v.d.provenance == nil {
+		return false
+	}
+	_, ok := v.d.provenance.Source(path)
+	return ok
+}
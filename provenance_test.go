@@ -0,0 +1,43 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProvenance(t *testing.T) {
+	type db struct {
+		Hosts []string
+		User  string
+	}
+	type config struct {
+		Port int
+		DB   db
+	}
+	cfg := config{Port: 9090, DB: db{Hosts: []string{"a", "b"}, User: "app"}}
+	p := NewProvenance()
+	p.Set("Port", "default")
+	p.Set("Port", "env APP_PORT")
+	hook := p.Hook("DB", "file app.toml")
+	hook("Hosts[1]")
+	hook("User")
+
+	want := `(godump.config)
+  Port(int) 9090 (from env APP_PORT)
+  DB(godump.db)
+    Hosts([]string)
+      0(string) "a"
+      1(string) "b" (from file app.toml)
+    User(string) "app" (from file app.toml)
+`
+	if got := Sdump(cfg, WithProvenance(p), WithInline(80)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got, want := p.Paths(), []string{"DB.Hosts[1]", "DB.User", "Port"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Paths() = %q, want %q", got, want)
+	}
+}
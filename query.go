@@ -0,0 +1,462 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrNoMatch is returned by Get if no value matches the query.
+var ErrNoMatch = errors.New("godump: no value matches the query")
+
+// Query selects values nested in a value. Queries are written as paths
+// like those of the dump, whose steps may select several values:
+//
+//	Orders[*].Items[?(Qty>10)].SKU
+//
+// A step is a field name or map key, like Orders, or one in brackets:
+//
+//	*, [*]        all fields, map entries or elements
+//	[2]           the element at index 2
+//	["k"], [k]    the map entry with key k
+//	[?(X op y)]   the fields, map entries or elements whose X compares to y
+//
+// In conditions, X is a query relative to the value checked, or @ for the
+// value itself, op is one of == != < <= > >=, and y is a number, a quoted
+// string, true or false. A condition holds if it holds for any value X
+// selects. Pointers and interfaces are looked through at every step, and
+// only exported fields are selected.
+type Query struct {
+	expr  string
+	steps []step
+}
+
+type stepKind int
+
+const (
+	fieldStep stepKind = iota
+	indexStep
+	keyStep
+	allStep
+	condStep
+)
+
+type step struct {
+	kind  stepKind
+	name  string
+	index int
+	cond  *cond
+}
+
+// cond is the condition of a filter step.
+type cond struct {
+	lhs []step // nil for @
+	op  string
+	lit reflect.Value
+}
+
+// match is a value selected by a query, with the path the dump would give
+// it.
+type match struct {
+	path string
+	val  reflect.Value
+}
+
+// ParseQuery returns the query written as expr.
+func ParseQuery(expr string) (*Query, error) {
+	steps, err := parseSteps(expr)
+	if err != nil {
+		return nil, fmt.Errorf("godump: bad query %q: %v", expr, err)
+	}
+	return &Query{expr, steps}, nil
+}
+
+// String returns the query as it was written.
+func (q *Query) String() string {
+	return q.expr
+}
+
+// Get returns the first value nested in v that the query expr selects.
+func Get(v interface{}, expr string) (interface{}, error) {
+	q, err := ParseQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range q.eval(reflect.ValueOf(v)) {
+		if m.val.CanInterface() {
+			return m.val.Interface(), nil
+		}
+	}
+	return nil, ErrNoMatch
+}
+
+// Find returns the values nested in v that the query expr selects, in the
+// order of the dump of v, with map entries sorted by key.
+func Find(v interface{}, expr string) ([]interface{}, error) {
+	q, err := ParseQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+	var vals []interface{}
+	for _, m := range q.eval(reflect.ValueOf(v)) {
+		if m.val.CanInterface() {
+			vals = append(vals, m.val.Interface())
+		}
+	}
+	return vals, nil
+}
+
+// WithFilter makes dumps show only the values selected by q, each named by
+// its path:
+//
+//	Orders[0].Items[1].SKU(string) "A-1"
+//	Orders[2].Items[0].SKU(string) "B-7"
+func WithFilter(q *Query) Option {
+	return func(d *Dumper) {
+		d.filter, d.filterErr = q, nil
+	}
+}
+
+// WithFilterExpr is like WithFilter with the query written as expr. If expr
+// is not a valid query, dumps show the error instead and fail with it.
+func WithFilterExpr(expr string) Option {
+	return func(d *Dumper) {
+		d.filter, d.filterErr = ParseQuery(expr)
+	}
+}
+
+// root dumps the top-level value val, the values named by DumpNamed, or the
+// values the filter selects.
+func (v *variable) root(val reflect.Value) {
+	val = addressable(val)
+	v.findShared(val)
+	switch {
+	case v.d.filterErr != nil:
+		n := &Node{Note: "<" + v.d.filterErr.Error() + ">", Leaf: true}
+		v.open(n)
+		v.close(n)
+		if v.err == nil {
+			v.err = v.d.filterErr
+		}
+	case val.IsValid() && val.Type() == namedValuesType:
+		v.named(val.Interface().(namedValues))
+	case v.d.filter != nil:
+		for _, m := range v.d.filter.eval(val) {
+			v.dump(m.val, m.path, m.path)
+		}
+	default:
+		v.dump(nilInterface(val), "", "")
+	}
+}
+
+func parseSteps(s string) ([]step, error) {
+	var steps []step
+	for i := 0; i < len(s); {
+		if s[i] == '[' {
+			j, err := closingBracket(s, i)
+			if err != nil {
+				return nil, err
+			}
+			st, err := parseBracket(s[i+1 : j])
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, st)
+			i = j + 1
+			continue
+		}
+		if len(steps) > 0 {
+			if s[i] != '.' {
+				return nil, fmt.Errorf("unexpected %q at offset %d", s[i], i)
+			}
+			i++
+		}
+		j := i
+		for j < len(s) && s[j] != '.' && s[j] != '[' {
+			j++
+		}
+		switch name := s[i:j]; name {
+		case "":
+			return nil, fmt.Errorf("missing name at offset %d", i)
+		case "*":
+			steps = append(steps, step{kind: allStep})
+		default:
+			steps = append(steps, step{kind: fieldStep, name: name})
+		}
+		i = j
+	}
+	if len(steps) == 0 {
+		return nil, errors.New("empty query")
+	}
+	return steps, nil
+}
+
+// closingBracket returns the index of the bracket closing the one at i,
+// skipping quoted strings and nested brackets.
+func closingBracket(s string, i int) (int, error) {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '"':
+			end, err := closingQuote(s, j)
+			if err != nil {
+				return 0, err
+			}
+			j = end
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return j, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unclosed bracket at offset %d", i)
+}
+
+// closingQuote returns the index of the quote ending the string starting
+// at i.
+func closingQuote(s string, i int) (int, error) {
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '"':
+			return j, nil
+		}
+	}
+	return 0, fmt.Errorf("unclosed string at offset %d", i)
+}
+
+func parseBracket(s string) (step, error) {
+	switch {
+	case s == "*":
+		return step{kind: allStep}, nil
+	case strings.HasPrefix(s, "?(") && strings.HasSuffix(s, ")"):
+		c, err := parseCond(s[2 : len(s)-1])
+		return step{kind: condStep, cond: c}, err
+	case strings.HasPrefix(s, `"`):
+		key, err := strconv.Unquote(s)
+		if err != nil {
+			return step{}, fmt.Errorf("bad key %s", s)
+		}
+		return step{kind: keyStep, name: key}, nil
+	}
+	if i, err := strconv.Atoi(s); err == nil && i >= 0 {
+		return step{kind: indexStep, index: i, name: s}, nil
+	}
+	if s == "" {
+		return step{}, errors.New("empty brackets")
+	}
+	return step{kind: keyStep, name: s}, nil
+}
+
+var condOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func parseCond(s string) (*cond, error) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' {
+			end, err := closingQuote(s, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			continue
+		}
+		for _, op := range condOps {
+			if !strings.HasPrefix(s[i:], op) {
+				continue
+			}
+			c := &cond{op: op}
+			lhs := strings.TrimSpace(s[:i])
+			if lhs != "@" {
+				var err error
+				if c.lhs, err = parseSteps(strings.TrimPrefix(lhs, "@.")); err != nil {
+					return nil, err
+				}
+			}
+			lit, err := parseLiteral(strings.TrimSpace(s[i+len(op):]))
+			if err != nil {
+				return nil, err
+			}
+			c.lit = lit
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("no comparison in condition %q", s)
+}
+
+func parseLiteral(s string) (reflect.Value, error) {
+	switch s {
+	case "true":
+		return reflect.ValueOf(true), nil
+	case "false":
+		return reflect.ValueOf(false), nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		str, err := strconv.Unquote(s)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("bad string %s", s)
+		}
+		return reflect.ValueOf(str), nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("bad literal %q", s)
+	}
+	return reflect.ValueOf(f), nil
+}
+
+// eval returns the values nested in val that q selects.
+func (q *Query) eval(val reflect.Value) []match {
+	return evalSteps(q.steps, []match{{"", val}})
+}
+
+func evalSteps(steps []step, ms []match) []match {
+	for _, st := range steps {
+		var next []match
+		for _, m := range ms {
+			next = st.apply(m, next)
+		}
+		ms = next
+	}
+	return ms
+}
+
+// apply appends the values selected by the step from m to ms.
+func (st step) apply(m match, ms []match) []match {
+	val := m.val
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return ms
+		}
+		val = val.Elem()
+	}
+	switch st.kind {
+	case fieldStep, keyStep:
+		switch val.Kind() {
+		case reflect.Struct:
+			if f, ok := val.Type().FieldByName(st.name); ok && st.kind == fieldStep && f.IsExported() {
+				ms = append(ms, match{joinPath(m.path, st.name), val.FieldByIndex(f.Index)})
+			}
+		case reflect.Map:
+			ms = mapEntry(m.path, val, st.name, ms)
+		}
+	case indexStep:
+		switch val.Kind() {
+		case reflect.Array, reflect.Slice:
+			if st.index < val.Len() {
+				ms = append(ms, match{m.path + "[" + st.name + "]", val.Index(st.index)})
+			}
+		case reflect.Map:
+			ms = mapEntry(m.path, val, st.name, ms)
+		}
+	case allStep, condStep:
+		for _, c := range children(m.path, val) {
+			if st.kind == allStep || st.cond.holds(c.val) {
+				ms = append(ms, c)
+			}
+		}
+	}
+	return ms
+}
+
+// mapEntry appends the entry of the map val with the key named name to ms.
+func mapEntry(path string, val reflect.Value, name string, ms []match) []match {
+	iter := val.MapRange()
+	for iter.Next() {
+		if keyString(iter.Key()) == name {
+			return append(ms, match{path + "[" + name + "]", iter.Value()})
+		}
+	}
+	return ms
+}
+
+// children returns the exported fields, map entries sorted by key or
+// elements of val.
+func children(path string, val reflect.Value) []match {
+	var ms []match
+	switch val.Kind() {
+	case reflect.Struct:
+		for i := 0; i < val.NumField(); i++ {
+			if f := val.Type().Field(i); f.IsExported() {
+				ms = append(ms, match{joinPath(path, f.Name), val.Field(i)})
+			}
+		}
+	case reflect.Map:
+		_, values, names := mapEntries(val, keyString)
+		for i, e := range values {
+			ms = append(ms, match{path + "[" + names[i] + "]", e})
+		}
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < val.Len(); i++ {
+			ms = append(ms, match{path + "[" + strconv.Itoa(i) + "]", val.Index(i)})
+		}
+	}
+	return ms
+}
+
+// holds reports whether the condition holds for val.
+func (c *cond) holds(val reflect.Value) bool {
+	vals := []match{{"", val}}
+	if c.lhs != nil {
+		vals = evalSteps(c.lhs, vals)
+	}
+	for _, m := range vals {
+		if c.compare(m.val) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *cond) compare(val reflect.Value) bool {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return false
+		}
+		val = val.Elem()
+	}
+	var r int
+	switch {
+	case c.lit.Kind() == reflect.Float64 && isNumeric(val.Kind()):
+		switch a, b := numeric(val), c.lit.Float(); {
+		case a < b:
+			r = -1
+		case a > b:
+			r = 1
+		}
+	case c.lit.Kind() == reflect.String && val.Kind() == reflect.String:
+		r = strings.Compare(val.String(), c.lit.String())
+	case c.lit.Kind() == reflect.Bool && val.Kind() == reflect.Bool:
+		switch c.op {
+		case "==":
+			return val.Bool() == c.lit.Bool()
+		case "!=":
+			return val.Bool() != c.lit.Bool()
+		}
+		return false
+	default:
+		return false
+	}
+	switch c.op {
+	case "==":
+		return r == 0
+	case "!=":
+		return r != 0
+	case "<":
+		return r < 0
+	case "<=":
+		return r <= 0
+	case ">":
+		return r > 0
+	}
+	return r >= 0
+}
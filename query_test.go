@@ -0,0 +1,83 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type queryItem struct {
+	SKU string
+	Qty int
+}
+
+type queryOrder struct {
+	Items []*queryItem
+	Tags  map[string]bool
+}
+
+var queryOrders = struct{ Orders []queryOrder }{[]queryOrder{
+	{Items: []*queryItem{{"A-0", 5}, {"A-1", 12}}, Tags: map[string]bool{"rush": true}},
+	{Items: []*queryItem{{"B-0", 1}}},
+	{Items: []*queryItem{{"C-0", 30}, nil}},
+}}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []interface{}
+	}{
+		{"Orders[*].Items[?(Qty>10)].SKU", []interface{}{"A-1", "C-0"}},
+		{"Orders[1].Items[0].Qty", []interface{}{1}},
+		{`Orders[*].Items[?(SKU=="B-0")].Qty`, []interface{}{1}},
+		{"Orders[0].Tags.rush", []interface{}{true}},
+		{`Orders[0].Tags["rush"]`, []interface{}{true}},
+		{"Orders[?(Tags.rush==true)].Items[0].SKU", []interface{}{"A-0"}},
+		{"Orders[0].Items[*].*", []interface{}{"A-0", 5, "A-1", 12}},
+		{"Orders[0].Items[*].Qty[?(@<=5)]", nil},
+		{"Orders[5].Items", nil},
+	}
+	for _, tt := range tests {
+		got, err := Find(queryOrders, tt.expr)
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	for _, expr := range []string{"", "Orders[", "Orders[?(Qty)]", "Orders..Items", `Orders["x]`, "Orders[?(Qty>x)]"} {
+		if _, err := ParseQuery(expr); err == nil {
+			t.Errorf("%q: no error", expr)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	if got, err := Get(queryOrders, "Orders[2].Items[0].SKU"); err != nil || got != "C-0" {
+		t.Errorf("got %v, %v", got, err)
+	}
+	if _, err := Get(queryOrders, "Orders[2].Items[1].SKU"); err != ErrNoMatch {
+		t.Errorf("nil pointer: got %v", err)
+	}
+}
+
+func TestFilterExpr(t *testing.T) {
+	want := `Orders[0].Items[1].SKU(string) "A-1"` + "\n" +
+		`Orders[2].Items[0].SKU(string) "C-0"` + "\n"
+	if got := Sdump(queryOrders, WithFilterExpr("Orders[*].Items[?(Qty>10)].SKU")); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	var b strings.Builder
+	err := Fdump(&b, queryOrders, WithFilterExpr("Orders["))
+	if err == nil || !strings.Contains(b.String(), err.Error()) {
+		t.Errorf("bad query: got %v, output %q", err, b.String())
+	}
+}
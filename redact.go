@@ -0,0 +1,149 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Mask replacing redacted values.
+const redacted = "***REDACTED***"
+
+// Rule name of the values ignored by a Harness, which are masked but not
+// reported as redacted.
+const ignoredRule = "\x00ignored"
+
+// RedactRule masks the values it matches. Match is called with the path of
+// every value, e.g. Config.DB.Password, and the value itself.
+type RedactRule struct {
+	Name  string
+	Match func(path string, val reflect.Value) bool
+}
+
+// Redaction records a value masked by a rule.
+type Redaction struct {
+	Path string
+	Rule string
+}
+
+// WithRedact masks the values matched by any of rules as ***REDACTED***.
+// Rules are tried in order; the first match wins. Struct fields tagged
+// dump:"redact" are always masked, by the rule "tag".
+func WithRedact(rules ...RedactRule) Option {
+	return func(d *Dumper) {
+		d.redactRules = append(d.redactRules, rules...)
+	}
+}
+
+// SensitiveNames are the parts of names RedactNames masks by default.
+var SensitiveNames = []string{"password", "passwd", "secret", "token", "key", "credential"}
+
+// RedactNames returns a rule, named "names", masking the struct fields and
+// map entries whose name contains any of patterns, ignoring case, e.g.
+// APIKey or Headers[X-Auth-Token]. Without patterns, SensitiveNames are
+// used:
+//
+//	godump.Dump(cfg, godump.WithRedact(godump.RedactNames()))
+func RedactNames(patterns ...string) RedactRule {
+	if len(patterns) == 0 {
+		patterns = SensitiveNames
+	}
+	lower := make([]string, len(patterns))
+	for i, p := range patterns {
+		lower[i] = strings.ToLower(p)
+	}
+	return RedactRule{"names", func(path string, _ reflect.Value) bool {
+		name := strings.ToLower(lastName(path))
+		for _, p := range lower {
+			if strings.Contains(name, p) {
+				return true
+			}
+		}
+		return false
+	}}
+}
+
+// lastName returns the name of the value at path: the last field name, or
+// the key or index if the path ends with an element.
+func lastName(path string) string {
+	if strings.HasSuffix(path, "]") {
+		if i := strings.LastIndexByte(path, '['); i >= 0 {
+			return path[i+1 : len(path)-1]
+		}
+	}
+	return path[strings.LastIndexByte(path, '.')+1:]
+}
+
+// Rule name of the values masked because their field is tagged
+// dump:"redact".
+const tagRule = "tag"
+
+// redactTagged reports whether the field f is tagged to be masked, by
+// dump:"redact" or godump:"redact".
+func redactTagged(f reflect.StructField) bool {
+	if _, ok := tagOption(f, "redact"); ok {
+		return true
+	}
+	return strings.Contains(","+f.Tag.Get("godump")+",", ",redact,")
+}
+
+// WithRedactionReport calls f after every dump with the values masked in it,
+// in the order they were found, so it can be verified that dumps are
+// sanitized.
+func WithRedactionReport(f func([]Redaction)) Option {
+	return func(d *Dumper) {
+		d.redactionReport = f
+	}
+}
+
+// WithRedactionSummary ends every dump with one line per masked value,
+// giving its path and the rule which masked it.
+func WithRedactionSummary(summary bool) Option {
+	return func(d *Dumper) {
+		d.redactionSummary = summary
+	}
+}
+
+// redactRule returns the name of the rule masking val, found at path.
+func (v *variable) redactRule(path string, val reflect.Value) (string, bool) {
+	if v.d.ignored != nil && v.d.ignored(path) {
+		return ignoredRule, true
+	}
+	for _, r := range v.d.redactRules {
+		if r.Match(path, val) {
+			return r.Name, true
+		}
+	}
+	return "", false
+}
+
+// redact renders n masked and records it.
+func (v *variable) redact(n *Node, rule string) {
+	n.Leaf = true
+	if rule == ignoredRule {
+		n.Value = "<ignored>"
+		v.open(n)
+		return
+	}
+	n.Value = redacted
+	v.open(n)
+	v.redactions = append(v.redactions, Redaction{Path: n.Path, Rule: rule})
+}
+
+// reportRedactions writes the redaction summary and calls the report
+// function, as configured.
+func (v *variable) reportRedactions() {
+	if v.d.redactionSummary {
+		for _, r := range v.redactions {
+			v.line.WriteString("redacted " + tracePath(r.Path) + " by rule " + strconv.Quote(r.Rule) + "\n")
+			v.flushLine()
+		}
+	}
+	if v.d.redactionReport != nil {
+		v.d.redactionReport(v.redactions)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type credentials struct {
+	User     string
+	Password string
+	Tokens   []string
+}
+
+func TestRedact(t *testing.T) {
+	password := RedactRule{"password", func(path string, val reflect.Value) bool {
+		return strings.HasSuffix(path, "Password")
+	}}
+	tokens := RedactRule{"tokens", func(path string, val reflect.Value) bool {
+		return path == "Tokens"
+	}}
+
+	var report []Redaction
+	d := NewDumper(
+		WithRedact(password, tokens),
+		WithRedactionReport(func(r []Redaction) { report = r }),
+		WithRedactionSummary(true),
+	)
+	got := d.Sdump(credentials{"bob", "hunter2", []string{"t1"}})
+	want := "(godump.credentials)\n" +
+		"  User(string) \"bob\"\n" +
+		"  Password(string) ***REDACTED***\n" +
+		"  Tokens([]string) ***REDACTED***\n" +
+		"redacted Password by rule \"password\"\n" +
+		"redacted Tokens by rule \"tokens\"\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	wantReport := []Redaction{{"Password", "password"}, {"Tokens", "tokens"}}
+	if !reflect.DeepEqual(report, wantReport) {
+		t.Errorf("report %v, want %v", report, wantReport)
+	}
+}
+
+func TestRedactInline(t *testing.T) {
+	d := NewDumper(WithInline(80), WithRedact(RedactRule{"user", func(path string, val reflect.Value) bool {
+		return path == "User"
+	}}))
+	want := "(godump.credentials)\n" +
+		"  User(string) ***REDACTED***\n" +
+		"  Password(string) \"x\"\n" +
+		"  Tokens([]string) nil\n"
+	if got := d.Sdump(credentials{"bob", "x", nil}); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRedactNames(t *testing.T) {
+	type service struct {
+		Name    string
+		APIKey  string
+		Headers map[string]string
+		Cert    []byte `dump:"redact"`
+		PIN     int    `godump:"redact"`
+	}
+	v := service{"billing", "k-123", map[string]string{"Accept": "*/*", "X-Auth-Token": "t"}, []byte("pem"), 1234}
+	var report []Redaction
+	got := Sdump(v, WithRedact(RedactNames()), WithInline(80),
+		WithRedactionReport(func(r []Redaction) { report = r }))
+	want := "(godump.service)\n" +
+		"  Name(string) \"billing\"\n" +
+		"  APIKey(string) ***REDACTED***\n" +
+		"  Headers(map[string]string)\n" +
+		"    Accept(string) \"*/*\"\n" +
+		"    X-Auth-Token(string) ***REDACTED***\n" +
+		"  Cert([]uint8) ***REDACTED***\n" +
+		"  PIN(int) ***REDACTED***\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	wantReport := []Redaction{{"APIKey", "names"}, {"Headers[X-Auth-Token]", "names"}, {"Cert", "tag"}, {"PIN", "tag"}}
+	if !reflect.DeepEqual(report, wantReport) {
+		t.Errorf("report %v, want %v", report, wantReport)
+	}
+
+	if got := Sdump(v, WithRedact(RedactNames("name"))); !strings.Contains(got, "Name(string) ***REDACTED***") ||
+		!strings.Contains(got, `APIKey(string) "k-123"`) {
+		t.Errorf("custom patterns: got\n%s", got)
+	}
+}
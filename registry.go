@@ -0,0 +1,58 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"sort"
+	"sync"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]interface{})
+)
+
+// Register makes v available under name to the debug handlers and to signal
+// triggered dumps. If v is a func() interface{}, it is called each time the
+// value is dumped, so live state can be exposed. Registering an existing name
+// replaces the previous value.
+func Register(name string, v interface{}) {
+	registryMu.Lock()
+	registry[name] = v
+	registryMu.Unlock()
+}
+
+// Unregister removes the value registered under name.
+func Unregister(name string) {
+	registryMu.Lock()
+	delete(registry, name)
+	registryMu.Unlock()
+}
+
+// Registered returns the names of all registered values in sorted order.
+func Registered() []string {
+	registryMu.RLock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	registryMu.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// lookup returns the current value registered under name.
+func lookup(name string) (interface{}, bool) {
+	registryMu.RLock()
+	v, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	if f, ok := v.(func() interface{}); ok {
+		v = f()
+	}
+	return v, true
+}
@@ -0,0 +1,33 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	n := 0
+	Register("b", 1)
+	Register("a", func() interface{} { n++; return n })
+	defer Unregister("a")
+	defer Unregister("b")
+
+	if got := Registered(); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("Registered() = %v", got)
+	}
+	if v, _ := lookup("a"); v != 1 {
+		t.Errorf("lookup(a) = %v, want 1", v)
+	}
+	if v, _ := lookup("a"); v != 2 {
+		t.Errorf("lookup(a) = %v, want 2", v)
+	}
+
+	Unregister("b")
+	if _, ok := lookup("b"); ok {
+		t.Error("b still registered")
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strconv"
+	"text/template"
+)
+
+// Node describes a value while it is being rendered.
+type Node struct {
+	// Nesting level; the top-level value is at depth 0
+	Depth int
+
+	// Indentation of the default output for Depth
+	Indent string
+
+	// Location of the value inside the top-level value, e.g. Users[0].Name
+	Path string
+
+	// Field name, index or map key of the value
+	Name string
+
+	// Type of the value as printed by %T
+	Type string
+
+	// True if Type is already given by the parent node, e.g. for the
+	// elements of a homogeneous slice
+	TypeImplied bool
+
+	// True for the elements of arrays and slices, whose Name is their index
+	Elem bool
+
+	// Kind of the value
+	Kind reflect.Kind
+
+	// True for values rendered by Value rather than by children. Leaves
+	// may still have children derived from their value, like decoded
+	// content.
+	Leaf bool
+
+	// Go-syntax representation of leaf values
+	Value string
+
+	// Annotation of the value, e.g. when its children were elided. Nodes
+	// standing in for elided values have a Note but no Type.
+	Note string
+
+	// Classes of the value, like ClassSecret, if classification is turned
+	// on by WithClassifier
+	Classes []string
+}
+
+// annotate appends s to the note of n.
+func (n *Node) annotate(s string) {
+	if n.Note != "" {
+		n.Note += " "
+	}
+	n.Note += s
+}
+
+// Renderer turns the nodes of a dump into output. Open is called for every
+// node, followed by the nodes of its children, if any, and by Close.
+// Everything written during a call ends up on the output lines of the node.
+type Renderer interface {
+	Open(w io.Writer, n *Node) error
+	Close(w io.Writer, n *Node) error
+}
+
+// WithRenderer makes the Dumper use r for its output.
+func WithRenderer(r Renderer) Option {
+	return func(d *Dumper) {
+		d.renderer = r
+		d.treeRender = nil
+		d.style = ""
+		d.format = "renderer"
+		if _, ok := r.(textRenderer); ok {
+			d.format = ""
+		}
+	}
+}
+
+// WithTemplate renders every node with t instead of the default grammar.
+// The template is executed with a *Node and should produce one line, e.g.
+//
+//	{{.Indent}}{{.Name}} <{{.Type}}>{{if .Leaf}} = {{.Value}}{{end}}
+//
+// A trailing newline is added if the template does not end with one.
+func WithTemplate(t *template.Template) Option {
+	return func(d *Dumper) {
+		WithRenderer(templateRenderer{t})(d)
+		d.format = "template"
+	}
+}
+
+// textRenderer renders the default grammar: name(type) value, in color if
+// requested.
+type textRenderer struct {
+	color bool
+}
+
+func (r textRenderer) Open(w io.Writer, n *Node) error {
+	// Lines are written straight to the line buffer of dumps.
+	if b, ok := w.(*bytes.Buffer); ok {
+		r.writeText(b, n)
+		return nil
+	}
+	var b bytes.Buffer
+	r.writeText(&b, n)
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// writeText appends the line of n to b.
+func (r textRenderer) writeText(b *bytes.Buffer, n *Node) {
+	b.WriteString(n.Indent)
+	start := b.Len()
+	r.colored(b, colorName, n.Name)
+	if n.Type != "" && !n.TypeImplied {
+		b.WriteByte('(')
+		r.colored(b, colorType, n.Type)
+		b.WriteByte(')')
+	}
+	if n.Leaf && n.Type != "" {
+		b.WriteByte(' ')
+		r.colored(b, colorValue, n.Value)
+	}
+	if n.Note != "" {
+		if b.Len() > start {
+			b.WriteByte(' ')
+		}
+		r.colored(b, colorNote, n.Note)
+	}
+	if len(n.Classes) > 0 {
+		if b.Len() > start {
+			b.WriteByte(' ')
+		}
+		r.colored(b, colorNote, classNote(n))
+	}
+	b.WriteByte('\n')
+}
+
+// colored appends s to b, in color c if colors are enabled.
+func (r textRenderer) colored(b *bytes.Buffer, c, s string) {
+	if !r.color || s == "" {
+		b.WriteString(s)
+		return
+	}
+	b.WriteString(c)
+	b.WriteString(s)
+	b.WriteString(colorReset)
+}
+
+func (textRenderer) Close(w io.Writer, n *Node) error {
+	return nil
+}
+
+type templateRenderer struct {
+	t *template.Template
+}
+
+func (r templateRenderer) Open(w io.Writer, n *Node) error {
+	var b bytes.Buffer
+	if err := r.t.Execute(&b, n); err != nil {
+		return err
+	}
+	if b.Len() == 0 || b.Bytes()[b.Len()-1] != '\n' {
+		b.WriteByte('\n')
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func (r templateRenderer) Close(w io.Writer, n *Node) error {
+	return nil
+}
+
+// tree is a node with its children, as collected by a treeRenderer.
+type tree struct {
+	Node
+	children []*tree
+
+	// Correlation ID of the dump, on the top-level node
+	id string
+}
+
+// decimal returns the value of the leaf t in decimal if it is an unsigned
+// integer, which the default grammar writes in hex.
+func (t *tree) decimal() (string, bool) {
+	switch t.Kind {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u, err := strconv.ParseUint(t.Value, 0, 64); err == nil {
+			return strconv.FormatUint(u, 10), true
+		}
+	}
+	return "", false
+}
+
+// treeRenderer collects the nodes of a dump and renders them as a whole
+// once the top-level node is closed. It supports output formats that cannot
+// be written node by node.
+type treeRenderer struct {
+	d      *Dumper
+	render func(d *Dumper, w io.Writer, root *tree) error
+	stack  []*tree
+	id     string
+}
+
+func (r *treeRenderer) Open(w io.Writer, n *Node) error {
+	t := &tree{Node: *n}
+	if len(r.stack) > 0 {
+		parent := r.stack[len(r.stack)-1]
+		parent.children = append(parent.children, t)
+	}
+	r.stack = append(r.stack, t)
+	return nil
+}
+
+func (r *treeRenderer) Close(w io.Writer, n *Node) error {
+	t := r.stack[len(r.stack)-1]
+	r.stack = r.stack[:len(r.stack)-1]
+	if len(r.stack) > 0 {
+		return nil
+	}
+	t.id = r.id
+	return r.render(r.d, w, t)
+}
@@ -0,0 +1,29 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"io"
+	"testing"
+	"text/template"
+)
+
+func TestTemplate(t *testing.T) {
+	tmpl := template.Must(template.New("node").Parse(
+		`{{.Indent}}{{.Name}} <{{.Type}}>{{if .Leaf}} = {{.Value}}{{end}}`))
+	d := NewDumper(WithTemplate(tmpl))
+	want := " <godump.S>\n  A <int> = 1\n  B <int> = 2\n"
+	if got := d.Sdump(S{1, 2}); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTemplateError(t *testing.T) {
+	tmpl := template.Must(template.New("node").Parse(`{{.Missing}}`))
+	d := NewDumper(WithTemplate(tmpl))
+	if err := d.fdump(io.Discard, "", 1); err == nil {
+		t.Error("expected template error")
+	}
+}
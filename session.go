@@ -0,0 +1,81 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Session collects the values of a flow to dump them together in a single
+// block, titled and stamped with the time the session began:
+//
+//	s := godump.Begin("checkout flow")
+//	s.Add("cart", cart)
+//	s.Add("user", user)
+//	s.End(os.Stdout)
+//
+// prints
+//
+//	checkout flow 2024-01-02T15:04:05+01:00
+//	  cart(main.Cart)
+//	    ...
+//	  user(*main.User)
+//	    ...
+//
+// The values share the configuration, markers, header and checksum of a
+// single dump. Sessions are safe for concurrent use.
+type Session struct {
+	d     *Dumper
+	title string
+	start time.Time
+
+	mu     sync.Mutex
+	values namedValues
+}
+
+// Begin begins a session titled title. Options are applied as by Dump.
+func Begin(title string, opts ...Option) *Session {
+	return std().With(opts...).Begin(title)
+}
+
+// Begin is the Dumper version of the package-level Begin.
+func (d *Dumper) Begin(title string) *Session {
+	return &Session{d: d, title: title, start: now()}
+}
+
+// Add adds v, named name, to the values of s. Values are dumped by End, as
+// they are then.
+func (s *Session) Add(name string, v interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = append(s.values, namedValue{name, v})
+}
+
+// End writes the block of the values added to s to w.
+func (s *Session) End(w io.Writer) error {
+	s.mu.Lock()
+	values := s.values
+	s.mu.Unlock()
+
+	dump := s.d.newVariable(w, s.title)
+	dump.begin()
+	dump.indent++
+	n := &Node{
+		Indent: dump.indentation(0),
+		Name:   s.title,
+		Note:   s.start.Format(time.RFC3339),
+	}
+	dump.open(n)
+	dump.named(values)
+	dump.close(n)
+	dump.indent--
+	dump.end()
+	if err := dump.w.Flush(); err != nil {
+		return err
+	}
+	return dump.err
+}
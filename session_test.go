@@ -0,0 +1,37 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSession(t *testing.T) {
+	defer func(f func() time.Time) { now = f }(now)
+	now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	type cart struct{ Items []string }
+	s := Begin("checkout flow", WithMarkers(true))
+	s.Add("cart", cart{[]string{"book"}})
+	s.Add("total", 12)
+
+	var b strings.Builder
+	if err := s.End(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `===== BEGIN dump "checkout flow" =====
+checkout flow 2024-01-02T03:04:05Z
+  cart(godump.cart)
+    Items([]string)
+      0(string) "book"
+  total(int) 12
+===== END dump "checkout flow" =====
+`
+	if got := b.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
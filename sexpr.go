@@ -0,0 +1,91 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WithSExpr renders dumps as S-expressions on a single line, like
+//
+//	(main.User (Name "bob") (Tags ("a" "b")))
+//
+// Every composite value is a list headed by its name, or by its type for
+// the top-level value and the elements of arrays and slices. The elements
+// of arrays and slices are held by a list of their own, those that are
+// leaves as bare values, so (Tags ("a")) is told apart from (Tags "a").
+// Pointers are rendered as the values they point to and elided elements as
+// block comments, like the lines written by a Dumpable or Handler.
+// WithSExpr(false) restores the default grammar if S-expressions were
+// selected, and leaves other renderers alone.
+func WithSExpr(enable bool) Option {
+	return func(d *Dumper) {
+		switch {
+		case enable:
+			WithRenderer(sexprRenderer{})(d)
+			d.format = "sexpr"
+		case d.format == "sexpr":
+			WithRenderer(textRenderer{})(d)
+		}
+	}
+}
+
+type sexprRenderer struct{}
+
+func (sexprRenderer) Open(w io.Writer, n *Node) error {
+	var b strings.Builder
+	// The first element of an array or slice starts its list.
+	if (n.Path != "" || n.Type == "") && !(n.Elem && n.Name == "0") {
+		b.WriteByte(' ')
+	}
+	head := n.Name
+	if head == "" || n.Elem {
+		head = n.Type
+	}
+	switch {
+	case n.Type == "":
+		b.WriteString("#|" + n.Name + n.Note + "|#")
+	case n.Kind == reflect.Ptr && !n.Leaf:
+		return nil
+	case n.Leaf && n.Elem:
+		b.WriteString(n.Value)
+	case n.Leaf:
+		b.WriteString("(" + symbol(head) + " " + n.Value + ")")
+	case n.Kind == reflect.Array || n.Kind == reflect.Slice:
+		b.WriteString("(" + symbol(head) + " (")
+	default:
+		b.WriteString("(" + symbol(head))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (sexprRenderer) Close(w io.Writer, n *Node) error {
+	s := ""
+	switch {
+	case n.Leaf || n.Type == "" || n.Kind == reflect.Ptr:
+	case n.Kind == reflect.Array || n.Kind == reflect.Slice:
+		s = "))"
+	default:
+		s = ")"
+	}
+	if n.Depth == 0 {
+		s += "\n"
+	}
+	_, err := io.WriteString(w, s)
+	return err
+}
+
+// symbol returns s as a symbol, quoting it if it holds characters that
+// would end one.
+func symbol(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n()\";'|#") {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,37 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import "testing"
+
+type person struct {
+	Name  string
+	Tags  []string
+	Pets  []*S
+	Attrs map[string]int
+}
+
+func TestSExpr(t *testing.T) {
+	v := &person{"bob", []string{"a", "b"}, []*S{{1, 2}}, map[string]int{"x y": 1}}
+	want := `(godump.person (Name "bob") (Tags ("a" "b")) (Pets ((godump.S (A 1) (B 2)))) (Attrs ("x y" 1)))` + "\n"
+	if got := Sdump(v, WithSExpr(true)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	d := NewDumper(WithSExpr(true))
+	d.maxElements = 1
+	want = `([]int (1 #|... 2 more elements|#))` + "\n"
+	if got := d.Sdump([]int{1, 2, 3}); got != want {
+		t.Errorf("elided: got %q, want %q", got, want)
+	}
+
+	want = `("struct { L []int; N int }" (L (1)) (N 1))` + "\n"
+	if got := Sdump(struct {
+		L []int
+		N int
+	}{[]int{1}, 1}, WithSExpr(true)); got != want {
+		t.Errorf("slice field: got %q, want %q", got, want)
+	}
+}
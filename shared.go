@@ -0,0 +1,138 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// WithAddresses annotates pointers with the address they point to, like
+// @0xc000012080, so values shared by several pointers can be told apart
+// from equal copies when diagnosing aliasing bugs. Pointers to primitive
+// values are not inlined then.
+func WithAddresses(enable bool) Option {
+	return func(d *Dumper) {
+		d.addresses = enable
+	}
+}
+
+// WithSharedPointers dumps the values reached by the same pointer more than
+// once only the first time: that pointer is numbered, like #3, and the
+// others refer to it as => see #3. This shows which parts of a value are
+// aliased and shrinks the dumps of graphs sharing nodes. Shared pointers are
+// numbered in the order they are dumped, and neither they nor the values
+// containing them are inlined.
+func WithSharedPointers(enable bool) Option {
+	return func(d *Dumper) {
+		d.sharedPointers = enable
+	}
+}
+
+// findShared records the pointers reachable from val more than once, if
+// shared pointers are dumped once.
+func (v *variable) findShared(val reflect.Value) {
+	if !v.d.sharedPointers {
+		return
+	}
+	counts := make(map[visit]int)
+	v.countPointers(val, counts)
+	v.shared = make(map[visit]int)
+	for p, n := range counts {
+		if n > 1 {
+			v.shared[p] = 0
+		}
+	}
+}
+
+// countPointers counts the references to the pointers reachable from val in
+// counts, following every pointer the first time only and stopping at maps
+// containing themselves.
+func (v *variable) countPointers(val reflect.Value, counts map[visit]int) {
+	val = v.accessible(val)
+	if !val.IsValid() || !val.CanInterface() && v.d.fields == ExportedOnly {
+		return
+	}
+	switch val.Kind() {
+	case reflect.Interface:
+		if !val.IsNil() {
+			v.countPointers(val.Elem(), counts)
+		}
+	case reflect.Ptr:
+		if val.IsNil() {
+			return
+		}
+		p := visitOf(val)
+		counts[p]++
+		if counts[p] == 1 {
+			v.countPointers(val.Elem(), counts)
+		}
+	case reflect.Struct:
+		for i := 0; i < val.NumField(); i++ {
+			if fv, ok := v.structField(val, i); ok {
+				v.countPointers(fv, counts)
+			}
+		}
+	case reflect.Map:
+		if val.IsNil() || v.visiting[visitOf(val)] {
+			return
+		}
+		v.enter(val)
+		defer v.leave(val)
+		iter := val.MapRange()
+		for iter.Next() {
+			v.countPointers(iter.Value(), counts)
+		}
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < val.Len(); i++ {
+			v.countPointers(val.Index(i), counts)
+		}
+	}
+}
+
+// isShared reports whether the pointer val is reachable more than once.
+func (v *variable) isShared(val reflect.Value) bool {
+	if v.shared == nil || val.IsNil() {
+		return false
+	}
+	_, ok := v.shared[visitOf(val)]
+	return ok
+}
+
+// sharedRef renders n as the reference to the pointer val if it is shared
+// and dumped already.
+func (v *variable) sharedRef(val reflect.Value, n *Node) bool {
+	if !v.isShared(val) {
+		return false
+	}
+	num := v.shared[visitOf(val)]
+	if num == 0 {
+		return false
+	}
+	n.Leaf = true
+	n.Value = "=> see #" + strconv.Itoa(num)
+	v.annotateAddress(val, n)
+	return true
+}
+
+// numberPointer numbers the pointer val, dumped as n, if it is shared, and
+// annotates n with the address, if requested.
+func (v *variable) numberPointer(val reflect.Value, n *Node) {
+	if v.isShared(val) {
+		v.refs++
+		v.shared[visitOf(val)] = v.refs
+		n.annotate("#" + strconv.Itoa(v.refs))
+	}
+	v.annotateAddress(val, n)
+}
+
+// annotateAddress annotates n with the address the pointer val points to,
+// if requested.
+func (v *variable) annotateAddress(val reflect.Value, n *Node) {
+	if v.d.addresses && !val.IsNil() {
+		n.annotate(fmt.Sprintf("@%#x", val.Pointer()))
+	}
+}
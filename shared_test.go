@@ -0,0 +1,62 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type vertex struct {
+	Name  string
+	Edges []*vertex
+}
+
+func TestSharedPointers(t *testing.T) {
+	leaf := &vertex{Name: "c"}
+	root := &vertex{Name: "a", Edges: []*vertex{{Name: "b", Edges: []*vertex{leaf}}, leaf}}
+	root.Edges[0].Edges = append(root.Edges[0].Edges, root)
+
+	got := NewDumper(WithSharedPointers(true), WithInline(80)).Sdump(root)
+	want := "(*godump.vertex) #1\n" +
+		"  (godump.vertex)\n" +
+		"    Name(string) \"a\"\n" +
+		"    Edges([]*godump.vertex)\n" +
+		"      0(*godump.vertex)\n" +
+		"        0(godump.vertex)\n" +
+		"          Name(string) \"b\"\n" +
+		"          Edges([]*godump.vertex)\n" +
+		"            0(*godump.vertex) #2\n" +
+		"              0(godump.vertex) {Name:\"c\", Edges:nil}\n" +
+		"            1(*godump.vertex) => see #1\n" +
+		"      1(*godump.vertex) => see #2\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	m := map[string]interface{}{}
+	m["self"] = m
+	got = NewDumper(WithSharedPointers(true)).Sdump(m)
+	if want := fmt.Sprintf("(map[string]interface {})\n  self(map[string]interface {}) <cycle to %p>\n", m); got != want {
+		t.Errorf("map cycle: got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAddresses(t *testing.T) {
+	n := 42
+	v := struct{ A, B *int }{&n, &n}
+	got := NewDumper(WithAddresses(true), WithSharedPointers(true), WithInlinePointers(true)).Sdump(v)
+	addr := fmt.Sprintf("@%#x", &n)
+	want := "(struct { A *int; B *int })\n" +
+		"  A(*int) #1 " + addr + "\n" +
+		"    A(int) 42\n" +
+		"  B(*int) => see #1 " + addr + "\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got := NewDumper(WithAddresses(true)).Sdump(v); strings.Count(got, addr) != 2 || strings.Contains(got, "#1") {
+		t.Errorf("addresses only got\n%s", got)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+var (
+	signalMu  sync.Mutex
+	signalCh  chan os.Signal
+	signalOut io.Writer = os.Stderr
+)
+
+// DumpRegistered writes the dumps of all registered values to w, each one
+// preceded by its name.
+func DumpRegistered(w io.Writer) error {
+	for _, name := range Registered() {
+		v, ok := lookup(name)
+		if !ok {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s:\n%s", name, std().sdump(name, v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnableSignalDump dumps all registered values whenever the process
+// receives one of the given signals, much like the runtime dumps all
+// goroutines on SIGQUIT:
+//
+//	godump.EnableSignalDump(syscall.SIGUSR1)
+//
+// The dumps are written to os.Stderr unless changed with SetSignalOutput.
+func EnableSignalDump(sig ...os.Signal) {
+	if len(sig) == 0 {
+		return
+	}
+	signalMu.Lock()
+	defer signalMu.Unlock()
+	if signalCh == nil {
+		signalCh = make(chan os.Signal, 1)
+		go signalLoop(signalCh)
+	}
+	signal.Notify(signalCh, sig...)
+}
+
+// DisableSignalDump undoes the effect of all prior calls to
+// EnableSignalDump.
+func DisableSignalDump() {
+	signalMu.Lock()
+	defer signalMu.Unlock()
+	if signalCh == nil {
+		return
+	}
+	signal.Stop(signalCh)
+	close(signalCh)
+	signalCh = nil
+}
+
+// SetSignalOutput sets the writer used by signal triggered dumps.
+func SetSignalOutput(w io.Writer) {
+	signalMu.Lock()
+	signalOut = w
+	signalMu.Unlock()
+}
+
+func signalLoop(ch chan os.Signal) {
+	for range ch {
+		signalMu.Lock()
+		w := signalOut
+		signalMu.Unlock()
+		DumpRegistered(w)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+)
+
+// chanWriter hands every write over to a channel.
+type chanWriter chan string
+
+func (c chanWriter) Write(p []byte) (int, error) {
+	c <- string(p)
+	return len(p), nil
+}
+
+func TestDumpRegistered(t *testing.T) {
+	Register("b", "two")
+	Register("a", 1)
+	defer Unregister("a")
+	defer Unregister("b")
+
+	var buf bytes.Buffer
+	if err := DumpRegistered(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "a:\n" + Sdump(1) + "b:\n" + Sdump("two")
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestSignalDump(t *testing.T) {
+	Register("state", 7)
+	defer Unregister("state")
+
+	out := make(chanWriter, 8)
+	SetSignalOutput(out)
+	defer SetSignalOutput(os.Stderr)
+	EnableSignalDump(os.Interrupt)
+	defer DisableSignalDump()
+
+	// Deliver the signal by hand rather than interrupting the test binary.
+	signalMu.Lock()
+	signalCh <- os.Interrupt
+	signalMu.Unlock()
+
+	var got string
+	for got != "state:\n"+Sdump(7) {
+		select {
+		case s := <-out:
+			got += s
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out, got %q", got)
+		}
+	}
+}
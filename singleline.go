@@ -0,0 +1,104 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"io"
+	"reflect"
+	"strings"
+)
+
+// WithSingleLine renders every top-level value on a single line in a form
+// close to Go syntax, whatever its size, like
+//
+//	godump.User{Name:"bob", Tags:[]string{"a", "b"}, Manager:&godump.User{Name:"ann"}}
+//
+// to embed dumps into structured log messages. Composite values are headed
+// by their type unless it is implied by the collection holding them, and
+// elided values are written as their note. String map keys are quoted and
+// unsigned integers written in decimal. Notes on leaves are left out.
+// Byte slices are rendered element by element rather than as a hex dump.
+// WithSingleLine(false) restores the default grammar if single-line output
+// was selected, and leaves other renderers alone.
+func WithSingleLine(enable bool) Option {
+	return func(d *Dumper) {
+		if !enable && d.format != "singleline" {
+			return
+		}
+		d.renderer = textRenderer{}
+		d.treeRender = nil
+		d.style = ""
+		d.format = ""
+		if enable {
+			d.treeRender = renderSingleLine
+			d.format = "singleline"
+			d.hexdump = false
+		}
+	}
+}
+
+// Sdumpf returns the dump of v on a single line, as rendered by
+// WithSingleLine, without a trailing newline. Options are applied as by
+// Dump.
+func Sdumpf(v interface{}, opts ...Option) string {
+	return std().With(opts...).Sdumpf(v)
+}
+
+// Sdumpf is the Dumper version of the package-level Sdumpf.
+func (d *Dumper) Sdumpf(v interface{}) string {
+	return strings.TrimSuffix(d.With(WithSingleLine(true)).Sdump(v), "\n")
+}
+
+func renderSingleLine(_ *Dumper, w io.Writer, root *tree) error {
+	var b strings.Builder
+	if root.id != "" {
+		b.WriteString("id=" + root.id + " ")
+	}
+	if root.Name != "" {
+		b.WriteString(root.Name + "=")
+	}
+	writeSingleLine(&b, root, "")
+	b.WriteByte('\n')
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeSingleLine appends t, a value held by a collection of type parent,
+// to b.
+func writeSingleLine(b *strings.Builder, t *tree, parent string) {
+	switch {
+	case t.Type == "":
+		b.WriteString(strings.TrimSpace(t.Name + " " + t.Note))
+		return
+	case t.Leaf && t.Value != "":
+		if s, ok := t.decimal(); ok {
+			b.WriteString(s)
+		} else {
+			b.WriteString(t.Value)
+		}
+		return
+	case t.Kind == reflect.Ptr && len(t.children) == 1:
+		b.WriteByte('&')
+		writeSingleLine(b, t.children[0], "")
+		return
+	}
+	if !t.Elem || !strings.HasSuffix(parent, "]"+t.Type) {
+		b.WriteString(t.Type)
+	}
+	b.WriteByte('{')
+	if len(t.children) == 0 {
+		b.WriteString(t.Note)
+	}
+	for i, c := range t.children {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if (t.Kind == reflect.Struct || t.Kind == reflect.Map) && c.Type != "" {
+			b.WriteString(c.Name + ":")
+		}
+		writeSingleLine(b, c, t.Type)
+	}
+	b.WriteByte('}')
+}
@@ -0,0 +1,62 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"testing"
+	"text/template"
+)
+
+func TestSingleLine(t *testing.T) {
+	type user struct {
+		Name    string
+		Tags    []string
+		Limits  map[string]int
+		Manager *user
+		Avatar  []byte
+		Team    []user
+	}
+	v := user{
+		Name:    "bob",
+		Tags:    []string{"a", "b"},
+		Limits:  map[string]int{"cpu": 2},
+		Manager: &user{Name: "ann"},
+		Avatar:  []byte("hi"),
+		Team:    []user{{Name: "cy"}},
+	}
+	tests := []struct {
+		opts []Option
+		want string
+	}{
+		{nil, `godump.user{Name:"bob", Tags:[]string{"a", "b"}, Limits:map[string]int{"cpu":2}, ` +
+			`Manager:&godump.user{Name:"ann", Tags:nil, Limits:nil, Manager:nil, Avatar:nil, Team:nil}, ` +
+			`Avatar:[]uint8{104, 105}, Team:[]godump.user{{Name:"cy", Tags:nil, Limits:nil, Manager:nil, Avatar:nil, Team:nil}}}`},
+		{[]Option{WithMaxDepth(2), WithMaxElements(1)}, `godump.user{Name:"bob", Tags:[]string{"a", ... 1 more element}, ` +
+			`Limits:map[string]int{"cpu":2}, Manager:&godump.user{...(max depth reached)}, ` +
+			`Avatar:[]uint8{104, ... 1 more element}, Team:[]godump.user{{...(max depth reached)}}}`},
+	}
+	for i, tt := range tests {
+		if got := Sdumpf(v, tt.opts...); got != tt.want {
+			t.Errorf("%d: got\n%s\nwant\n%s", i, got, tt.want)
+		}
+	}
+
+	if got, want := Sdumpf(42), "42"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if got, want := NewDumper(WithSingleLine(true)).SdumpNamed("n", 1, "s", []int{1}), "n=1\ns=[]int{1}\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSingleLineDisabled(t *testing.T) {
+	tmpl := template.Must(template.New("").Parse("{{.Name}}={{.Value}}"))
+	if got, want := Sdump(S{1, 2}, WithTemplate(tmpl), WithSingleLine(false)), Sdump(S{1, 2}, WithTemplate(tmpl)); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := Sdump(S{1, 2}, WithSingleLine(true), WithSingleLine(false)), Sdump(S{1, 2}); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
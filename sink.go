@@ -0,0 +1,184 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Severity classifies dumps for routing them to sinks.
+type Severity int
+
+// Severities in increasing order.
+const (
+	Debug Severity = iota
+	Info
+	Warning
+	Error
+)
+
+var severityNames = []string{"debug", "info", "warning", "error"}
+
+func (s Severity) String() string {
+	if s >= 0 && int(s) < len(severityNames) {
+		return severityNames[s]
+	}
+	return "severity(" + strconv.Itoa(int(s)) + ")"
+}
+
+// Sink receives finished dumps.
+type Sink interface {
+	Write(label string, sev Severity, chunk []byte) error
+}
+
+// idSink is implemented by the sinks keeping the correlation IDs of dumps.
+// They are handed the ID rather than reading it from the stamp line, which
+// documents like those of WithJSON do not have.
+type idSink interface {
+	writeID(label, id string, sev Severity, chunk []byte) error
+}
+
+// SinkFunc adapts a function to a Sink.
+type SinkFunc func(label string, sev Severity, chunk []byte) error
+
+func (f SinkFunc) Write(label string, sev Severity, chunk []byte) error {
+	return f(label, sev, chunk)
+}
+
+type route struct {
+	sink Sink
+	min  Severity
+}
+
+// WithSink sends the dumps made with at least severity min to s. Once a sink
+// is configured, dumps are no longer printed to standard out; Dump and
+// DumpLabel use severity Debug.
+func WithSink(min Severity, s Sink) Option {
+	return func(d *Dumper) {
+		d.sinks = append(d.sinks, route{s, min})
+	}
+}
+
+// WriterSink returns a Sink writing dumps to w.
+func WriterSink(w io.Writer) Sink {
+	var mu sync.Mutex
+	return SinkFunc(func(label string, sev Severity, chunk []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		_, err := w.Write(chunk)
+		return err
+	})
+}
+
+// LoggerSink returns a Sink printing dumps to l, preceded by their severity,
+// label and correlation ID, if they have one.
+func LoggerSink(l *log.Logger) Sink {
+	return loggerSink{l}
+}
+
+type loggerSink struct {
+	l *log.Logger
+}
+
+func (s loggerSink) Write(label string, sev Severity, chunk []byte) error {
+	id, _ := CorrelationID(string(chunk))
+	return s.writeID(label, id, sev, chunk)
+}
+
+func (s loggerSink) writeID(label, id string, sev Severity, chunk []byte) error {
+	prefix := sev.String() + " " + label
+	if id != "" {
+		prefix += " id=" + id
+	}
+	return s.l.Output(2, prefix+":\n"+strings.TrimSuffix(string(chunk), "\n"))
+}
+
+// RingSink keeps the most recent dumps in memory.
+type RingSink struct {
+	mu    sync.Mutex
+	size  int
+	recs  []Record
+	count uint64
+}
+
+// NewRingSink returns a RingSink keeping the last size dumps.
+func NewRingSink(size int) *RingSink {
+	return &RingSink{size: size}
+}
+
+func (r *RingSink) Write(label string, sev Severity, chunk []byte) error {
+	id, _ := CorrelationID(string(chunk))
+	return r.writeID(label, id, sev, chunk)
+}
+
+func (r *RingSink) writeID(label, id string, sev Severity, chunk []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count++
+	if len(r.recs) == r.size {
+		copy(r.recs, r.recs[1:])
+		r.recs = r.recs[:r.size-1]
+	}
+	r.recs = append(r.recs, Record{
+		Seq:      r.count,
+		Time:     time.Now(),
+		Label:    label,
+		ID:       id,
+		Severity: sev.String(),
+		Text:     string(chunk),
+	})
+	return nil
+}
+
+// Records returns the kept dumps, oldest first.
+func (r *RingSink) Records() []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Record(nil), r.recs...)
+}
+
+// DumpSeverity dumps v with label to the sinks accepting sev, or to standard
+// out if the Dumper has no sinks. It returns the first error of a sink.
+func (d *Dumper) DumpSeverity(sev Severity, label string, v interface{}) error {
+	out, id := d.sdumpID(label, v)
+	return d.deliver(sev, label, id, v, out)
+}
+
+// deliver writes out, the dump of v with the correlation ID id, where
+// DumpSeverity would and mirrors it to the stream servers.
+func (d *Dumper) deliver(sev Severity, label, id string, v interface{}, out string) error {
+	publish(label, id, v, out)
+	if len(d.sinks) == 0 {
+		_, err := io.WriteString(d.output(), out)
+		return err
+	}
+
+	var err error
+	for _, r := range d.sinks {
+		if sev < r.min {
+			continue
+		}
+		var werr error
+		if s, ok := r.sink.(idSink); ok {
+			werr = s.writeID(label, id, sev, []byte(out))
+		} else {
+			werr = r.sink.Write(label, sev, []byte(out))
+		}
+		if err == nil {
+			err = werr
+		}
+	}
+	return err
+}
+
+// DumpSeverity is the package-level version of Dumper.DumpSeverity.
+func DumpSeverity(sev Severity, label string, v interface{}, opts ...Option) error {
+	return std().With(opts...).DumpSeverity(sev, label, v)
+}
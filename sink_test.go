@@ -0,0 +1,38 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"bytes"
+	"log"
+	"testing"
+)
+
+func TestSinks(t *testing.T) {
+	var file, logged bytes.Buffer
+	ring := NewRingSink(1)
+	d := NewDumper(
+		WithSink(Debug, WriterSink(&file)),
+		WithSink(Error, LoggerSink(log.New(&logged, "", 0))),
+		WithSink(Error, ring),
+	)
+
+	d.DumpLabel("tick", 1)
+	if err := d.DumpSeverity(Error, "failed", 2); err != nil {
+		t.Fatal(err)
+	}
+	d.DumpSeverity(Error, "failed again", 3)
+
+	if want := Sdump(1) + Sdump(2) + Sdump(3); file.String() != want {
+		t.Errorf("file got %q, want %q", file.String(), want)
+	}
+	if want := "error failed:\n(int) 2\nerror failed again:\n(int) 3\n"; logged.String() != want {
+		t.Errorf("log got %q, want %q", logged.String(), want)
+	}
+	recs := ring.Records()
+	if len(recs) != 1 || recs[0].Seq != 2 || recs[0].Label != "failed again" || recs[0].Severity != "error" || recs[0].Text != Sdump(3) {
+		t.Errorf("ring got %+v", recs)
+	}
+}
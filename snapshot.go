@@ -0,0 +1,45 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// WithMapSnapshot makes the Dumper copy every map in a single pass before
+// rendering it, so the map is only read for the short time the copy takes
+// rather than while its entries are rendered, and a failed copy leaves the
+// entries copied so far, annotated with the failure.
+//
+// This narrows, but does not close, the window in which a goroutine writing
+// the map makes the runtime abort the program with "concurrent map
+// iteration and map write": such a fatal error cannot be recovered from.
+// Maps shared between goroutines should be dumped holding their lock.
+func WithMapSnapshot(snapshot bool) Option {
+	return func(d *Dumper) {
+		d.mapSnapshot = snapshot
+	}
+}
+
+// snapshotMap returns a copy of the map val, and annotates n if the copy
+// could not be completed. Maps reached through unexported fields are
+// returned as they are, since their entries cannot be copied.
+func snapshotMap(val reflect.Value, n *Node) (snap reflect.Value) {
+	if val.IsNil() || !val.CanInterface() {
+		return val
+	}
+	snap = reflect.MakeMapWithSize(val.Type(), val.Len())
+	defer func() {
+		if r := recover(); r != nil {
+			n.annotate(fmt.Sprintf("(snapshot incomplete: %v)", r))
+		}
+	}()
+	it := val.MapRange()
+	for it.Next() {
+		snap.SetMapIndex(it.Key(), it.Value())
+	}
+	return snap
+}
@@ -0,0 +1,28 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMapSnapshot(t *testing.T) {
+	v := struct {
+		M map[string]int
+		m map[string]int
+	}{map[string]int{"a": 1}, map[string]int{"b": 2}}
+	d := NewDumper(WithMapSnapshot(true), WithFields(AllFields))
+	want := NewDumper(WithFields(AllFields)).Sdump(v)
+	if got := d.Sdump(v); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	n := new(Node)
+	snap := snapshotMap(reflect.ValueOf(map[string]int{"a": 1}), n)
+	if snap.Len() != 1 || n.Note != "" {
+		t.Errorf("snapshot: %d entries, note %q", snap.Len(), n.Note)
+	}
+}
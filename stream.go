@@ -0,0 +1,235 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"path"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Record is a single dump as it is mirrored to stream clients. Records are
+// sent as newline-delimited JSON.
+type Record struct {
+	Seq      uint64    `json:"seq"`
+	Time     time.Time `json:"time"`
+	Label    string    `json:"label,omitempty"`
+	ID       string    `json:"id,omitempty"`
+	Severity string    `json:"severity,omitempty"`
+	Type     string    `json:"type,omitempty"`
+	Text     string    `json:"text"`
+}
+
+// Number of records buffered per client before new records are dropped for
+// that client. A slow viewer must never block the dumping program.
+const streamBacklog = 256
+
+var (
+	recordSeq uint64
+
+	streamMu      sync.Mutex
+	streamServers = make(map[*StreamServer]struct{})
+)
+
+// StreamServer mirrors every Dump call to the TCP clients connected to it.
+type StreamServer struct {
+	mu      sync.Mutex
+	ln      net.Listener
+	clients map[net.Conn]chan []byte
+	closed  bool
+}
+
+// ListenAndStream listens on the TCP network address addr and mirrors all
+// subsequent dumps to the connected clients. It blocks until the listener
+// fails, so it is usually started in its own goroutine:
+//
+//	go godump.ListenAndStream("localhost:7070")
+func ListenAndStream(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return new(StreamServer).Serve(ln)
+}
+
+// Serve accepts connections on ln and streams records to them until ln
+// fails or the server is closed.
+func (s *StreamServer) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		ln.Close()
+		return fmt.Errorf("godump: stream server closed")
+	}
+	s.ln = ln
+	s.clients = make(map[net.Conn]chan []byte)
+	s.mu.Unlock()
+
+	streamMu.Lock()
+	streamServers[s] = struct{}{}
+	streamMu.Unlock()
+	defer func() {
+		streamMu.Lock()
+		delete(streamServers, s)
+		streamMu.Unlock()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+		s.add(conn)
+	}
+}
+
+// Close stops the listener and disconnects all clients.
+func (s *StreamServer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	for conn, ch := range s.clients {
+		close(ch)
+		delete(s.clients, conn)
+	}
+	if s.ln != nil {
+		return s.ln.Close()
+	}
+	return nil
+}
+
+func (s *StreamServer) add(conn net.Conn) {
+	ch := make(chan []byte, streamBacklog)
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		conn.Close()
+		return
+	}
+	s.clients[conn] = ch
+	s.mu.Unlock()
+
+	go func() {
+		defer conn.Close()
+		for b := range ch {
+			if _, err := conn.Write(b); err != nil {
+				s.remove(conn)
+				return
+			}
+		}
+	}()
+}
+
+func (s *StreamServer) remove(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ch, ok := s.clients[conn]; ok {
+		close(ch)
+		delete(s.clients, conn)
+	}
+}
+
+func (s *StreamServer) send(b []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ch := range s.clients {
+		select {
+		case ch <- b:
+		default:
+		}
+	}
+}
+
+// publish mirrors a finished dump to all running stream servers and to the
+// recent recordings of the debug handlers, with its correlation ID id.
+func publish(label, id string, v interface{}, out string) {
+	streamMu.Lock()
+	defer streamMu.Unlock()
+	if len(streamServers) == 0 && !recording {
+		return
+	}
+
+	rec := &Record{
+		Seq:   atomic.AddUint64(&recordSeq, 1),
+		Time:  time.Now(),
+		Label: label,
+		ID:    id,
+		Type:  typeString(reflect.ValueOf(&v).Elem()),
+		Text:  out,
+	}
+	if recording {
+		remember(rec)
+	}
+	if len(streamServers) == 0 {
+		return
+	}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+	b = append(b, '\n')
+	for s := range streamServers {
+		s.send(b)
+	}
+}
+
+// StreamClient reads records from a stream server.
+type StreamClient struct {
+	// Filter, if set, is a path.Match pattern; records whose label does
+	// not match it are skipped by Recv.
+	Filter string
+
+	conn net.Conn
+	dec  *json.Decoder
+}
+
+// DialStream connects to the stream server listening on the TCP network
+// address addr.
+func DialStream(addr string) (*StreamClient, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &StreamClient{conn: conn, dec: json.NewDecoder(conn)}, nil
+}
+
+// Recv blocks until the next record matching Filter arrives.
+func (c *StreamClient) Recv() (*Record, error) {
+	for {
+		rec := new(Record)
+		if err := c.dec.Decode(rec); err != nil {
+			return nil, err
+		}
+		if c.Filter == "" {
+			return rec, nil
+		}
+		ok, err := path.Match(c.Filter, rec.Label)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return rec, nil
+		}
+	}
+}
+
+// Close closes the connection to the server.
+func (c *StreamClient) Close() error {
+	return c.conn.Close()
+}
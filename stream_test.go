@@ -0,0 +1,61 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func waitClients(t *testing.T, s *StreamServer, n int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		got := len(s.clients)
+		s.mu.Unlock()
+		if got == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d stream clients", n)
+}
+
+func TestStream(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := new(StreamServer)
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(ln) }()
+
+	c, err := DialStream(ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.Filter = "cache*"
+	waitClients(t, s, 1)
+
+	DumpLabel("session", "skipped")
+	DumpLabel("cache", []int{1, 2})
+
+	rec, err := c.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Label != "cache" || rec.Type != "[]int" || rec.Text != Sdump([]int{1, 2}) {
+		t.Errorf("unexpected record %+v", rec)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// StringerPolicy tells how values implementing fmt.Stringer are dumped.
+type StringerPolicy int
+
+const (
+	// IgnoreStringer dumps Stringers like any other value; this is the
+	// default.
+	IgnoreStringer StringerPolicy = iota
+
+	// StringerOnly renders Stringers as the quoted result of their String
+	// method, without dumping their contents, e.g. Month(time.Month)
+	// "March".
+	StringerOnly
+
+	// StringerPlusFields annotates Stringers with the quoted result of
+	// their String method and dumps their contents as usual.
+	StringerPlusFields
+)
+
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// WithStringers sets the policy for all Stringers.
+func WithStringers(p StringerPolicy) Option {
+	return func(d *Dumper) {
+		d.stringers = p
+	}
+}
+
+// WithStringerPolicy sets the policy for the Stringers of type t,
+// overriding the WithStringers policy. This way terse Stringers like
+// time.Month stop the dump while others are still expanded.
+func WithStringerPolicy(t reflect.Type, p StringerPolicy) Option {
+	return func(d *Dumper) {
+		if d.stringerPolicies == nil {
+			d.stringerPolicies = make(map[reflect.Type]StringerPolicy)
+		}
+		d.stringerPolicies[t] = p
+	}
+}
+
+// stringerText returns the quoted result of the String method of val, the
+// value of n, and the policy applying to it. The policy is IgnoreStringer
+// if val is not a Stringer. n is nil if val is dumped inline.
+func (v *variable) stringerText(val reflect.Value, n *Node, deref bool) (string, StringerPolicy) {
+	t := val.Type()
+	if val.Kind() == reflect.Interface && !val.IsNil() {
+		t = val.Elem().Type()
+	}
+	p, ok := v.d.stringerPolicies[t]
+	if !ok {
+		p = v.d.stringers
+	}
+	// Pointers to values that are Stringers themselves leave the String
+	// method to the value pointed to.
+	if p == IgnoreStringer || v.d.fullExpansion || t.Kind() == reflect.Ptr && t.Elem().Implements(stringerType) {
+		return "", IgnoreStringer
+	}
+	if n != nil {
+		val = v.receiver(val, stringerType, n, deref)
+	} else if !deref {
+		if val, ok = addrReceiver(val, stringerType); !ok {
+			// Only the expanded form notes that the method was not
+			// applied.
+			return "", StringerPlusFields
+		}
+	}
+	s, ok := stringerText(val)
+	if !ok {
+		return "", IgnoreStringer
+	}
+	return s, p
+}
+
+// stringerText returns the quoted result of the String method of val if
+// it is a non-nil Stringer. String methods panicking are reported as such.
+func stringerText(val reflect.Value) (s string, ok bool) {
+	if !val.CanInterface() || (val.Kind() == reflect.Interface || val.Kind() == reflect.Ptr) && val.IsNil() {
+		return "", false
+	}
+	str, ok := val.Interface().(fmt.Stringer)
+	if !ok {
+		return "", false
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			s = "<String panicked>"
+		}
+	}()
+	return strconv.Quote(str.String()), true
+}
@@ -0,0 +1,83 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type point struct{ X, Y int }
+
+func (p *point) String() string { return "(" + strconv.Itoa(p.X) + ", " + strconv.Itoa(p.Y) + ")" }
+
+type release struct {
+	Month time.Month
+	At    *point
+}
+
+func TestStringers(t *testing.T) {
+	v := release{time.March, &point{1, 2}}
+	tests := []struct {
+		opts []Option
+		want string
+	}{
+		{nil, "(godump.release)\n" +
+			"  Month(time.Month) 3\n" +
+			"  At(*godump.point)\n" +
+			"    At(godump.point)\n" +
+			"      X(int) 1\n" +
+			"      Y(int) 2\n"},
+		{[]Option{WithStringers(StringerOnly)}, "(godump.release)\n" +
+			"  Month(time.Month) \"March\"\n" +
+			"  At(*godump.point) \"(1, 2)\"\n"},
+		{[]Option{WithStringers(StringerPlusFields), WithStringerPolicy(reflect.TypeOf(time.Month(0)), StringerOnly)},
+			"(godump.release)\n" +
+				"  Month(time.Month) \"March\"\n" +
+				"  At(*godump.point) \"(1, 2)\"\n" +
+				"    At(godump.point)\n" +
+				"      X(int) 1\n" +
+				"      Y(int) 2\n"},
+		{[]Option{WithStringers(StringerOnly), WithStringerPolicy(reflect.TypeOf(&point{}), IgnoreStringer), WithInline(80)},
+			"(godump.release) {Month:\"March\", At:&{X:1, Y:2}}\n"},
+	}
+	for i, tt := range tests {
+		if got := Sdump(v, tt.opts...); got != tt.want {
+			t.Errorf("%d: got\n%s\nwant\n%s", i, got, tt.want)
+		}
+	}
+}
+
+type pointerMethods struct {
+	Err codeError
+	At  point
+	Map map[string]point
+}
+
+func TestPointerReceivers(t *testing.T) {
+	v := &pointerMethods{Err: codeError{Code: 4}, At: point{1, 2}}
+	want := "(*godump.pointerMethods) &{Err:\"code 4\", At:\"(1, 2)\", Map:nil}\n"
+	if got := Sdump(v, WithStringers(StringerOnly), WithInline(200)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	// Map values are not addressable, so their methods with pointer
+	// receivers cannot be called.
+	v.Map = map[string]point{"a": {}}
+	want = "(map[string]godump.point)\n" +
+		"  a(godump.point) (unaddressable: (*godump.point).String not applied)\n" +
+		"    X(int) 0\n" +
+		"    Y(int) 0\n"
+	if got := Sdump(v.Map, WithStringers(StringerOnly), WithInline(200)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	want = "(*godump.pointerMethods) &{Err:{Code:4}, At:{X:1, Y:2}, Map:{\"a\":{X:0, Y:0}}}\n"
+	if got := Sdump(v, WithStringers(StringerOnly), WithInline(200), WithFullExpansion(true)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
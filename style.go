@@ -0,0 +1,362 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// Style is a named output style. Tools parsing dumps can pin the style
+// they depend on: Validate checks that dumps follow its grammar, and the
+// Fingerprint of its Samples changes whenever its output does.
+type Style struct {
+	name     string
+	describe string
+	opts     []Option
+	validate func(lines []string) error
+}
+
+// The output styles.
+var (
+	// StyleDefault is the default grammar:
+	//
+	//	(main.User)
+	//	  Name(string) "bob"
+	//	  Tags([]string)
+	//	    0(string) "a"
+	StyleDefault = Style{
+		name: "default",
+		describe: "One value per line, indented by two spaces per level: the name, " +
+			"the type in parentheses and, for leaves, the Go-syntax value, followed " +
+			"by notes. Children follow their parent one level deeper.",
+		validate: validateIndented,
+	}
+
+	// StyleSpew resembles the output of go-spew, with the children of
+	// composite values enclosed in braces:
+	//
+	//	(main.User) {
+	//	 Name: (string) "bob"
+	//	 Tags: ([]string) {
+	//	  (string) "a"
+	//	 }
+	//	}
+	StyleSpew = Style{
+		name: "spew",
+		describe: "One value per line, indented by one space per level: the name " +
+			"followed by a colon, omitted for elements, the type in parentheses and " +
+			"the Go-syntax value of leaves. The children of composite values are " +
+			"enclosed in braces, the closing one on a line of its own.",
+		opts:     []Option{WithRenderer(spewRenderer{})},
+		validate: validateBraces,
+	}
+
+	// StyleTree draws the nesting of values with box-drawing characters:
+	//
+	//	(main.User)
+	//	├── Name(string) "bob"
+	//	└── Tags([]string)
+	//	    └── 0(string) "a"
+	StyleTree = Style{
+		name: "tree",
+		describe: "One value per line as in the default style, nested below its " +
+			"parent by a prefix drawing the branches of the tree: \"├── \" for " +
+			"children followed by siblings, \"└── \" for the last one, continued " +
+			"below by \"│   \" or four spaces.",
+		opts:     []Option{func(d *Dumper) { d.renderer, d.treeRender = textRenderer{}, renderTree }},
+		validate: validateTree,
+	}
+
+	// StyleCompact renders every value on a single line where possible,
+	// like the inline form of WithInline:
+	//
+	//	(main.User) {Name:"bob", Tags:{"a"}}
+	StyleCompact = Style{
+		name: "compact",
+		describe: "The default style with composite values written on the line of " +
+			"their name as {A:1, B:\"x\"}, unless they cannot be inlined, e.g. " +
+			"because they are elided.",
+		opts:     []Option{WithInline(math.MaxInt32)},
+		validate: validateIndented,
+	}
+)
+
+// Styles returns all styles.
+func Styles() []Style {
+	return []Style{StyleDefault, StyleSpew, StyleTree, StyleCompact}
+}
+
+// WithStyle renders dumps in the style s. The style is noted in the header.
+func WithStyle(s Style) Option {
+	return func(d *Dumper) {
+		d.renderer, d.treeRender = textRenderer{}, nil
+		d.inlineWidth = 0
+		for _, opt := range s.opts {
+			opt(d)
+		}
+		d.style = s.name
+		d.format = ""
+	}
+}
+
+// Name returns the name of s, as noted in the header.
+func (s Style) Name() string {
+	return s.name
+}
+
+// Describe returns the description of the grammar of s.
+func (s Style) Describe() string {
+	return s.describe
+}
+
+// Validate reports whether dump, as written by a Dumper using the style s
+// with the default indentation, follows its grammar. Marker, header, stamp
+// and checksum lines are skipped.
+func (s Style) Validate(dump string) error {
+	var lines []string
+	for i, l := range strings.Split(strings.TrimSuffix(dump, "\n"), "\n") {
+		if strings.HasPrefix(l, markerPrefix) || strings.HasPrefix(l, "godump/") ||
+			strings.HasPrefix(l, "seq=") || strings.HasPrefix(l, "time=") || strings.HasPrefix(l, "id=") || strings.HasPrefix(l, checksumPrefix) {
+			continue
+		}
+		if l == "" || strings.TrimSpace(l) != strings.TrimLeft(l, " ") {
+			return fmt.Errorf("godump: %s style: line %d: blank or with trailing space", s.name, i+1)
+		}
+		lines = append(lines, l)
+	}
+	if err := s.validate(lines); err != nil {
+		return fmt.Errorf("godump: %s style: %v", s.name, err)
+	}
+	return nil
+}
+
+// StyleSample is a value of the conformance corpus of the styles, dumped in
+// a style.
+type StyleSample struct {
+	Name string
+	Dump string
+}
+
+type sampleUser struct {
+	Name    string
+	Age     int
+	Score   float64
+	Admin   bool
+	Tags    []string
+	Limits  map[string]int
+	Manager *sampleUser
+	Avatar  []byte
+	Err     error
+}
+
+// Samples returns the dumps of the conformance corpus in the style s, so
+// tools parsing dumps can be tested against every construct of its grammar.
+func (s Style) Samples() []StyleSample {
+	d := NewDumper(WithStyle(s))
+	values := []struct {
+		name string
+		v    interface{}
+	}{
+		{"leaf", 42},
+		{"struct", sampleUser{
+			Name:    "bob",
+			Age:     42,
+			Score:   1.5,
+			Tags:    []string{"a", "b"},
+			Limits:  map[string]int{"cpu": 2, "mem": 512},
+			Manager: &sampleUser{Name: "ann"},
+			Avatar:  []byte("\x89PNG"),
+			Err:     errors.New("boom"),
+		}},
+		{"elided", []int{1, 2, 3, 4, 5}},
+		{"nil", nil},
+	}
+	samples := make([]StyleSample, len(values))
+	for i, v := range values {
+		dd := d
+		if v.name == "elided" {
+			dd = d.With(WithMaxElements(3))
+		}
+		samples[i] = StyleSample{v.name, dd.Sdump(v.v)}
+	}
+	return samples
+}
+
+// Fingerprint returns a digest of the Samples of s. It changes whenever the
+// output of s does, so tools can pin it in their tests.
+func (s Style) Fingerprint() string {
+	h := sha256.New()
+	for _, sample := range s.Samples() {
+		io.WriteString(h, sample.Name+"\x00"+sample.Dump+"\x00")
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// indentWidth returns the number of leading spaces of l.
+func indentWidth(l string) int {
+	return len(l) - len(strings.TrimLeft(l, " "))
+}
+
+// validateIndented checks that lines are indented by two spaces per level,
+// children one level below their parent.
+func validateIndented(lines []string) error {
+	depth := -1
+	for i, l := range lines {
+		w := indentWidth(l)
+		if w%2 != 0 {
+			return fmt.Errorf("line %d: odd indentation", i+1)
+		}
+		if w/2 > depth+1 {
+			return fmt.Errorf("line %d: indented more than one level below its parent", i+1)
+		}
+		depth = w / 2
+	}
+	return nil
+}
+
+// validateBraces checks that lines are indented by one space per level of
+// braces and that braces are balanced.
+func validateBraces(lines []string) error {
+	depth := 0
+	for i, l := range lines {
+		if strings.TrimSpace(l) == "}" {
+			depth--
+		}
+		if depth < 0 {
+			return fmt.Errorf("line %d: unbalanced closing brace", i+1)
+		}
+		if indentWidth(l) != depth {
+			return fmt.Errorf("line %d: indented %d spaces at depth %d", i+1, indentWidth(l), depth)
+		}
+		if strings.HasSuffix(l, " {") {
+			depth++
+		}
+	}
+	if depth != 0 {
+		return errors.New("unbalanced opening brace")
+	}
+	return nil
+}
+
+// validateTree checks that lines are nested by well-formed branches.
+func validateTree(lines []string) error {
+	depth := -1
+	for i, l := range lines {
+		rest, d := l, 0
+		for {
+			var ok bool
+			if rest, ok = cutAny(rest, "│   ", "    "); !ok {
+				break
+			}
+			d++
+		}
+		var ok bool
+		if rest, ok = cutAny(rest, "├── ", "└── "); ok {
+			d++
+		} else if d > 0 {
+			return fmt.Errorf("line %d: continuation without branch", i+1)
+		}
+		if strings.HasPrefix(rest, " ") {
+			return fmt.Errorf("line %d: indented value", i+1)
+		}
+		if d > depth+1 {
+			return fmt.Errorf("line %d: nested more than one level below its parent", i+1)
+		}
+		depth = d
+	}
+	return nil
+}
+
+// cutAny returns s without the first of prefixes it starts with.
+func cutAny(s string, prefixes ...string) (string, bool) {
+	for _, p := range prefixes {
+		if rest, ok := strings.CutPrefix(s, p); ok {
+			return rest, true
+		}
+	}
+	return s, false
+}
+
+// spewRenderer renders StyleSpew.
+type spewRenderer struct{}
+
+// braced reports whether n is written with its children in braces.
+func braced(n *Node) bool {
+	return !n.Leaf && n.Type != ""
+}
+
+func (spewRenderer) Open(w io.Writer, n *Node) error {
+	var b bytes.Buffer
+	b.WriteString(strings.Repeat(" ", n.Depth))
+	start := b.Len()
+	switch {
+	case n.Name == "" || n.Elem || n.Depth == 0:
+	case n.Type == "":
+		b.WriteString(n.Name)
+	default:
+		b.WriteString(n.Name + ": ")
+	}
+	if n.Type != "" {
+		b.WriteString("(" + n.Type + ")")
+	}
+	if n.Leaf && n.Type != "" {
+		b.WriteString(" " + n.Value)
+	}
+	if n.Note != "" {
+		if b.Len() > start {
+			b.WriteByte(' ')
+		}
+		b.WriteString(n.Note)
+	}
+	if braced(n) {
+		b.WriteString(" {")
+	}
+	b.WriteByte('\n')
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func (spewRenderer) Close(w io.Writer, n *Node) error {
+	if !braced(n) {
+		return nil
+	}
+	_, err := io.WriteString(w, strings.Repeat(" ", n.Depth)+"}\n")
+	return err
+}
+
+// renderTree renders StyleTree.
+func renderTree(d *Dumper, w io.Writer, root *tree) error {
+	var b bytes.Buffer
+	var walk func(t *tree, prefix, branch string)
+	walk = func(t *tree, prefix, branch string) {
+		n := t.Node
+		n.Indent = ""
+		b.WriteString(prefix + branch)
+		textRenderer{}.writeText(&b, &n)
+		switch branch {
+		case "├── ":
+			prefix += "│   "
+		case "└── ":
+			prefix += "    "
+		}
+		for i, c := range t.children {
+			if i == len(t.children)-1 {
+				walk(c, prefix, "└── ")
+			} else {
+				walk(c, prefix, "├── ")
+			}
+		}
+	}
+	walk(root, "", "")
+	_, err := w.Write(b.Bytes())
+	return err
+}
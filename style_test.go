@@ -0,0 +1,64 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestStyleConformance pins the output of every style. A changed
+// fingerprint means tools parsing the style may break: bump FormatVersion
+// and update the fingerprint.
+func TestStyleConformance(t *testing.T) {
+	fingerprints := map[string]string{
+		"default": "9006c739eecfb8ae",
+		"spew":    "da99b978e6e4c47f",
+		"tree":    "8d51a8b5cde00524",
+		"compact": "1ad639dad5d3f0d9",
+	}
+	for _, s := range Styles() {
+		for _, sample := range s.Samples() {
+			if err := s.Validate(sample.Dump); err != nil {
+				t.Errorf("%s sample: %v\n%s", sample.Name, err, sample.Dump)
+			}
+		}
+		if got := s.Fingerprint(); got != fingerprints[s.Name()] {
+			t.Errorf("%s style: fingerprint %s, want %s", s.Name(), got, fingerprints[s.Name()])
+		}
+		if s.Describe() == "" {
+			t.Errorf("%s style: no description", s.Name())
+		}
+	}
+}
+
+func TestStyleValidate(t *testing.T) {
+	tests := []struct {
+		s    Style
+		dump string
+	}{
+		{StyleDefault, "(T)\n    A(int) 1\n"},
+		{StyleDefault, "(T)\n A(int) 1\n"},
+		{StyleDefault, "(T) \n"},
+		{StyleSpew, "(T) {\n A: (int) 1\n"},
+		{StyleSpew, "(T) {\n  A: (int) 1\n}\n"},
+		{StyleTree, "(T)\n    A(int) 1\n"},
+		{StyleTree, "(T)\n├── A(T)\n│   │   └── B(int) 1\n"},
+	}
+	for _, tt := range tests {
+		if err := tt.s.Validate(tt.dump); err == nil {
+			t.Errorf("%s style accepts %q", tt.s.Name(), tt.dump)
+		}
+	}
+
+	d := NewDumper(WithStyle(StyleTree), WithHeader(true), WithMarkers(true))
+	out := d.Sdump(S{1, 2})
+	if !strings.Contains(out, "style=tree") {
+		t.Errorf("style missing from header:\n%s", out)
+	}
+	if err := StyleTree.Validate(out); err != nil {
+		t.Errorf("%v\n%s", err, out)
+	}
+}
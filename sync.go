@@ -0,0 +1,106 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// Synchronization types whose internals are plumbing rather than state.
+var syncTypes = map[reflect.Type]bool{
+	reflect.TypeOf(sync.Mutex{}):     true,
+	reflect.TypeOf(sync.RWMutex{}):   true,
+	reflect.TypeOf(sync.WaitGroup{}): true,
+	reflect.TypeOf(sync.Cond{}):      true,
+	reflect.TypeOf(sync.Pool{}):      true,
+}
+
+// Types of golang.org/x/sync, matched by name so the package is not needed.
+var xsyncTypes = map[string]bool{
+	"golang.org/x/sync/errgroup.Group":     true,
+	"golang.org/x/sync/semaphore.Weighted": true,
+	"golang.org/x/sync/singleflight.Group": true,
+}
+
+// syncValue returns the summary of val if it is a synchronization value:
+// locks, wait groups and the like render as <T>, a sync.Once as whether
+// it is done, a sync.Map as the number of its entries, and the types of
+// sync/atomic as the value they hold. Results
+// of sync.OnceFunc and sync.OnceValue are funcs and render as such.
+func (v *variable) syncValue(val reflect.Value) (string, bool) {
+	typ := val.Type()
+	name := typ.PkgPath() + "." + typ.Name()
+	switch {
+	case syncTypes[typ] || xsyncTypes[name]:
+		return "<" + typ.String() + ">", true
+	case typ == reflect.TypeOf(sync.Once{}):
+		return "<sync.Once " + onceState(val) + ">", true
+	case typ == reflect.TypeOf(sync.Map{}):
+		return mapSize(val), true
+	case typ.PkgPath() == "sync/atomic":
+		return v.atomicValue(val), true
+	}
+	return "", false
+}
+
+// onceState tells whether the sync.Once val has run, as far as its
+// unexported done field gives away.
+func onceState(val reflect.Value) string {
+	done := val.FieldByName("done")
+	if done.Kind() == reflect.Struct {
+		done = done.FieldByName("v")
+	}
+	switch done.Kind() {
+	case reflect.Uint32:
+		if done.Uint() != 0 {
+			return "done"
+		}
+		return "pending"
+	case reflect.Bool:
+		if done.Bool() {
+			return "done"
+		}
+		return "pending"
+	}
+	return "unknown"
+}
+
+// mapSize returns the number of entries in the sync.Map val, or <sync.Map>
+// if it cannot be ranged over. Unlike atomic values, copies are not ranged
+// over since they could copy its lock while it is held.
+func mapSize(val reflect.Value) string {
+	if !val.CanInterface() || !val.CanAddr() {
+		return "<sync.Map>"
+	}
+	n := 0
+	val.Addr().Interface().(*sync.Map).Range(func(_, _ interface{}) bool {
+		n++
+		return true
+	})
+	if n == 1 {
+		return "<sync.Map 1 entry>"
+	}
+	return "<sync.Map " + strconv.Itoa(n) + " entries>"
+}
+
+// atomicValue returns the value loaded from the sync/atomic value val, or
+// <T> if its Load method cannot be called.
+func (v *variable) atomicValue(val reflect.Value) string {
+	if !val.CanInterface() {
+		return "<" + typeName(val.Type()) + ">"
+	}
+	if !val.CanAddr() {
+		c := reflect.New(val.Type()).Elem()
+		c.Set(val)
+		val = c
+	}
+	load := val.Addr().MethodByName("Load")
+	if !load.IsValid() || load.Type().NumIn() != 0 || load.Type().NumOut() != 1 {
+		return "<" + typeName(val.Type()) + ">"
+	}
+	return v.leafValue(load.Call(nil)[0])
+}
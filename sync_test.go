@@ -0,0 +1,66 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type counter struct {
+	Mu    sync.Mutex
+	Wg    *sync.WaitGroup
+	Init  sync.Once
+	Hits  atomic.Int64
+	Ready atomic.Bool
+	Last  atomic.Value
+	Seen  sync.Map
+}
+
+func TestSync(t *testing.T) {
+	c := &counter{Wg: new(sync.WaitGroup)}
+	c.Init.Do(func() {})
+	c.Hits.Add(42)
+	c.Last.Store("x")
+	c.Seen.Store("a", 1)
+	c.Seen.Store("b", 2)
+	want := "(*godump.counter)\n" +
+		"  (godump.counter)\n" +
+		"    Mu(sync.Mutex) <sync.Mutex>\n" +
+		"    Wg(*sync.WaitGroup)\n" +
+		"      Wg(sync.WaitGroup) <sync.WaitGroup>\n" +
+		"    Init(sync.Once) <sync.Once done>\n" +
+		"    Hits(atomic.Int64) 42\n" +
+		"    Ready(atomic.Bool) false\n" +
+		"    Last(atomic.Value) \"x\"\n" +
+		"    Seen(sync.Map) <sync.Map 2 entries>\n"
+	if got := Sdump(c); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	want = "(*godump.counter) &{Mu:<sync.Mutex>, Wg:&<sync.WaitGroup>, " +
+		"Init:<sync.Once done>, Hits:42, Ready:false, Last:\"x\", Seen:<sync.Map 2 entries>}\n"
+	if got := NewDumper(WithInline(200)).Sdump(c); got != want {
+		t.Errorf("inline: got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSyncMap(t *testing.T) {
+	var m sync.Map
+	if got, want := Sdump(&m), "(*sync.Map)\n  (sync.Map) <sync.Map 0 entries>\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	m.Store("k", 1)
+	if got, want := Sdump(&m), "(*sync.Map)\n  (sync.Map) <sync.Map 1 entry>\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := Sdump(map[int]*sync.Map{1: &m}, WithInline(80)), "(map[int]*sync.Map) {1:&<sync.Map 1 entry>}\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := Sdump([]interface{}{sync.Map{}}), "([]interface {})\n  0(sync.Map) <sync.Map>\n"; got != want {
+		t.Errorf("unaddressable: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"strings"
+)
+
+// WithJSONNames names struct fields by their json tag, if they have one, so
+// dumps use the names of the wire format. A name given by the dump tag
+// takes precedence. Paths, as seen by redaction rules, keep the Go names.
+func WithJSONNames(enable bool) Option {
+	return func(d *Dumper) {
+		d.jsonNames = enable
+	}
+}
+
+// tagOption returns the value of the option key in the dump tag of f. The
+// tag holds comma-separated options, each either a key or key=value, e.g.
+//
+//	ID string `dump:"name=externalID"`
+func tagOption(f reflect.StructField, key string) (string, bool) {
+	for _, opt := range strings.Split(f.Tag.Get("dump"), ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		if k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// fieldName returns the name under which the field f is dumped.
+func (v *variable) fieldName(f reflect.StructField) string {
+	if name, ok := tagOption(f, "name"); ok && name != "" {
+		return name
+	}
+	if v.d.jsonNames {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return f.Name
+}
@@ -0,0 +1,29 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import "testing"
+
+type payload struct {
+	ID    string `dump:"name=externalID" json:"id"`
+	Count int    `json:"count,omitempty"`
+	Skip  bool   `json:"-"`
+}
+
+func TestFieldNames(t *testing.T) {
+	v := payload{"x", 1, true}
+	want := "(godump.payload)\n" +
+		"  externalID(string) \"x\"\n" +
+		"  Count(int) 1\n" +
+		"  Skip(bool) true\n"
+	if got := Sdump(v); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	want = `(godump.payload) {externalID:"x", count:1, Skip:true}` + "\n"
+	if got := Sdump(v, WithJSONNames(true), WithInline(80)); got != want {
+		t.Errorf("json names: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WithTOML renders dumps as TOML documents, so dumps of configuration
+// structs can be compared against the configuration files they are loaded
+// from. Structs and maps become tables, arrays and slices of them arrays of
+// tables, and other values key/value pairs. Values nested in arrays are
+// rendered inline, elided elements as comments. A top-level value that is
+// not a table is rendered as the value of the key "value". TOML has no
+// null, so the keys of nil pointers, interfaces, funcs and channels are
+// commented out, and such values are left out of inline tables and arrays.
+//
+// The document is written at once when the dump is complete.
+// WithTOML(false) restores the default grammar if TOML was selected, and
+// leaves other renderers alone.
+func WithTOML(enable bool) Option {
+	return func(d *Dumper) {
+		if !enable && d.format != "toml" {
+			return
+		}
+		d.renderer = textRenderer{}
+		d.treeRender = nil
+		d.format = ""
+		if enable {
+			d.treeRender = renderTOML
+			d.format = "toml"
+		}
+	}
+}
+
+func renderTOML(_ *Dumper, w io.Writer, root *tree) error {
+	var b strings.Builder
+	if root.id != "" {
+		b.WriteString("# id=" + root.id + "\n")
+	}
+	root = tomlElem(root)
+	switch {
+	case isTable(root):
+		writeTable(&b, root, "")
+	case isNilLeaf(root):
+		b.WriteString("# value = nil\n")
+	default:
+		b.WriteString("value = " + tomlInline(root) + "\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// tomlElem returns the value pointed to by t, if t is a pointer.
+func tomlElem(t *tree) *tree {
+	for t.Kind == reflect.Ptr && !t.Leaf && len(t.children) == 1 {
+		t = t.children[0]
+	}
+	return t
+}
+
+func isMarker(t *tree) bool {
+	return t.Type == ""
+}
+
+// isNilLeaf reports whether t is a nil value other than a slice or map,
+// which has no TOML value.
+func isNilLeaf(t *tree) bool {
+	if !t.Leaf || t.Value != "nil" {
+		return false
+	}
+	switch t.Kind {
+	case reflect.Ptr, reflect.Interface, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return true
+	}
+	return false
+}
+
+func isTable(t *tree) bool {
+	return !t.Leaf && !isMarker(t) && (t.Kind == reflect.Struct || t.Kind == reflect.Map)
+}
+
+func isTableArray(t *tree) bool {
+	if t.Leaf || t.Kind != reflect.Array && t.Kind != reflect.Slice || len(t.children) == 0 {
+		return false
+	}
+	for _, c := range t.children {
+		if !isMarker(c) && !isTable(tomlElem(c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeTable writes the key/value pairs of the table t, whose header is
+// prefix, followed by its subtables.
+func writeTable(b *strings.Builder, t *tree, prefix string) {
+	var tables []*tree
+	for _, c := range t.children {
+		c = tomlElem(c)
+		switch {
+		case isMarker(c):
+			b.WriteString("# " + c.Name + c.Note + "\n")
+		case isTable(c) || isTableArray(c):
+			tables = append(tables, c)
+		case isNilLeaf(c):
+			b.WriteString("# " + tomlKey(c.Name) + " = nil\n")
+		default:
+			b.WriteString(tomlKey(c.Name) + " = " + tomlInline(c) + "\n")
+		}
+	}
+	for _, c := range tables {
+		header := tomlKey(c.Name)
+		if prefix != "" {
+			header = prefix + "." + header
+		}
+		if isTable(c) {
+			b.WriteString("\n[" + header + "]\n")
+			writeTable(b, c, header)
+			continue
+		}
+		for _, e := range c.children {
+			if isMarker(e) {
+				b.WriteString("# " + e.Name + e.Note + "\n")
+				continue
+			}
+			b.WriteString("\n[[" + header + "]]\n")
+			writeTable(b, tomlElem(e), header)
+		}
+	}
+}
+
+// tomlInline returns t as an inline value.
+func tomlInline(t *tree) string {
+	t = tomlElem(t)
+	var items []string
+	switch {
+	case t.Leaf:
+		return tomlValue(t)
+	case t.Kind == reflect.Array || t.Kind == reflect.Slice:
+		for _, c := range t.children {
+			if !isMarker(c) && !isNilLeaf(tomlElem(c)) {
+				items = append(items, tomlInline(c))
+			}
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case isTable(t):
+		for _, c := range t.children {
+			if !isMarker(c) && !isNilLeaf(tomlElem(c)) {
+				items = append(items, tomlKey(c.Name)+" = "+tomlInline(c))
+			}
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	}
+	return strconv.Quote(strings.TrimSpace(t.Note))
+}
+
+// tomlValue returns the leaf t as a TOML value.
+func tomlValue(t *tree) string {
+	if s, ok := t.decimal(); ok {
+		return s
+	}
+	switch t.Kind {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// Well-known types like time.Duration are not rendered as numbers.
+		if t.Value != "" && strings.TrimLeft(t.Value, "-0123456789") == "" || t.Value == "true" || t.Value == "false" {
+			return t.Value
+		}
+	case reflect.Float32, reflect.Float64:
+		switch t.Value {
+		case "NaN":
+			return "nan"
+		case "+Inf":
+			return "inf"
+		case "-Inf":
+			return "-inf"
+		}
+		if !strings.ContainsAny(t.Value, ".eE") {
+			return t.Value + ".0"
+		}
+		return t.Value
+	case reflect.String:
+		if strings.HasPrefix(t.Value, `"`) {
+			return t.Value
+		}
+	case reflect.Slice:
+		if t.Value == "nil" {
+			return "[]"
+		}
+	case reflect.Map:
+		if t.Value == "nil" {
+			return "{}"
+		}
+	}
+	return strconv.Quote(t.Value)
+}
+
+// tomlKey returns name as a bare key if possible, or else as a quoted one.
+func tomlKey(name string) string {
+	if name == "" {
+		return `""`
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+			return strconv.Quote(name)
+		}
+	}
+	return name
+}
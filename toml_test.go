@@ -0,0 +1,101 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"testing"
+	"text/template"
+)
+
+type config struct {
+	Name    string
+	Servers []server
+	Limits  map[string]float64
+	Ports   []int
+}
+
+type server struct {
+	Host string
+	Tags []string
+	Port uint16
+}
+
+func TestTOML(t *testing.T) {
+	v := &config{
+		Name:    "app",
+		Servers: []server{{"a", []string{"x"}, 8080}, {"b", nil, 0}},
+		Limits:  map[string]float64{"cpu": 1},
+		Ports:   []int{80, 443},
+	}
+	want := `Name = "app"
+Ports = [80, 443]
+
+[[Servers]]
+Host = "a"
+Tags = ["x"]
+Port = 8080
+
+[[Servers]]
+Host = "b"
+Tags = []
+Port = 0
+
+[Limits]
+cpu = 1.0
+`
+	if got := Sdump(v, WithTOML(true)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	if got := Sdump([]S{{1, 2}}, WithTOML(true)); got != "value = [{A = 1, B = 2}]\n" {
+		t.Errorf("array: got %q", got)
+	}
+}
+
+func TestTOMLNil(t *testing.T) {
+	type opts struct {
+		Timeout *int
+		Hook    interface{}
+		Err     error
+		Retries []*int
+		Extra   map[string]int
+	}
+	n := 3
+	v := struct {
+		Opts  opts
+		Inner []opts
+	}{opts{Retries: []*int{nil, &n}}, []opts{{}}}
+	want := `
+[Opts]
+# Timeout = nil
+# Hook = nil
+# Err = nil
+Retries = [3]
+Extra = {}
+
+[[Inner]]
+# Timeout = nil
+# Hook = nil
+# Err = nil
+Retries = []
+Extra = {}
+`
+	if got := Sdump(v, WithTOML(true)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got := Sdump((*int)(nil), WithTOML(true)); got != "# value = nil\n" {
+		t.Errorf("top-level nil: got %q", got)
+	}
+}
+
+func TestTOMLDisabled(t *testing.T) {
+	tmpl := template.Must(template.New("").Parse("{{.Name}}={{.Value}}"))
+	if got, want := Sdump(S{1, 2}, WithTemplate(tmpl), WithTOML(false)), Sdump(S{1, 2}, WithTemplate(tmpl)); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := Sdump(S{1, 2}, WithTOML(true), WithTOML(false)), Sdump(S{1, 2}); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Reasons of truncations.
+const (
+	TruncatedDepth      = "depth"      // children beyond the depth limit
+	TruncatedElements   = "elements"   // elements beyond the element limit
+	TruncatedBytes      = "bytes"      // bytes beyond the byte limit
+	TruncatedString     = "string"     // bytes beyond the string length limit
+	TruncatedPointer    = "pointer"    // value summarized by the pointer policy
+	TruncatedDownsample = "downsample" // elements left out by downsampling
+	TruncatedKey        = "key"        // map key shortened to the key length limit
+	TruncatedBudget     = "budget"     // values left out once the budget was spent
+	TruncatedPreview    = "preview"    // lines left out of the preview of a large dump
+)
+
+// Truncation records a value whose dump is incomplete because of a limit.
+type Truncation struct {
+	Path   string
+	Reason string
+
+	// Number of elements or bytes left out, if known
+	Omitted int
+}
+
+// WithTruncationReport calls f after every dump with the values whose dump
+// was cut short, in the order they were found, so tools can warn that the
+// dump is incomplete. f is called with no truncations for complete dumps.
+func WithTruncationReport(f func([]Truncation)) Option {
+	return func(d *Dumper) {
+		d.truncationReport = f
+	}
+}
+
+// truncated records the truncation of the value at path.
+func (v *variable) truncated(path, reason string, omitted int) {
+	if v.d.truncationReport != nil {
+		v.truncations = append(v.truncations, Truncation{path, reason, omitted})
+	}
+}
+
+// truncatedString renders the string val, the value of n, truncated to the
+// string length limit if it exceeds it.
+func (v *variable) truncatedString(val reflect.Value, n *Node) bool {
+	s, max := val.String(), v.d.maxStringLen
+	if max <= 0 || len(s) <= max {
+		return false
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	n.Value = strconv.Quote(s[:max])
+	n.annotate("(" + v.count(len(s)) + " bytes, truncated)")
+	v.truncated(n.Path, TruncatedString, len(s)-max)
+	return true
+}
+
+// count formats the number n of elided elements or bytes with grouped
+// thousands, in the conventions of the locale if there is one.
+func (v *variable) count(n int) string {
+	l := v.d.locale
+	if l == nil {
+		en := locales["en"]
+		l = &en
+	}
+	return l.number(strconv.Itoa(n))
+}
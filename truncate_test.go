@@ -0,0 +1,60 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTruncationReport(t *testing.T) {
+	var got []Truncation
+	d := NewDumper(WithTruncationReport(func(ts []Truncation) { got = ts }), WithByteDetection(true))
+	d.maxElements = 2
+	d.maxDepth = 2
+	v := struct {
+		List []int
+		Deep [][]int
+		Blob []byte
+	}{[]int{1, 2, 3, 4}, [][]int{{1}}, make([]byte, maxBytes+10)}
+	d.Sdump(v)
+	want := []Truncation{
+		{"List", TruncatedElements, 2},
+		{"Deep[0]", TruncatedDepth, 0},
+		{"Blob", TruncatedBytes, 10},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	d.Sdump(1)
+	if len(got) != 0 {
+		t.Errorf("complete dump: got %v", got)
+	}
+}
+
+func TestMaxStringLen(t *testing.T) {
+	var got []Truncation
+	v := struct {
+		Short string
+		Long  string
+		List  []int
+	}{"abc", "héllo wörld", make([]int, 1234)}
+	want := `(struct { Short string; Long string; List []int })
+  Short(string) "abc"
+  Long(string) "héll" (13 bytes, truncated)
+  List([]int)
+    0(int) 0
+    ... 1,233 more elements
+`
+	out := Sdump(v, WithMaxStringLen(5), WithMaxElements(1), WithInline(200),
+		WithTruncationReport(func(ts []Truncation) { got = ts }))
+	if out != want {
+		t.Errorf("got\n%s\nwant\n%s", out, want)
+	}
+	if len(got) != 2 || got[0] != (Truncation{"Long", TruncatedString, 8}) {
+		t.Errorf("got %v", got)
+	}
+}
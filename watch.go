@@ -0,0 +1,199 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Watcher periodically samples a value and dumps it when it changes.
+type Watcher struct {
+	stop chan struct{}
+	once sync.Once
+	done chan struct{}
+}
+
+// Watch evaluates getter every interval and dumps its result with label
+// whenever the dump differs from the previous sample. The first sample is
+// always dumped. Dumps go where DumpLabel would send them; options are
+// applied as by Dump. Samples are compared without their timestamps,
+// sequence numbers and correlation IDs.
+func Watch(label string, getter func() interface{}, interval time.Duration, opts ...Option) *Watcher {
+	return watch(label, getter, interval, watchFull, opts)
+}
+
+// WatchDiff is like Watch, but after the first sample only a line diff
+// against the previous sample is emitted. Removed lines are prefixed with
+// "- ", added lines with "+ ".
+func WatchDiff(label string, getter func() interface{}, interval time.Duration, opts ...Option) *Watcher {
+	return watch(label, getter, interval, watchDiff, opts)
+}
+
+// WatchRecent is like Watch, but the fields of every struct are dumped
+// with the ones that changed most recently first, so the interesting parts
+// of a state object being watched are immediately visible. Fields that
+// never changed keep their order below them.
+func WatchRecent(label string, getter func() interface{}, interval time.Duration, opts ...Option) *Watcher {
+	return watch(label, getter, interval, watchRecent, opts)
+}
+
+// watchMode tells how the samples of a Watcher are dumped.
+type watchMode int
+
+const (
+	watchFull watchMode = iota
+	watchDiff
+	watchRecent
+)
+
+func watch(label string, getter func() interface{}, interval time.Duration, mode watchMode, opts []Option) *Watcher {
+	wt := &Watcher{stop: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		defer close(wt.done)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+
+		var prev string
+		var r *recency
+		if mode == watchRecent {
+			r = &recency{changed: make(map[string]int)}
+		}
+		first := true
+		for {
+			v := getter()
+			d := std().With(opts...)
+			if r != nil {
+				d = r.sample(d, v)
+			}
+			// The stamps differ between dumps of the same value.
+			out := d.With(unstamped).sdump(label, v)
+			switch {
+			case first:
+				d.DumpSeverity(Debug, label, v)
+				first = false
+			case out == prev:
+			case mode == watchDiff:
+				d.deliver(Debug, label, d.newCorrelationID(), v, diffLines(prev, out))
+			default:
+				d.DumpSeverity(Debug, label, v)
+			}
+			prev = out
+
+			select {
+			case <-t.C:
+			case <-wt.stop:
+				return
+			}
+		}
+	}()
+	return wt
+}
+
+// unstamped turns off the stamps starting dumps.
+func unstamped(d *Dumper) {
+	d.timestamp = false
+	d.sequence = false
+	d.correlationID = ""
+	d.correlationIDs = false
+}
+
+// recency tracks when the values at the paths of a watched value last
+// changed.
+type recency struct {
+	// Number of the current sample
+	n int
+
+	// Nodes of the dump of the previous sample
+	prev map[baselineKey]*tree
+
+	// Number of the sample in which the values at paths last changed
+	changed map[string]int
+}
+
+// sample records the changes of v since the previous sample and returns d
+// configured to dump the fields that changed most recently first.
+func (r *recency) sample(d *Dumper, v interface{}) *Dumper {
+	r.n++
+	nodes := d.baselineOf(reflect.ValueOf(v)).nodes
+	if r.prev != nil {
+		for k, t := range nodes {
+			if p, ok := r.prev[k]; !ok || p.Type != t.Type || p.Leaf != t.Leaf || p.Value != t.Value || p.Note != t.Note {
+				r.touch(k.path)
+			}
+		}
+		for k := range r.prev {
+			if _, ok := nodes[k]; !ok {
+				r.touch(k.path)
+			}
+		}
+	}
+	r.prev = nodes
+	return d.With(func(d *Dumper) { d.recency = r })
+}
+
+// touch records the change of the value at path and of the values
+// containing it.
+func (r *recency) touch(path string) {
+	for path != "" && r.changed[path] != r.n {
+		r.changed[path] = r.n
+		i := strings.LastIndexAny(path, ".[")
+		if i < 0 {
+			break
+		}
+		path = path[:i]
+	}
+}
+
+// fieldOrder returns the indices of the fields of the struct type t, found
+// at path, in the order they are dumped, or nil for their declaration
+// order.
+func (v *variable) fieldOrder(t reflect.Type, path string) []int {
+	r := v.d.recency
+	if r == nil {
+		return nil
+	}
+	order := make([]int, t.NumField())
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return r.changed[joinPath(path, t.Field(order[i]).Name)] > r.changed[joinPath(path, t.Field(order[j]).Name)]
+	})
+	return order
+}
+
+// Stop stops the watcher. No dump is emitted after Stop returns.
+func (wt *Watcher) Stop() {
+	wt.once.Do(func() { close(wt.stop) })
+	<-wt.done
+}
+
+// diffLines returns the lines that differ between the texts a and b, as
+// aligned by align.
+func diffLines(a, b string) string {
+	x := strings.SplitAfter(a, "\n")
+	y := strings.SplitAfter(b, "\n")
+	if x[len(x)-1] == "" {
+		x = x[:len(x)-1]
+	}
+	if y[len(y)-1] == "" {
+		y = y[:len(y)-1]
+	}
+
+	var out strings.Builder
+	for _, e := range align(x, y) {
+		switch e.op {
+		case '-':
+			out.WriteString("- " + x[e.i])
+		case '+':
+			out.WriteString("+ " + y[e.j])
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,138 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	samples := make(chan int)
+	out := make(chanWriter, 8)
+	w := watch("counter", func() interface{} { return <-samples }, time.Millisecond, watchDiff, []Option{WithWriter(out)})
+
+	samples <- 1
+	if got := <-out; got != Sdump(1) {
+		t.Errorf("first sample: %q", got)
+	}
+	samples <- 1
+	samples <- 2
+	if got, want := <-out, "- (int) 1\n+ (int) 2\n"; got != want {
+		t.Errorf("diff: got %q, want %q", got, want)
+	}
+
+	// Unchanged samples are not dumped.
+	stopped := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case samples <- 2:
+			case <-stopped:
+				return
+			}
+		}
+	}()
+	w.Stop()
+	close(stopped)
+	select {
+	case got := <-out:
+		t.Errorf("unexpected dump: %q", got)
+	default:
+	}
+}
+
+func TestWatchStamps(t *testing.T) {
+	samples := make(chan int)
+	out := make(chanWriter, 8)
+	opts := []Option{WithSequence(true), WithTimestamp(true), WithCorrelationIDs(true), WithSink(Debug, WriterSink(out))}
+	w := watch("counter", func() interface{} { return <-samples }, time.Millisecond, watchFull, opts)
+
+	// Samples differing only in their stamps are not dumped again.
+	samples <- 1
+	samples <- 1
+	samples <- 2
+	for _, want := range []string{"(int) 1\n", "(int) 2\n"} {
+		got := <-out
+		if !strings.HasPrefix(got, "seq=") || !strings.HasSuffix(got, "\n"+want) {
+			t.Errorf("got %q, want a stamped dump ending in %q", got, want)
+		}
+	}
+	stopped := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case samples <- 2:
+			case <-stopped:
+				return
+			}
+		}
+	}()
+	w.Stop()
+	close(stopped)
+	select {
+	case got := <-out:
+		t.Errorf("unexpected dump: %q", got)
+	default:
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	a := "a\nb\nc\n"
+	b := "a\nx\nc\nd\n"
+	if got, want := diffLines(a, b), "- b\n+ x\n+ d\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Dumps too long for the LCS table are compared line by line.
+	var x, y strings.Builder
+	for i := 0; i < 10000; i++ {
+		fmt.Fprintf(&x, "%d\n", i)
+		fmt.Fprintf(&y, "%d\n", -i)
+	}
+	if got := strings.Count(diffLines(x.String(), y.String()), "\n"); got != 2*9999 {
+		t.Errorf("got %d lines, want %d", got, 2*9999)
+	}
+}
+
+type watched struct {
+	A, B, C int
+}
+
+func TestWatchRecent(t *testing.T) {
+	samples := make(chan watched)
+	out := make(chanWriter, 8)
+	w := watch("state", func() interface{} { return <-samples }, time.Millisecond, watchRecent, []Option{WithWriter(out)})
+	defer func() {
+		stopped := make(chan struct{})
+		go func() {
+			for {
+				select {
+				case samples <- watched{2, 1, 2}:
+				case <-stopped:
+					return
+				}
+			}
+		}()
+		w.Stop()
+		close(stopped)
+	}()
+
+	for _, tt := range []struct {
+		v    watched
+		want string
+	}{
+		{watched{1, 1, 1}, "(godump.watched)\n  A(int) 1\n  B(int) 1\n  C(int) 1\n"},
+		{watched{1, 1, 2}, "(godump.watched)\n  C(int) 2\n  A(int) 1\n  B(int) 1\n"},
+		{watched{2, 1, 2}, "(godump.watched)\n  A(int) 2\n  C(int) 2\n  B(int) 1\n"},
+	} {
+		samples <- tt.v
+		if got := <-out; got != tt.want {
+			t.Errorf("%v: got\n%s\nwant\n%s", tt.v, got, tt.want)
+		}
+	}
+}
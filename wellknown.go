@@ -0,0 +1,72 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// wellKnown maps the standard library types rendered compactly to the
+// functions formatting their values.
+var wellKnown = map[reflect.Type]func(reflect.Value) string{
+	timeType: func(val reflect.Value) string {
+		return val.Interface().(time.Time).Format(time.RFC3339Nano)
+	},
+	reflect.TypeOf(time.Duration(0)): func(val reflect.Value) string {
+		return shortDuration(val.Interface().(time.Duration))
+	},
+	reflect.TypeOf(net.IP(nil)): func(val reflect.Value) string {
+		return val.Interface().(net.IP).String()
+	},
+}
+
+// WithWellKnownTypes controls whether values of well-known standard library
+// types are rendered compactly rather than by their contents: time.Time in
+// RFC 3339 format, time.Duration like 1h30m and net.IP like 10.0.0.1. It is
+// enabled by default.
+func WithWellKnownTypes(enable bool) Option {
+	return func(d *Dumper) {
+		d.wellKnown = enable
+	}
+}
+
+// wellKnownText returns the compact form of val if it is of a well-known
+// type.
+func wellKnownText(val reflect.Value) (string, bool) {
+	format, ok := wellKnown[val.Type()]
+	if !ok || !val.CanInterface() || isNil(val) {
+		return "", false
+	}
+	return format(val), true
+}
+
+// wellKnownNode renders n in its compact form if its value val is of a
+// well-known type.
+func (v *variable) wellKnownNode(val reflect.Value, n *Node) bool {
+	s, ok := wellKnownText(val)
+	if !ok {
+		return false
+	}
+	n.Leaf = true
+	n.Value = s
+	v.open(n)
+	return true
+}
+
+// shortDuration formats d like time.Duration.String without trailing zero
+// units, e.g. 1h30m rather than 1h30m0s.
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
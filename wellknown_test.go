@@ -0,0 +1,66 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWellKnownTypes(t *testing.T) {
+	type event struct {
+		At    time.Time
+		Took  time.Duration
+		Every *time.Duration
+		From  net.IP
+		To    net.IP
+	}
+	every := time.Hour
+	v := event{
+		At:    time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+		Took:  90 * time.Minute,
+		Every: &every,
+		From:  net.IPv4(10, 0, 0, 1),
+	}
+
+	want := `(godump.event)
+  At(time.Time) 2024-01-02T03:04:05.000000006Z
+  Took(time.Duration) 1h30m
+  Every(*time.Duration)
+    Every(time.Duration) 1h
+  From(net.IP) 10.0.0.1
+  To(net.IP) nil
+`
+	if got := Sdump(v, WithInlinePointers(true)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	want = `(godump.event) {At:2024-01-02T03:04:05.000000006Z, Took:1h30m, Every:&1h, From:10.0.0.1, To:nil}` + "\n"
+	if got := Sdump(v, WithInline(200)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	if got := Sdump(v, WithWellKnownTypes(false)); !strings.Contains(got, "Took(time.Duration) 5400000000000") {
+		t.Errorf("well-known types rendered compactly:\n%s", got)
+	}
+
+	if got := Sdump(v.Took, WithTOML(true)); !strings.Contains(got, `"1h30m"`) {
+		t.Errorf("duration not quoted in TOML:\n%s", got)
+	}
+
+	for d, want := range map[time.Duration]string{
+		0:                       "0s",
+		time.Second:             "1s",
+		2 * time.Minute:         "2m",
+		time.Hour + time.Second: "1h0m1s",
+		1500 * time.Millisecond: "1.5s",
+	} {
+		if got := shortDuration(d); got != want {
+			t.Errorf("shortDuration(%v) = %s, want %s", d, got, want)
+		}
+	}
+}
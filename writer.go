@@ -0,0 +1,167 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Writer writes lines of output at the indentation of the dump they are
+// part of. Every line is written to the underlying writer as a whole, with
+// the prefix of the Writer and its current indentation.
+type Writer struct {
+	w      io.Writer
+	prefix string
+	indent int
+	unit   string
+	buf    bytes.Buffer
+	err    error
+}
+
+// NewWriter returns a Writer writing lines starting with prefix to w.
+func NewWriter(w io.Writer, prefix string) *Writer {
+	return &Writer{w: w, prefix: prefix, unit: "  "}
+}
+
+// Indent makes the following lines one level deeper.
+func (w *Writer) Indent() {
+	w.indent++
+}
+
+// Dedent undoes the last Indent.
+func (w *Writer) Dedent() {
+	if w.indent > 0 {
+		w.indent--
+	}
+}
+
+// Write writes the complete lines of p and keeps the rest until it is
+// completed by later writes or by Flush. Lines are indented as they were
+// when they were started.
+func (w *Writer) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 && w.err == nil {
+		if w.buf.Len() == 0 {
+			w.buf.WriteString(w.prefix)
+			w.buf.WriteString(strings.Repeat(w.unit, w.indent))
+		}
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.buf.Write(p)
+			break
+		}
+		w.buf.Write(p[:i+1])
+		p = p[i+1:]
+		w.writeLine()
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+	return n, nil
+}
+
+// Printf formats according to a format specifier and writes the result.
+func (w *Writer) Printf(format string, a ...interface{}) {
+	fmt.Fprintf(w, format, a...)
+}
+
+// Flush terminates and writes the pending incomplete line, if any, and
+// returns the first error encountered by the Writer.
+func (w *Writer) Flush() error {
+	if w.buf.Len() > 0 && w.err == nil {
+		w.buf.WriteByte('\n')
+		w.writeLine()
+	}
+	return w.err
+}
+
+func (w *Writer) writeLine() {
+	_, w.err = w.w.Write(w.buf.Bytes())
+	w.buf.Reset()
+}
+
+// Dumpable is implemented by values rendering their own content. DumpTo is
+// called after the line naming the value, with a Writer indenting lines one
+// level below it. The lines are written verbatim, whatever the renderer,
+// except in documents like those of WithJSON and in dumps rendered as a
+// whole, where every line becomes a node below the value with the line as
+// its note.
+type Dumpable interface {
+	DumpTo(w *Writer)
+}
+
+// dumpable returns val as a Dumpable, if it is one.
+func dumpable(val reflect.Value) (Dumpable, bool) {
+	if !val.CanInterface() || val.Kind() == reflect.Ptr && val.IsNil() || val.Kind() == reflect.Interface {
+		return nil, false
+	}
+	d, ok := val.Interface().(Dumpable)
+	return d, ok
+}
+
+// lineWriter routes the lines of a Writer to the outputs of a dump, as
+// lines of values at the given indent.
+type lineWriter struct {
+	v      *variable
+	indent int64
+	path   string
+}
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	saved := w.v.indent
+	w.v.indent = w.indent
+	w.v.at = Line{Depth: int(w.indent), Path: w.path}
+	w.v.line.Write(p)
+	w.v.flushLine()
+	w.v.at = Line{}
+	w.v.indent = saved
+	return len(p), nil
+}
+
+// noteWriter renders the lines of a Writer as leaves with the lines as
+// their notes, below the node at the given depth, so they become part of
+// documents.
+type noteWriter struct {
+	v     *variable
+	depth int
+	path  string
+}
+
+func (w noteWriter) Write(p []byte) (int, error) {
+	n := &Node{
+		Depth:  w.depth,
+		Indent: w.v.indentation(w.depth),
+		Path:   w.path,
+		Leaf:   true,
+		Note:   strings.TrimSuffix(string(p), "\n"),
+	}
+	w.v.open(n)
+	w.v.close(n)
+	return len(p), nil
+}
+
+// newWriter returns a Writer for the lines of the children of n.
+func (v *variable) newWriter(n *Node) *Writer {
+	var w *Writer
+	if v.d.document() || v.d.treeRender != nil {
+		w = NewWriter(noteWriter{v, n.Depth + 1, n.Path}, "")
+	} else {
+		w = NewWriter(lineWriter{v, v.indent + 1, n.Path}, v.indentation(n.Depth+1))
+	}
+	w.unit = v.d.indentUnit
+	return w
+}
+
+// dumpableNode renders n by the DumpTo method of its value d.
+func (v *variable) dumpableNode(d Dumpable, n *Node) {
+	v.open(n)
+	w := v.newWriter(n)
+	d.DumpTo(w)
+	w.Flush()
+}
@@ -0,0 +1,70 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	var b strings.Builder
+	w := NewWriter(&b, "> ")
+	w.Printf("a\nb")
+	w.Indent()
+	w.Printf("c\n")
+	w.Dedent()
+	w.Printf("d")
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := "> a\n> bc\n> d\n"
+	if got := b.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+type matrix [][]int
+
+func (m matrix) DumpTo(w *Writer) {
+	for _, row := range m {
+		w.Printf("%v\n", row)
+	}
+}
+
+func TestDumpable(t *testing.T) {
+	v := struct {
+		M matrix
+		N int
+	}{matrix{{1, 2}, {3, 4}}, 5}
+	want := "(struct { M godump.matrix; N int })\n" +
+		"  M(godump.matrix)\n" +
+		"    [1 2]\n" +
+		"    [3 4]\n" +
+		"  N(int) 5\n"
+	if got := Sdump(v); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got := NewDumper(WithInline(80)).Sdump(v); got != want {
+		t.Errorf("inline: got\n%s\nwant\n%s", got, want)
+	}
+	// Documents hold the lines as notes of their own nodes.
+	tests := []struct {
+		opt  Option
+		want string
+	}{
+		{WithJSON(true), `{"type":"struct { M godump.matrix; N int }","kind":"struct","children":[` +
+			`{"path":"M","name":"M","type":"godump.matrix","kind":"slice","children":[{"path":"M","note":"[1 2]"},{"path":"M","note":"[3 4]"}]},` +
+			`{"path":"N","name":"N","type":"int","kind":"int","value":"5"}]}` + "\n"},
+		{WithSExpr(true), `("struct { M godump.matrix; N int }" (M ( #|[1 2]|# #|[3 4]|#)) (N 5))` + "\n"},
+		{WithSingleLine(true), `struct { M godump.matrix; N int }{M:godump.matrix{[1 2], [3 4]}, N:5}` + "\n"},
+		{WithTOML(true), "N = 5\n# [1 2]\n# [3 4]\n"},
+	}
+	for _, tt := range tests {
+		if got := Sdump(v, tt.opt); got != tt.want {
+			t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// zeroTypes are the types whose zero values render as <zero T> by default,
+// since they would otherwise be dumped as blocks of zero fields.
+var zeroTypes = []reflect.Type{
+	timeType,
+	reflect.TypeOf(sync.Once{}),
+	reflect.TypeOf(bytes.Buffer{}),
+	reflect.TypeOf(strings.Builder{}),
+}
+
+// WithZeroTypes renders the zero values of the given types as <zero T>,
+// like those of time.Time, sync.Once, bytes.Buffer and strings.Builder by
+// default.
+func WithZeroTypes(types ...reflect.Type) Option {
+	return func(d *Dumper) {
+		if d.zeroTypes == nil {
+			d.zeroTypes = make(map[reflect.Type]bool)
+		}
+		for _, t := range types {
+			d.zeroTypes[t] = true
+		}
+	}
+}
+
+// WithZeroElision controls whether zero values of the types set by
+// WithZeroTypes, or the default ones, are rendered as <zero T>. It is
+// enabled by default.
+func WithZeroElision(enable bool) Option {
+	return func(d *Dumper) {
+		d.zeroElision = enable
+	}
+}
+
+// zeroText returns the rendering of val if it is the zero value of one of
+// the zero types.
+func (v *variable) zeroText(val reflect.Value) (string, bool) {
+	if !v.d.zeroElision || !v.d.zeroTypes[val.Type()] || !val.IsZero() {
+		return "", false
+	}
+	return "<zero " + typeName(val.Type()) + ">", true
+}
+
+// zeroNode renders n as <zero T> if its value val is the zero value of one
+// of the zero types.
+func (v *variable) zeroNode(val reflect.Value, n *Node) bool {
+	s, ok := v.zeroText(val)
+	if !ok {
+		return false
+	}
+	n.Leaf = true
+	n.Value = s
+	v.open(n)
+	return true
+}
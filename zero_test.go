@@ -0,0 +1,53 @@
+// Copyright 2014 The godump Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godump
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type span struct{ Start, End int }
+
+func TestZeroTypes(t *testing.T) {
+	type job struct {
+		Created time.Time
+		Once    sync.Once
+		Out     bytes.Buffer
+		Log     *strings.Builder
+		Span    span
+	}
+	v := &job{Log: new(strings.Builder)}
+
+	want := `(*godump.job)
+  (godump.job)
+    Created(time.Time) <zero time.Time>
+    Once(sync.Once) <zero sync.Once>
+    Out(bytes.Buffer) <zero bytes.Buffer>
+    Log(*strings.Builder)
+      Log(strings.Builder) <zero strings.Builder>
+    Span(godump.span) <zero godump.span>
+`
+	if got := Sdump(v, WithZeroTypes(reflect.TypeOf(span{}))); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	want = "(godump.job) {Created:<zero time.Time>, Once:<zero sync.Once>, Out:<zero bytes.Buffer>, Log:nil, Span:{Start:0, End:0}}\n"
+	if got := Sdump(job{}, WithInline(200)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	v.Created = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if got := Sdump(v); !strings.Contains(got, "Created(time.Time) 2024-01-02T03:04:05Z") {
+		t.Errorf("non-zero time elided:\n%s", got)
+	}
+	if got := Sdump(job{}, WithZeroElision(false)); strings.Contains(got, "<zero") {
+		t.Errorf("zero values elided:\n%s", got)
+	}
+}